@@ -2,10 +2,15 @@ package api
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"slices"
 
 	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
 	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/util"
 	"github.com/gin-gonic/gin"
 )
 
@@ -26,7 +31,7 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		Balance:  0,
 		Currency: req.Currency,
 	}
-	account, err := server.store.CreateAccount(ctx, arg)
+	account, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
 		errCode := db.ErrorCode(err)
 		if errCode == db.ForeignKeyViolation || errCode == db.UniqueViolation {
@@ -50,25 +55,27 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	account, err := server.store.GetAccount(ctx, req.ID)
-	if err != nil {
-		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	account, ok := server.memberAccount(ctx, req.ID, util.MemberRoleViewOnly)
+	if !ok {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
-		err := errors.New("account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	held, err := server.store.GetAccountHeldAmount(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, accountResponse{
+		Account:          account,
+		AvailableBalance: account.Balance - held,
+	})
+}
+
+// accountResponse adds the available balance to the ledger balance, funds reserved by holds are not available
+type accountResponse struct {
+	db.Account
+	AvailableBalance int64 `json:"available_balance"`
 }
 
 type listAccountsRequest struct {
@@ -85,9 +92,9 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		Username: authPayload.Username,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
 	}
 	accounts, err := server.store.ListAccounts(ctx, arg)
 	if err != nil {
@@ -96,3 +103,191 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, accounts)
 }
+
+// memberAccount loads an account and makes sure the authenticated user is a member allowed to act as role
+func (server *Server) memberAccount(ctx *gin.Context, id int64, role string) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	return account, server.authorizeMember(ctx, account, role)
+}
+
+// authorizeMember makes sure the authenticated user is an active member of the account allowed to act as role.
+// Pending invitations grant nothing.
+func (server *Server) authorizeMember(ctx *gin.Context, account db.Account, role string) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  authPayload.Username,
+	})
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if err != nil || member.Status != util.MemberStatusActive {
+		err := fmt.Errorf("account [%d] doesn't belong to the authenticated user", account.ID)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return false
+	}
+
+	if !util.MemberCan(member.Role, role) {
+		err := fmt.Errorf("%s members of account [%d] aren't allowed to do this", member.Role, account.ID)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+func (server *Server) freezeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, util.AccountStatusFrozen, util.AccountStatusActive)
+}
+
+func (server *Server) unfreezeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, util.AccountStatusActive, util.AccountStatusFrozen)
+}
+
+// changeAccountStatus moves an account to status, only from one of the given statuses
+func (server *Server) changeAccountStatus(ctx *gin.Context, status string, from ...string) {
+	var req getAccountRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	auditBefore(ctx, account.ID, account)
+
+	if !slices.Contains(from, account.Status) {
+		err := fmt.Errorf("account is %s and can't become %s", account.Status, status)
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	account, err = server.store.ChangeAccountStatusTx(ctx, db.UpdateAccountStatusParams{
+		Status:       status,
+		ID:           account.ID,
+		FromStatuses: from,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrAccountStatusChanged) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
+
+type closeAccountRequest struct {
+	// ToAccountID receives the remaining balance, it is required unless the account is empty
+	ToAccountID int64 `json:"to_account_id" binding:"omitempty,min=1"`
+}
+
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req closeAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.memberAccount(ctx, uri.ID, util.MemberRoleOwner)
+	if !ok {
+		return
+	}
+	auditBefore(ctx, account.ID, gin.H{"account": account})
+
+	switch account.Status {
+	case util.AccountStatusClosed:
+		err := errors.New("account is already closed")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	case util.AccountStatusFrozen:
+		err := errors.New("a frozen account can't be closed until a banker unfreezes it")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	arg := db.CloseAccountTxParams{
+		AccountID: account.ID,
+	}
+
+	if account.Balance > 0 {
+		if req.ToAccountID == 0 || req.ToAccountID == account.ID {
+			err := errors.New("another account is required to receive the remaining balance")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		// the sweep skips limits and screening, so it may only land in an account the user owns
+		toAccount, ok := server.memberAccount(ctx, req.ToAccountID, util.MemberRoleOwner)
+		if !ok {
+			return
+		}
+		// a co-owner closing a joint account can't move its balance out of the primary owner's accounts
+		if toAccount.Owner != account.Owner {
+			err := errors.New("the remaining balance can only go to another account of the primary owner")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		conversion, err := server.currencyExchange(ctx, money.New(account.Balance, account.Currency), toAccount.Currency)
+		if err != nil {
+			return
+		}
+
+		arg.Sweep = &db.TransferTxParams{
+			FromAccountID: account.ID,
+			ToAccountID:   toAccount.ID,
+			FromAmount:    conversion.Source.Amount,
+			ToAmount:      conversion.Target.Amount,
+			ExchangeRate:  money.NumericFromRat(conversion.Rate),
+		}
+		if conversion.Remainder.Sign() != 0 {
+			arg.Sweep.RoundingRemainder = money.NumericFromRat(conversion.Remainder)
+		}
+	}
+
+	result, err := server.store.CloseAccountTx(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrAccountNotActive),
+			errors.Is(err, db.ErrAccountBalanceChanged),
+			errors.Is(err, db.ErrAccountStatusChanged),
+			errors.Is(err, db.ErrAccountHasHolds),
+			errors.Is(err, db.ErrInsufficientBalance):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+)
+
+// inviteAccountMemberRequest isn't limited to alphanumeric usernames, those of Google users end in "-google" and may contain spaces
+type inviteAccountMemberRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required,member_role"`
+}
+
+// inviteAccountMember invites a user to the account, the membership only grants access once they accept it
+func (server *Server) inviteAccountMember(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req inviteAccountMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.memberAccount(ctx, uri.ID, util.MemberRoleOwner)
+	if !ok {
+		return
+	}
+
+	if account.Status == util.AccountStatusClosed {
+		err := errors.New("account is closed")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	member, err := server.store.CreateAccountMember(ctx, db.CreateAccountMemberParams{
+		AccountID: account.ID,
+		Username:  req.Username,
+		Role:      req.Role,
+		Status:    util.MemberStatusInvited,
+		InvitedBy: authPayload.Username,
+	})
+	if err != nil {
+		switch db.ErrorCode(err) {
+		case db.ForeignKeyViolation:
+			err := fmt.Errorf("user %s doesn't exist", req.Username)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case db.UniqueViolation:
+			err := fmt.Errorf("user %s is already a member of or invited to the account", req.Username)
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+func (server *Server) listAccountMembers(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.memberAccount(ctx, uri.ID, util.MemberRoleViewOnly)
+	if !ok {
+		return
+	}
+
+	members, err := server.store.ListAccountMembers(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+// listAccountInvitations lists the invitations the authenticated user hasn't accepted yet
+func (server *Server) listAccountInvitations(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	invitations, err := server.store.ListAccountInvitations(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitations)
+}
+
+func (server *Server) acceptAccountInvitation(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	member, err := server.store.AcceptAccountMember(ctx, db.AcceptAccountMemberParams{
+		AccountID: uri.ID,
+		Username:  authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err := fmt.Errorf("no pending invitation to account [%d]", uri.ID)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// accountMemberRequest accepts the usernames of Google users like inviteAccountMemberRequest
+type accountMemberRequest struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required"`
+}
+
+type updateAccountMemberRequest struct {
+	Role string `json:"role" binding:"required,member_role"`
+}
+
+func (server *Server) updateAccountMember(ctx *gin.Context) {
+	var uri accountMemberRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateAccountMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.memberAccount(ctx, uri.ID, util.MemberRoleOwner)
+	if !ok {
+		return
+	}
+
+	member, ok := server.accountMember(ctx, account, uri.Username)
+	if !ok {
+		return
+	}
+	auditBefore(ctx, account.ID, member)
+
+	member, err := server.store.UpdateAccountMemberRole(ctx, db.UpdateAccountMemberRoleParams{
+		Role:      req.Role,
+		AccountID: account.ID,
+		Username:  member.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// removeAccountMember lets an owner remove a member, and any member leave or decline an invitation
+func (server *Server) removeAccountMember(ctx *gin.Context) {
+	var uri accountMemberRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if uri.Username != authPayload.Username && !server.authorizeMember(ctx, account, util.MemberRoleOwner) {
+		return
+	}
+
+	member, ok := server.accountMember(ctx, account, uri.Username)
+	if !ok {
+		return
+	}
+	auditBefore(ctx, account.ID, member)
+
+	err = server.store.DeleteAccountMember(ctx, db.DeleteAccountMemberParams{
+		AccountID: account.ID,
+		Username:  member.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%s is no longer a member of account [%d]", member.Username, account.ID)})
+}
+
+// accountMember loads a membership of the account that may be changed, the holder of the account always stays an owner
+func (server *Server) accountMember(ctx *gin.Context, account db.Account, username string) (db.AccountMember, bool) {
+	if username == account.Owner {
+		err := errors.New("the account holder can't be removed or change role")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return db.AccountMember{}, false
+	}
+
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return member, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return member, false
+	}
+
+	return member, true
+}
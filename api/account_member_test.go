@@ -0,0 +1,523 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInviteAccountMemberAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+
+	account := randomAccount(user.Username)
+	closed := account
+	closed.Status = util.AccountStatusClosed
+
+	invitation := db.AccountMember{
+		AccountID: account.ID,
+		Username:  invitee.Username,
+		Role:      util.MemberRoleCanTransfer,
+		Status:    util.MemberStatusInvited,
+		InvitedBy: user.Username,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			body:     gin.H{"username": invitee.Username, "role": util.MemberRoleCanTransfer},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleOwner)
+				store.EXPECT().
+					CreateAccountMember(gomock.Any(), gomock.Eq(db.CreateAccountMemberParams{
+						AccountID: account.ID,
+						Username:  invitee.Username,
+						Role:      util.MemberRoleCanTransfer,
+						Status:    util.MemberStatusInvited,
+						InvitedBy: user.Username,
+					})).
+					Times(1).
+					Return(invitation, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.AccountMember
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, invitation, got)
+			},
+		},
+		{
+			name:     "GoogleUser",
+			body:     gin.H{"username": "Jane Doe-google", "role": util.MemberRoleViewOnly},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleOwner)
+				store.EXPECT().
+					CreateAccountMember(gomock.Any(), gomock.Eq(db.CreateAccountMemberParams{
+						AccountID: account.ID,
+						Username:  "Jane Doe-google",
+						Role:      util.MemberRoleViewOnly,
+						Status:    util.MemberStatusInvited,
+						InvitedBy: user.Username,
+					})).
+					Times(1).
+					Return(invitation, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			body:     gin.H{"username": invitee.Username, "role": util.MemberRoleViewOnly},
+			username: invitee.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, invitee.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().CreateAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			body:     gin.H{"username": "nobody", "role": util.MemberRoleViewOnly},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleOwner)
+				store.EXPECT().CreateAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, db.ErrForeignKeyViolation)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyMember",
+			body:     gin.H{"username": invitee.Username, "role": util.MemberRoleViewOnly},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleOwner)
+				store.EXPECT().CreateAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, db.ErrUniqueViolation)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "ClosedAccount",
+			body:     gin.H{"username": invitee.Username, "role": util.MemberRoleViewOnly},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(closed, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleOwner)
+				store.EXPECT().CreateAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidRole",
+			body:     gin.H{"username": invitee.Username, "role": "admin"},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/auth/accounts/%d/members", account.ID)
+			request := newMemberRequest(t, http.MethodPost, url, tc.body)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAcceptAccountInvitationAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(util.RandomOwner())
+
+	accepted := db.AccountMember{
+		AccountID: account.ID,
+		Username:  user.Username,
+		Role:      util.MemberRoleViewOnly,
+		Status:    util.MemberStatusActive,
+	}
+	arg := db.AcceptAccountMemberParams{AccountID: account.ID, Username: user.Username}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AcceptAccountMember(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accepted, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.AccountMember
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.MemberStatusActive, got.Status)
+			},
+		},
+		{
+			name: "NoInvitation",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AcceptAccountMember(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.AccountMember{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AcceptAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/auth/accounts/%d/members/accept", account.ID)
+			request := newMemberRequest(t, http.MethodPost, url, nil)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRemoveAccountMemberAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	member, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	account := randomAccount(user.Username)
+	membership := db.AccountMember{
+		AccountID: account.ID,
+		Username:  member.Username,
+		Role:      util.MemberRoleCanTransfer,
+		Status:    util.MemberStatusActive,
+	}
+	arg := db.GetAccountMemberParams{AccountID: account.ID, Username: member.Username}
+
+	testCases := []struct {
+		name          string
+		username      string
+		target        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OwnerRemovesMember",
+			username: user.Username,
+			target:   member.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleOwner)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(arg)).Times(1).Return(membership, nil)
+				store.EXPECT().
+					DeleteAccountMember(gomock.Any(), gomock.Eq(db.DeleteAccountMemberParams{AccountID: account.ID, Username: member.Username})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "MemberLeaves",
+			username: member.Username,
+			target:   member.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(arg)).Times(1).Return(membership, nil)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: other.Username,
+			target:   member.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, other.Username, util.MemberRoleViewOnly)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "AccountHolder",
+			username: user.Username,
+			target:   user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "OwnerRemovesGoogleMember",
+			username: user.Username,
+			target:   "Jane Doe-google",
+			buildStubs: func(store *mockdb.MockStore) {
+				googleMember := membership
+				googleMember.Username = "Jane Doe-google"
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleOwner)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: googleMember.Username})).
+					Times(1).
+					Return(googleMember, nil)
+				store.EXPECT().
+					DeleteAccountMember(gomock.Any(), gomock.Eq(db.DeleteAccountMemberParams{AccountID: account.ID, Username: googleMember.Username})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "MemberNotFound",
+			username: user.Username,
+			target:   member.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleOwner)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.AccountMember{}, db.ErrRecordNotFound)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/auth/accounts/%d/members/%s", account.ID, url.PathEscape(tc.target))
+			request := newMemberRequest(t, http.MethodDelete, url, nil)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateAccountMemberAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	member, _ := randomUser(t)
+
+	account := randomAccount(user.Username)
+	membership := db.AccountMember{
+		AccountID: account.ID,
+		Username:  member.Username,
+		Role:      util.MemberRoleViewOnly,
+		Status:    util.MemberStatusActive,
+	}
+	promoted := membership
+	promoted.Role = util.MemberRoleCanTransfer
+
+	testCases := []struct {
+		name          string
+		target        string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			target: member.Username,
+			body:   gin.H{"role": util.MemberRoleCanTransfer},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleOwner)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: member.Username})).
+					Times(1).
+					Return(membership, nil)
+				store.EXPECT().
+					UpdateAccountMemberRole(gomock.Any(), gomock.Eq(db.UpdateAccountMemberRoleParams{
+						Role:      util.MemberRoleCanTransfer,
+						AccountID: account.ID,
+						Username:  member.Username,
+					})).
+					Times(1).
+					Return(promoted, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.AccountMember
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.MemberRoleCanTransfer, got.Role)
+			},
+		},
+		{
+			name:   "AccountHolder",
+			target: user.Username,
+			body:   gin.H{"role": util.MemberRoleViewOnly},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleOwner)
+				store.EXPECT().UpdateAccountMemberRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/auth/accounts/%d/members/%s", account.ID, tc.target)
+			request := newMemberRequest(t, http.MethodPatch, url, tc.body)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func newMemberRequest(t *testing.T, method string, url string, body gin.H) *http.Request {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	return request
+}
+
+// expectMember stubs the membership check of the authenticated user on the account
+func expectMember(store *mockdb.MockStore, accountID int64, username string, role string) {
+	store.EXPECT().
+		GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: accountID, Username: username})).
+		Times(1).
+		Return(db.AccountMember{AccountID: accountID, Username: username, Role: role, Status: util.MemberStatusActive}, nil)
+}
+
+// expectNoMember stubs the membership check of a user who isn't a member of the account
+func expectNoMember(store *mockdb.MockStore, accountID int64, username string) {
+	store.EXPECT().
+		GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: accountID, Username: username})).
+		Times(1).
+		Return(db.AccountMember{}, db.ErrRecordNotFound)
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	auditBeforeKey     = "audit_before"
+	auditResourceIDKey = "audit_resource_id"
+
+	redacted = "[REDACTED]"
+)
+
+// auditLogger persists the audit log, it is the store outside of tests
+type auditLogger interface {
+	CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error)
+}
+
+// auditBefore hands the audit middleware the state of the resource a handler is about to change
+func auditBefore(ctx *gin.Context, resourceID any, before any) {
+	ctx.Set(auditResourceIDKey, fmt.Sprint(resourceID))
+	ctx.Set(auditBeforeKey, before)
+}
+
+// auditMiddleware writes an audit_log row for every mutating request, including rejected ones.
+// The response is already sent when the row is written, so a failed write is only logged.
+func (server *Server) auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			ctx.Next()
+			return
+		}
+
+		body, _ := ctx.GetRawData()
+		ctx.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		status := ctx.Writer.Status()
+
+		arg := db.CreateAuditLogParams{
+			Actor:        authPayload.Username,
+			ActorRole:    authPayload.Role,
+			Ip:           ctx.ClientIP(),
+			UserAgent:    ctx.Request.UserAgent(),
+			Method:       ctx.Request.Method,
+			Route:        ctx.FullPath(),
+			ResourceType: auditResourceType(ctx.FullPath()),
+			StatusCode:   int32(status),
+			Request:      redactJSON(body),
+		}
+
+		var after map[string]any
+		if status < http.StatusBadRequest {
+			// responses that aren't a single object, like lists, leave the changes empty
+			json.Unmarshal(recorder.body.Bytes(), &after)
+			before, _ := ctx.Get(auditBeforeKey)
+			arg.Changes = auditChanges(before, after)
+		}
+
+		if resourceID := auditResourceID(ctx, after); resourceID != "" {
+			arg.ResourceID = pgtype.Text{String: resourceID, Valid: true}
+		}
+
+		if _, err := server.auditLog.CreateAuditLog(ctx, arg); err != nil {
+			log.Error().Err(err).Str("actor", arg.Actor).Str("route", arg.Route).Msg("failed to write audit log")
+		}
+	}
+}
+
+// auditResourceType is the first segment of the route under /api/auth, e.g. accounts
+func auditResourceType(route string) string {
+	route = strings.TrimPrefix(route, "/api/auth/")
+	resourceType, _, _ := strings.Cut(route, "/")
+	return resourceType
+}
+
+// auditResourceID prefers what the handler reported, then the route, then the id of a created resource
+func auditResourceID(ctx *gin.Context, after map[string]any) string {
+	if id := ctx.GetString(auditResourceIDKey); id != "" {
+		return id
+	}
+	for _, param := range []string{"id", "username", "role"} {
+		if value := ctx.Param(param); value != "" {
+			return value
+		}
+	}
+	if id, ok := after["id"]; ok {
+		return fmt.Sprint(id)
+	}
+	return ""
+}
+
+// auditChanges diffs the top-level fields of the resource before and after the request
+func auditChanges(before any, after map[string]any) []byte {
+	if after == nil {
+		return nil
+	}
+
+	beforeFields := map[string]any{}
+	if before != nil {
+		data, err := json.Marshal(before)
+		if err != nil {
+			return nil
+		}
+		json.Unmarshal(data, &beforeFields)
+	}
+
+	changes := map[string]any{}
+	for field, value := range after {
+		if !reflect.DeepEqual(beforeFields[field], value) {
+			changes[field] = auditChange(field, beforeFields[field], value)
+		}
+	}
+	for field, value := range beforeFields {
+		if _, ok := after[field]; !ok {
+			changes[field] = auditChange(field, value, nil)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	data, _ := json.Marshal(changes)
+	return data
+}
+
+func auditChange(field string, before any, after any) gin.H {
+	if isSensitiveField(field) {
+		if before != nil {
+			before = redacted
+		}
+		if after != nil {
+			after = redacted
+		}
+	}
+	return gin.H{"before": redact(before), "after": redact(after)}
+}
+
+// redactJSON drops the secrets of a request body, a body that isn't JSON is left out entirely
+func redactJSON(body []byte) []byte {
+	var value any
+	if len(body) == 0 || json.Unmarshal(body, &value) != nil {
+		return nil
+	}
+
+	data, _ := json.Marshal(redact(value))
+	return data
+}
+
+func redact(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for field, nested := range value {
+			if isSensitiveField(field) {
+				value[field] = redacted
+				continue
+			}
+			value[field] = redact(nested)
+		}
+	case []any:
+		for i, nested := range value {
+			value[i] = redact(nested)
+		}
+	}
+	return value
+}
+
+func isSensitiveField(field string) bool {
+	field = strings.ToLower(field)
+	return strings.Contains(field, "password") ||
+		strings.Contains(field, "secret") ||
+		strings.Contains(field, "token") ||
+		field == "otp"
+}
+
+type listAuditLogsRequest struct {
+	Actor        string    `form:"actor"`
+	ResourceType string    `form:"resource_type"`
+	ResourceID   string    `form:"resource_id"`
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	PageID       int32     `form:"page_id" binding:"required,min=1"`
+	PageSize     int32     `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+type auditLogResponse struct {
+	ID           int64           `json:"id"`
+	Actor        string          `json:"actor"`
+	ActorRole    string          `json:"actor_role"`
+	IP           string          `json:"ip"`
+	UserAgent    string          `json:"user_agent"`
+	Method       string          `json:"method"`
+	Route        string          `json:"route"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   pgtype.Text     `json:"resource_id"`
+	StatusCode   int32           `json:"status_code"`
+	Request      json.RawMessage `json:"request,omitempty"`
+	Changes      json.RawMessage `json:"changes,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+func newAuditLogResponse(entry db.AuditLog) auditLogResponse {
+	return auditLogResponse{
+		ID:           entry.ID,
+		Actor:        entry.Actor,
+		ActorRole:    entry.ActorRole,
+		IP:           entry.Ip,
+		UserAgent:    entry.UserAgent,
+		Method:       entry.Method,
+		Route:        entry.Route,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		StatusCode:   entry.StatusCode,
+		Request:      entry.Request,
+		Changes:      entry.Changes,
+		CreatedAt:    entry.CreatedAt,
+	}
+}
+
+// listAuditLogs searches the audit log, newest first
+func (server *Server) listAuditLogs(ctx *gin.Context) {
+	var req listAuditLogsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	entries, err := server.store.ListAuditLogs(ctx, db.ListAuditLogsParams{
+		Actor:        pgtype.Text{String: req.Actor, Valid: req.Actor != ""},
+		ResourceType: pgtype.Text{String: req.ResourceType, Valid: req.ResourceType != ""},
+		ResourceID:   pgtype.Text{String: req.ResourceID, Valid: req.ResourceID != ""},
+		FromTime:     pgtype.Timestamptz{Time: req.From, Valid: !req.From.IsZero()},
+		ToTime:       pgtype.Timestamptz{Time: req.To, Valid: !req.To.IsZero()},
+		PageLimit:    req.PageSize,
+		PageOffset:   (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]auditLogResponse, len(entries))
+	for i, entry := range entries {
+		rsp[i] = newAuditLogResponse(entry)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
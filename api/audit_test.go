@@ -0,0 +1,246 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	newEmail := util.RandomEmail()
+	newPassword := util.RandomString(8)
+	updated := user
+	updated.Email = newEmail
+	updated.PasswordChangedAt = time.Now().UTC().Truncate(time.Second)
+
+	testCases := []struct {
+		name       string
+		username   string
+		role       string
+		body       UpdateUserRequest
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name:     "BankerChangesEmailAndPassword",
+			username: banker.Username,
+			role:     banker.Role,
+			body:     UpdateUserRequest{Username: user.Username, Email: &newEmail, Password: &newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(1).Return(updated, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, banker.Username, arg.Actor)
+						require.Equal(t, util.BankerRole, arg.ActorRole)
+						require.Equal(t, "agent", arg.UserAgent)
+						require.Equal(t, http.MethodPatch, arg.Method)
+						require.Equal(t, "/api/auth/users/update", arg.Route)
+						require.Equal(t, "users", arg.ResourceType)
+						require.Equal(t, pgtype.Text{String: user.Username, Valid: true}, arg.ResourceID)
+						require.Equal(t, int32(http.StatusOK), arg.StatusCode)
+
+						require.NotContains(t, string(arg.Request), newPassword)
+						require.Contains(t, string(arg.Request), redacted)
+
+						var changes map[string]map[string]any
+						require.NoError(t, json.Unmarshal(arg.Changes, &changes))
+						require.Equal(t, map[string]any{"before": user.Email, "after": newEmail}, changes["email"])
+						require.Equal(t, map[string]any{"before": redacted, "after": redacted}, changes["password_changed_at"])
+						require.NotContains(t, changes, "username")
+						return db.AuditLog{}, nil
+					})
+			},
+		},
+		{
+			name:     "RejectedRequestIsAudited",
+			username: user.Username,
+			role:     user.Role,
+			body:     UpdateUserRequest{Username: banker.Username, Email: &newEmail},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, user.Username, arg.Actor)
+						require.Equal(t, int32(http.StatusForbidden), arg.StatusCode)
+						require.Contains(t, string(arg.Request), newEmail)
+						require.Nil(t, arg.Changes)
+						return db.AuditLog{}, nil
+					})
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			server.auditLog = store
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPatch, "/api/auth/users/update", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("User-Agent", "agent")
+
+			// the CSRF fetch is a GET and isn't audited
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/users/update", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+		})
+	}
+}
+
+func TestAuditChanges(t *testing.T) {
+	require.Nil(t, auditChanges(nil, nil))
+	require.Nil(t, auditChanges(gin.H{"id": 1.0}, map[string]any{"id": 1.0}))
+
+	changes := auditChanges(nil, map[string]any{
+		"id":     7.0,
+		"secret": "whsec_abc",
+		"nested": map[string]any{"access_token": "abc"},
+	})
+	require.JSONEq(t, `{
+		"id": {"before": null, "after": 7},
+		"secret": {"before": null, "after": "[REDACTED]"},
+		"nested": {"before": null, "after": {"access_token": "[REDACTED]"}}
+	}`, string(changes))
+
+	changes = auditChanges(gin.H{"status": "active", "owner": "alice"}, map[string]any{"status": "frozen"})
+	require.JSONEq(t, `{
+		"status": {"before": "active", "after": "frozen"},
+		"owner": {"before": "alice", "after": null}
+	}`, string(changes))
+
+	require.Nil(t, redactJSON([]byte("not json")))
+	require.JSONEq(t, `{"otp":"[REDACTED]","amount":5}`, string(redactJSON([]byte(`{"otp":"123456","amount":5}`))))
+}
+
+func TestListAuditLogsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	entry := db.AuditLog{
+		ID:           1,
+		Actor:        banker.Username,
+		ActorRole:    banker.Role,
+		Method:       http.MethodPatch,
+		Route:        "/api/auth/users/update",
+		ResourceType: "users",
+		ResourceID:   pgtype.Text{String: user.Username, Valid: true},
+		StatusCode:   http.StatusOK,
+		Changes:      []byte(`{"email":{"before":"a@b.c","after":"d@e.f"}}`),
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: banker.Username,
+			role:     banker.Role,
+			query:    "?page_id=1&page_size=10&resource_type=users&resource_id=" + user.Username + "&from=2024-01-01T00:00:00Z",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Eq(db.ListAuditLogsParams{
+						ResourceType: pgtype.Text{String: "users", Valid: true},
+						ResourceID:   pgtype.Text{String: user.Username, Valid: true},
+						FromTime:     pgtype.Timestamptz{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+						PageLimit:    10,
+						PageOffset:   0,
+					})).
+					Times(1).
+					Return([]db.AuditLog{entry}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []map[string]any
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp, 1)
+				require.Equal(t, map[string]any{"before": "a@b.c", "after": "d@e.f"}, rsp[0]["changes"].(map[string]any)["email"])
+			},
+		},
+		{
+			name:     "DepositorForbidden",
+			username: user.Username,
+			role:     user.Role,
+			query:    "?page_id=1&page_size=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidFrom",
+			username: banker.Username,
+			role:     banker.Role,
+			query:    "?page_id=1&page_size=10&from=yesterday",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/auth/audit"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+)
+
+type cashRequest struct {
+	Amount    int64  `json:"amount" binding:"required,gt=0"`
+	Currency  string `json:"currency" binding:"required,currency"`
+	Reference string `json:"reference" binding:"required,max=64"`
+	Reason    string `json:"reason" binding:"required,max=255"`
+}
+
+func (server *Server) depositCash(ctx *gin.Context) {
+	server.postCash(ctx, util.CashDeposit)
+}
+
+func (server *Server) withdrawCash(ctx *gin.Context) {
+	server.postCash(ctx, util.CashWithdrawal)
+}
+
+// postCash moves cash between an account and the bank's cash account
+func (server *Server) postCash(ctx *gin.Context, cashType string) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req cashRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, uri.ID, req.Currency)
+	if !valid {
+		return
+	}
+	auditBefore(ctx, account.ID, gin.H{"account": account})
+
+	if account.Owner == util.CashAccountOwner {
+		err := errors.New("cash can't be posted to the bank's cash account")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.CashTx(ctx, db.CashTxParams{
+		AccountID: account.ID,
+		Type:      cashType,
+		Amount:    req.Amount,
+		Reference: req.Reference,
+		Reason:    req.Reason,
+		Banker:    authPayload.Username,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientBalance):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case db.ErrorCode(err) == db.UniqueViolation:
+			ctx.JSON(http.StatusConflict, errorResponse(errors.New("reference has already been used")))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
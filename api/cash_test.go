@@ -0,0 +1,322 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	account := randomAccount(user.Username)
+	cashAccount := randomAccount(util.CashAccountOwner)
+	cashAccount.Currency = account.Currency
+	amount := int64(1000)
+
+	bankerAuth := func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+		csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+		addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+		addCSRFToken(t, csrfReq, request, router)
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		accountID     int64
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Deposit",
+			path:      "deposit",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"currency":  account.Currency,
+				"reference": "TELLER-1",
+				"reason":    "branch deposit",
+			},
+			setupAuth: bankerAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.CashTxParams{
+					AccountID: account.ID,
+					Type:      util.CashDeposit,
+					Amount:    amount,
+					Reference: "TELLER-1",
+					Reason:    "branch deposit",
+					Banker:    banker.Username,
+				}
+				store.EXPECT().
+					CashTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CashTxResult{
+						CashTransaction: db.CashTransaction{AccountID: account.ID, CashAccountID: cashAccount.ID, Type: util.CashDeposit, Amount: amount},
+						Account:         db.Account{ID: account.ID, Balance: account.Balance + amount},
+						CashAccount:     db.Account{ID: cashAccount.ID, Balance: -amount},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.CashTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Equal(t, util.CashDeposit, result.CashTransaction.Type)
+				require.Equal(t, account.Balance+amount, result.Account.Balance)
+			},
+		},
+		{
+			name:      "Withdraw",
+			path:      "withdraw",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"currency":  account.Currency,
+				"reference": "TELLER-2",
+				"reason":    "branch withdrawal",
+			},
+			setupAuth: bankerAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CashTx(gomock.Any(), gomock.Eq(db.CashTxParams{
+						AccountID: account.ID,
+						Type:      util.CashWithdrawal,
+						Amount:    amount,
+						Reference: "TELLER-2",
+						Reason:    "branch withdrawal",
+						Banker:    banker.Username,
+					})).
+					Times(1).
+					Return(db.CashTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "DepositorForbidden",
+			path:      "deposit",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"currency":  account.Currency,
+				"reference": "TELLER-3",
+				"reason":    "self service",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CashTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingReference",
+			path:      "deposit",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":   amount,
+				"currency": account.Currency,
+				"reason":   "branch deposit",
+			},
+			setupAuth: bankerAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CashTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NegativeAmount",
+			path:      "withdraw",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    -amount,
+				"currency":  account.Currency,
+				"reference": "TELLER-4",
+				"reason":    "branch withdrawal",
+			},
+			setupAuth: bankerAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CashTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "CurrencyMismatch",
+			path:      "deposit",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"currency":  mismatchedCurrency(account.Currency),
+				"reference": "TELLER-5",
+				"reason":    "branch deposit",
+			},
+			setupAuth: bankerAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CashTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "CashAccount",
+			path:      "deposit",
+			accountID: cashAccount.ID,
+			body: gin.H{
+				"amount":    amount,
+				"currency":  cashAccount.Currency,
+				"reference": "TELLER-6",
+				"reason":    "branch deposit",
+			},
+			setupAuth: bankerAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(cashAccount.ID)).Times(1).Return(cashAccount, nil)
+				store.EXPECT().CashTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "AccountNotFound",
+			path:      "deposit",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"currency":  account.Currency,
+				"reference": "TELLER-7",
+				"reason":    "branch deposit",
+			},
+			setupAuth: bankerAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().CashTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InsufficientBalance",
+			path:      "withdraw",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"currency":  account.Currency,
+				"reference": "TELLER-8",
+				"reason":    "branch withdrawal",
+			},
+			setupAuth: bankerAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CashTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CashTxResult{}, db.ErrInsufficientBalance)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "DuplicateReference",
+			path:      "deposit",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"currency":  account.Currency,
+				"reference": "TELLER-1",
+				"reason":    "branch deposit",
+			},
+			setupAuth: bankerAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CashTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CashTxResult{}, db.ErrUniqueViolation)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			path:      "deposit",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"currency":  account.Currency,
+				"reference": "TELLER-9",
+				"reason":    "branch deposit",
+			},
+			setupAuth: bankerAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CashTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CashTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/api/auth/accounts/%d/%s", tc.accountID, tc.path)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.router, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func mismatchedCurrency(currency string) string {
+	if currency == util.USD {
+		return util.EUR
+	}
+	return util.USD
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/statement"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type listAccountEntriesRequest struct {
+	From      time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To        time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	Direction string    `form:"direction" binding:"omitempty,oneof=credit debit"`
+	Cursor    int64     `form:"cursor" binding:"omitempty,min=1"`
+	PageSize  int32     `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+type listAccountEntriesResponse struct {
+	Entries []db.ListAccountEntriesRow `json:"entries"`
+	// NextCursor is passed as cursor to fetch the following page, it is omitted on the last page
+	NextCursor *int64 `json:"next_cursor,omitempty"`
+}
+
+func (server *Server) listAccountEntries(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listAccountEntriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && req.To.Before(req.From) {
+		err := errors.New("to must not be before from")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.memberAccount(ctx, uri.ID, util.MemberRoleViewOnly)
+	if !ok {
+		return
+	}
+
+	arg := db.ListAccountEntriesParams{
+		AccountID: account.ID,
+		Direction: pgtype.Text{String: req.Direction, Valid: req.Direction != ""},
+		Cursor:    pgtype.Int8{Int64: req.Cursor, Valid: req.Cursor > 0},
+		PageSize:  req.PageSize,
+	}
+	if !req.From.IsZero() {
+		arg.FromTime = pgtype.Timestamptz{Time: req.From, Valid: true}
+	}
+	// to is inclusive, so the range ends at the start of the following day
+	if !req.To.IsZero() {
+		arg.ToTime = pgtype.Timestamptz{Time: req.To.AddDate(0, 0, 1), Valid: true}
+	}
+
+	entries, err := server.store.ListAccountEntries(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := listAccountEntriesResponse{Entries: entries}
+	if len(entries) == int(req.PageSize) {
+		nextCursor := entries[len(entries)-1].ID
+		rsp.NextCursor = &nextCursor
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// maxStatementDays bounds the range of an on-demand statement
+const maxStatementDays = 366
+
+type getAccountStatementRequest struct {
+	From   time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	To     time.Time `form:"to" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	Format string    `form:"format" binding:"omitempty,oneof=csv pdf"`
+}
+
+func (server *Server) getAccountStatement(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getAccountStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// to is inclusive, so the statement ends at the start of the following day
+	to := req.To.AddDate(0, 0, 1)
+	if !to.After(req.From) || to.Sub(req.From) > maxStatementDays*24*time.Hour {
+		err := fmt.Errorf("statement range must cover 1 to %d days", maxStatementDays)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.memberAccount(ctx, uri.ID, util.MemberRoleViewOnly)
+	if !ok {
+		return
+	}
+
+	stmt, err := statement.Build(ctx, server.store, account, req.From, to)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/csv"
+	write := statement.WriteCSV
+	if req.Format == "pdf" {
+		contentType = "application/pdf"
+		write = statement.WritePDF
+	} else {
+		req.Format = "csv"
+	}
+
+	if err := write(&buf, stmt); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, stmt.Filename(req.Format)))
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type listFraudDecisionsRequest struct {
+	Decision string `form:"decision" binding:"omitempty,oneof=allow hold block"`
+	Username string `form:"username"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+type fraudDecisionResponse struct {
+	ID            int64           `json:"id"`
+	Username      string          `json:"username"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	Currency      string          `json:"currency"`
+	Decision      string          `json:"decision"`
+	Rules         json.RawMessage `json:"rules"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func newFraudDecisionResponse(decision db.FraudDecision) fraudDecisionResponse {
+	return fraudDecisionResponse{
+		ID:            decision.ID,
+		Username:      decision.Username,
+		FromAccountID: decision.FromAccountID,
+		ToAccountID:   decision.ToAccountID,
+		Amount:        decision.Amount,
+		Currency:      decision.Currency,
+		Decision:      decision.Decision,
+		Rules:         decision.Rules,
+		CreatedAt:     decision.CreatedAt,
+	}
+}
+
+// listFraudDecisions lists the screened transfers with their triggered rules, newest first
+func (server *Server) listFraudDecisions(ctx *gin.Context) {
+	var req listFraudDecisionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	decisions, err := server.store.ListFraudDecisions(ctx, db.ListFraudDecisionsParams{
+		Decision:   pgtype.Text{String: req.Decision, Valid: req.Decision != ""},
+		Username:   pgtype.Text{String: req.Username, Valid: req.Username != ""},
+		PageLimit:  req.PageSize,
+		PageOffset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]fraudDecisionResponse, len(decisions))
+	for i, decision := range decisions {
+		rsp[i] = newFraudDecisionResponse(decision)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
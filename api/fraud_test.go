@@ -0,0 +1,403 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/fraud"
+	"github.com/RobertChienShiba/simplebank/posting"
+	"github.com/RobertChienShiba/simplebank/util"
+)
+
+type stubScreener struct {
+	screening fraud.Screening
+	err       error
+	screened  *fraud.Transfer
+}
+
+func (screener stubScreener) Screen(ctx context.Context, transfer fraud.Transfer) (fraud.Screening, error) {
+	*screener.screened = transfer
+	return screener.screening, screener.err
+}
+
+func TestCreateTransferFraudScreening(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+
+	decisionID := util.RandomInt(1, 1000)
+	body := transferRequest{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10000,
+		Currency:      util.USD,
+		OTP:           "777777",
+	}
+
+	expectAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+		expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+		store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(2).Return(newRate(t, "1"), nil)
+	}
+
+	testCases := []struct {
+		name          string
+		screening     fraud.Screening
+		screenErr     error
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Allow",
+			screening: fraud.Screening{DecisionID: decisionID, Decision: fraud.Allow},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "Hold",
+			screening: fraud.Screening{DecisionID: decisionID, Decision: fraud.Hold},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().
+					CreateTransferApproval(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTransferApprovalParams) (db.TransferApproval, error) {
+						require.Equal(t, pgtype.Int8{Int64: decisionID, Valid: true}, arg.FraudDecisionID)
+						require.Equal(t, body.Amount, arg.Amount)
+
+						return db.TransferApproval{
+							ID:              1,
+							Amount:          arg.Amount,
+							Status:          util.ApprovalStatusPending,
+							FraudDecisionID: arg.FraudDecisionID,
+						}, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var approval db.TransferApproval
+				err := json.Unmarshal(recorder.Body.Bytes(), &approval)
+				require.NoError(t, err)
+				require.Equal(t, util.ApprovalStatusPending, approval.Status)
+				require.Equal(t, decisionID, approval.FraudDecisionID.Int64)
+			},
+		},
+		{
+			name:      "Block",
+			screening: fraud.Screening{DecisionID: decisionID, Decision: fraud.Block},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), posting.ErrTransferBlocked.Error())
+			},
+		},
+		{
+			name:      "ScreenError",
+			screenErr: sql.ErrConnDone,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			var screened fraud.Transfer
+			server := newTestServer(t, store, nil, nil)
+			server.controls.Fraud = stubScreener{screening: tc.screening, err: tc.screenErr, screened: &screened}
+			server.controls.ApprovalTTL = time.Hour
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			onlyTransferURL := "/api/test/transfers"
+			server.router.POST(
+				onlyTransferURL,
+				csrfVerifyMiddleware(),
+				csrfTokenMiddleware(),
+				authMiddleware(server.tokenMaker),
+				server.createTransfer,
+			)
+			request, err := http.NewRequest(http.MethodPost, onlyTransferURL, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+
+			require.Equal(t, user1.Username, screened.Username)
+			require.Equal(t, account1.ID, screened.FromAccount.ID)
+			require.Equal(t, account2.ID, screened.ToAccount.ID)
+			require.Equal(t, body.Amount, screened.Amount)
+		})
+	}
+}
+
+func TestCreateHoldFraudScreening(t *testing.T) {
+	user, _ := randomUser(t)
+	merchant, _ := randomUser(t)
+
+	account := randomAccount(user.Username)
+	payee := randomAccount(merchant.Username)
+	body := gin.H{
+		"to_account_id": payee.ID,
+		"amount":        500,
+		"currency":      account.Currency,
+		"description":   "hotel deposit",
+		"otp":           "777777",
+	}
+
+	expectAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		expectMember(store, account.ID, user.Username, util.MemberRoleCanTransfer)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		screening     fraud.Screening
+		screenErr     error
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Allow",
+			screening: fraud.Screening{Decision: fraud.Allow},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Hold{ID: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "Hold",
+			screening: fraud.Screening{Decision: fraud.Hold},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Hold{ID: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "Block",
+			screening: fraud.Screening{Decision: fraud.Block},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), posting.ErrTransferBlocked.Error())
+			},
+		},
+		{
+			name:      "ScreenError",
+			screenErr: sql.ErrConnDone,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			var screened fraud.Transfer
+			server := newTestServer(t, store, nil, nil)
+			server.controls.Fraud = stubScreener{screening: tc.screening, err: tc.screenErr, screened: &screened}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			server.router.POST(
+				"/api/test/accounts/:id/holds",
+				csrfVerifyMiddleware(),
+				csrfTokenMiddleware(),
+				authMiddleware(server.tokenMaker),
+				server.createHold,
+			)
+			url := fmt.Sprintf("/api/test/accounts/%d/holds", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+
+			require.Equal(t, user.Username, screened.Username)
+			require.Equal(t, account.ID, screened.FromAccount.ID)
+			require.Equal(t, payee.ID, screened.ToAccount.ID)
+			require.Equal(t, int64(500), screened.Amount)
+		})
+	}
+}
+
+func TestListFraudDecisionsAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	decisions := []db.FraudDecision{
+		{ID: 2, Username: util.RandomOwner(), Amount: 90000, Currency: util.USD, Decision: string(fraud.Block), Rules: []byte(`[{"rule":"velocity","decision":"block","detail":"5 transfers sent in the last 10m0s"}]`)},
+		{ID: 1, Username: util.RandomOwner(), Amount: 1000, Currency: util.USD, Decision: string(fraud.Allow), Rules: []byte(`[]`)},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "decision=block&username=alice&page_id=2&page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListFraudDecisionsParams{
+					Decision:   pgtype.Text{String: "block", Valid: true},
+					Username:   pgtype.Text{String: "alice", Valid: true},
+					PageLimit:  5,
+					PageOffset: 5,
+				}
+				store.EXPECT().ListFraudDecisions(gomock.Any(), gomock.Eq(arg)).Times(1).Return(decisions, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response []struct {
+					ID       int64       `json:"id"`
+					Decision string      `json:"decision"`
+					Rules    []fraud.Hit `json:"rules"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response, 2)
+				require.Equal(t, decisions[0].ID, response[0].ID)
+				require.Equal(t, []fraud.Hit{{Rule: "velocity", Decision: fraud.Block, Detail: "5 transfers sent in the last 10m0s"}}, response[0].Rules)
+				require.Empty(t, response[1].Rules)
+			},
+		},
+		{
+			name:  "InvalidDecision",
+			query: "decision=deny&page_id=1&page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudDecisions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "Depositor",
+			query: "page_id=1&page_size=5",
+			role:  util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudDecisions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_id=1&page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFraudDecisions(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/auth/fraud/decisions?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrQuoteExpired is returned for a quote that expired, was already used or never existed
+var ErrQuoteExpired = errors.New("quote has expired or doesn't exist")
+
+func fxQuoteKey(id string) string {
+	return "fxq:" + id
+}
+
+// fxQuote is a conversion rate locked for the user who asked for it, Redis drops it once it expires.
+// Rate keeps the exact fraction so the transfer converts exactly as quoted.
+type fxQuote struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	Amount       int64     `json:"amount"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	Rate         string    `json:"rate"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type createFXQuoteRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,currency"`
+	ToCurrency   string `json:"to_currency" binding:"required,currency"`
+	Amount       int64  `json:"amount" binding:"required,gt=0"`
+}
+
+type fxQuoteResponse struct {
+	QuoteID        string      `json:"quote_id"`
+	SentAmount     money.Money `json:"sent_amount"`
+	ReceivedAmount money.Money `json:"received_amount"`
+	ExchangeRate   string      `json:"exchange_rate"`
+	ExpiresAt      time.Time   `json:"expires_at"`
+}
+
+// createFXQuote shows what the recipient will get and locks the rate for FX_QUOTE_TTL
+func (server *Server) createFXQuote(ctx *gin.Context) {
+	var req createFXQuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	conversion, err := server.currencyExchange(ctx, money.New(req.Amount, req.FromCurrency), req.ToCurrency)
+	if err != nil {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	quote := fxQuote{
+		ID:           uuid.NewString(),
+		Username:     authPayload.Username,
+		Amount:       req.Amount,
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		Rate:         conversion.Rate.String(),
+		ExpiresAt:    time.Now().Add(server.config.FXQuoteTTL).UTC(),
+	}
+
+	data, err := json.Marshal(quote)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.kvStore.Set(fxQuoteKey(quote.ID), data, server.config.FXQuoteTTL)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, fxQuoteResponse{
+		QuoteID:        quote.ID,
+		SentAmount:     conversion.Source,
+		ReceivedAmount: conversion.Target,
+		ExchangeRate:   conversion.Rate.FloatString(money.RateScale),
+		ExpiresAt:      quote.ExpiresAt,
+	})
+}
+
+// claimedQuote is a quote taken out of Redis by the transfer it prices
+type claimedQuote struct {
+	key       string
+	data      string
+	expiresAt time.Time
+}
+
+// quotedExchange converts an amount at the rate locked by a quote of the authenticated user.
+// The quote must cover exactly this amount and pair of currencies. It is claimed atomically,
+// so concurrent transfers can't both use it, and must be released if no transfer is made with it.
+func (server *Server) quotedExchange(ctx *gin.Context, quoteID string, amount money.Money, toCurrency string) (money.Conversion, *claimedQuote, error) {
+	data, err := server.kvStore.GetDel(fxQuoteKey(quoteID))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(ErrQuoteExpired))
+			return money.Conversion{}, nil, ErrQuoteExpired
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return money.Conversion{}, nil, err
+	}
+
+	var quote fxQuote
+	if err := json.Unmarshal([]byte(data), &quote); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return money.Conversion{}, nil, err
+	}
+	claim := &claimedQuote{key: fxQuoteKey(quoteID), data: data, expiresAt: quote.ExpiresAt}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if quote.Username != authPayload.Username {
+		server.releaseQuote(claim)
+		err := errors.New("quote doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return money.Conversion{}, nil, err
+	}
+
+	if !time.Now().Before(quote.ExpiresAt) {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(ErrQuoteExpired))
+		return money.Conversion{}, nil, ErrQuoteExpired
+	}
+
+	if quote.Amount != amount.Amount || quote.FromCurrency != amount.Currency || quote.ToCurrency != toCurrency {
+		server.releaseQuote(claim)
+		err := fmt.Errorf("quote covers %s to %s, not %s to %s",
+			money.New(quote.Amount, quote.FromCurrency), quote.ToCurrency, amount, toCurrency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return money.Conversion{}, nil, err
+	}
+
+	rate, ok := new(big.Rat).SetString(quote.Rate)
+	if !ok {
+		err := fmt.Errorf("invalid rate %q in quote", quote.Rate)
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return money.Conversion{}, nil, err
+	}
+
+	conversion, err := money.ConvertAt(amount, toCurrency, rate, server.roundingMode)
+	if err != nil {
+		server.releaseQuote(claim)
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return money.Conversion{}, nil, err
+	}
+
+	return conversion, claim, nil
+}
+
+// releaseQuote gives back a claimed quote for the rest of its lifetime, a quote locks the rate for a single transfer
+func (server *Server) releaseQuote(claim *claimedQuote) {
+	if claim == nil {
+		return
+	}
+	ttl := time.Until(claim.expiresAt)
+	if ttl <= 0 {
+		return
+	}
+	if err := server.kvStore.Set(claim.key, claim.data, ttl); err != nil {
+		log.Error().Err(err).Str("key", claim.key).Msg("failed to release fx quote")
+	}
+}
@@ -0,0 +1,444 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
+	mocksession "github.com/RobertChienShiba/simplebank/redis/mock"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/util"
+)
+
+func TestCreateFXQuoteAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore, session *mocksession.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.EUR,
+				"amount":        1000,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(newRate(t, "32.5"), nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.EUR)).Times(1).Return(newRate(t, "35"), nil)
+				session.EXPECT().
+					Set(gomock.Any(), gomock.Any(), gomock.Eq(time.Minute)).
+					Times(1).
+					DoAndReturn(func(key string, value interface{}, expiration time.Duration) error {
+						require.True(t, strings.HasPrefix(key, "fxq:"))
+
+						var quote fxQuote
+						require.NoError(t, json.Unmarshal(value.([]byte), &quote))
+						require.Equal(t, fxQuoteKey(quote.ID), key)
+						require.Equal(t, user.Username, quote.Username)
+						require.Equal(t, int64(1000), quote.Amount)
+						require.Equal(t, util.USD, quote.FromCurrency)
+						require.Equal(t, util.EUR, quote.ToCurrency)
+						require.Equal(t, "13/14", quote.Rate)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response fxQuoteResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.NoError(t, uuid.Validate(response.QuoteID))
+				require.Equal(t, money.New(1000, util.USD), response.SentAmount)
+				require.Equal(t, money.New(929, util.EUR), response.ReceivedAmount)
+				require.Equal(t, "0.928571428571", response.ExchangeRate)
+				require.WithinDuration(t, time.Now().Add(time.Minute), response.ExpiresAt, time.Second)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.EUR,
+				"amount":        1000,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+				session.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			body: gin.H{
+				"from_currency": "XYZ",
+				"to_currency":   util.EUR,
+				"amount":        1000,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+				session.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NegativeAmount",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.EUR,
+				"amount":        -1,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+				session.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExchangeRateNotFound",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.EUR,
+				"amount":        1000,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(newRate(t, "32.5"), nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.EUR)).Times(1).Return(newRate(t, "0"), db.ErrRecordNotFound)
+				session.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "SessionStoreError",
+			body: gin.H{
+				"from_currency": util.USD,
+				"to_currency":   util.EUR,
+				"amount":        1000,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(2).Return(newRate(t, "1"), nil)
+				session.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(errors.New("redis is down"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			session := mocksession.NewMockStore(ctrl)
+			tc.buildStubs(store, session)
+
+			server := newTestServer(t, store, session, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api/auth/fx/quotes", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.router, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateTransferWithQuote(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.EUR
+
+	quoteID := uuid.NewString()
+	// the locked rate differs from the current one, the transfer must not look the rates up again
+	quote := fxQuote{
+		ID:           quoteID,
+		Username:     user1.Username,
+		Amount:       1000,
+		FromCurrency: util.USD,
+		ToCurrency:   util.EUR,
+		Rate:         "9/10",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	newQuote := func(change func(quote *fxQuote)) string {
+		q := quote
+		if change != nil {
+			change(&q)
+		}
+		data, err := json.Marshal(q)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	body := transferRequest{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1000,
+		Currency:      util.USD,
+		OTP:           "777777",
+		QuoteID:       quoteID,
+	}
+
+	testCases := []struct {
+		name          string
+		body          transferRequest
+		buildStubs    func(store *mockdb.MockStore, session *mocksession.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+				session.EXPECT().GetDel(gomock.Eq(fxQuoteKey(quoteID))).Times(1).Return(newQuote(nil), nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					FromAmount:    1000,
+					ToAmount:      900,
+					ExchangeRate:  money.NumericFromRat(big.NewRat(9, 10)),
+					Username:      user1.Username,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{}, nil)
+				// the claimed quote is used up
+				session.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response transferResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, money.New(1000, util.USD), response.SentAmount)
+				require.Equal(t, money.New(900, util.EUR), response.ReceivedAmount)
+				require.Equal(t, "0.900000000000", response.ExchangeRate)
+			},
+		},
+		{
+			name: "QuoteNotFound",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				session.EXPECT().GetDel(gomock.Eq(fxQuoteKey(quoteID))).Times(1).Return("", redis.Nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				session.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "QuoteExpired",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				session.EXPECT().
+					GetDel(gomock.Eq(fxQuoteKey(quoteID))).
+					Times(1).
+					Return(newQuote(func(quote *fxQuote) { quote.ExpiresAt = time.Now().Add(-time.Second) }), nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "QuoteOfAnotherUser",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				session.EXPECT().
+					GetDel(gomock.Eq(fxQuoteKey(quoteID))).
+					Times(1).
+					Return(newQuote(func(quote *fxQuote) { quote.Username = user2.Username }), nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				// the quote is given back to the transfer it was made for
+				session.EXPECT().Set(gomock.Eq(fxQuoteKey(quoteID)), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "QuoteAmountMismatch",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				session.EXPECT().
+					GetDel(gomock.Eq(fxQuoteKey(quoteID))).
+					Times(1).
+					Return(newQuote(func(quote *fxQuote) { quote.Amount = 500 }), nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				// the quote is given back to the transfer it was made for
+				session.EXPECT().Set(gomock.Eq(fxQuoteKey(quoteID)), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SessionStoreError",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				session.EXPECT().GetDel(gomock.Eq(fxQuoteKey(quoteID))).Times(1).Return("", errors.New("redis is down"))
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "TransferTxError",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				session.EXPECT().GetDel(gomock.Eq(fxQuoteKey(quoteID))).Times(1).Return(newQuote(nil), nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, sql.ErrConnDone)
+				// the quote stays usable when the transfer fails
+				session.EXPECT().Set(gomock.Eq(fxQuoteKey(quoteID)), gomock.Eq(newQuote(nil)), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidQuoteID",
+			body: func() transferRequest {
+				req := body
+				req.QuoteID = "not-a-uuid"
+				return req
+			}(),
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				session.EXPECT().GetDel(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			session := mocksession.NewMockStore(ctrl)
+			tc.buildStubs(store, session)
+
+			server := newTestServer(t, store, session, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			onlyTransferURL := "/api/test/transfers"
+			server.router.POST(
+				onlyTransferURL,
+				csrfVerifyMiddleware(),
+				csrfTokenMiddleware(),
+				authMiddleware(server.tokenMaker),
+				server.createTransfer,
+			)
+			request, err := http.NewRequest(http.MethodPost, onlyTransferURL, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/posting"
+	"github.com/RobertChienShiba/simplebank/rbac"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultHoldHours is how long a hold reserves funds when the request doesn't say
+const defaultHoldHours = 7 * 24
+
+type createHoldRequest struct {
+	ToAccountID    int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount         int64  `json:"amount" binding:"required,gt=0"`
+	Currency       string `json:"currency" binding:"required,currency"`
+	Description    string `json:"description" binding:"required,max=255"`
+	ExpiresInHours int64  `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+	OTP            string `json:"otp" binding:"required,min=6,max=6,numeric"`
+}
+
+// createHold reserves funds of an account of the authenticated user for a payee
+func (server *Server) createHold(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req createHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, uri.ID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeMember(ctx, account, util.MemberRoleCanTransfer) {
+		return
+	}
+
+	if req.ToAccountID == account.ID {
+		err := errors.New("a hold can't be placed for the held account itself")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, req.ToAccountID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hours := req.ExpiresInHours
+	if hours == 0 {
+		hours = defaultHoldHours
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	// A held decision doesn't stop the hold, its capture is screened again and waits for a banker
+	if !server.screenTransfer(ctx, posting.Transfer{
+		Username:    authPayload.Username,
+		FromAccount: account,
+		ToAccount:   toAccount,
+		Params: db.TransferTxParams{
+			FromAccountID: account.ID,
+			ToAccountID:   toAccount.ID,
+			FromAmount:    req.Amount,
+			Username:      authPayload.Username,
+		},
+	}) {
+		return
+	}
+
+	hold, err := server.store.AuthorizeHoldTx(ctx, db.AuthorizeHoldTxParams{
+		AccountID:   account.ID,
+		ToAccountID: req.ToAccountID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		CreatedBy:   authPayload.Username,
+		ExpiresAt:   time.Now().Add(time.Duration(hours) * time.Hour),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientBalance):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+type listHoldsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// listHolds lists the holds placed on an account of the authenticated user, newest first
+func (server *Server) listHolds(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listHoldsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.memberAccount(ctx, uri.ID, util.MemberRoleViewOnly)
+	if !ok {
+		return
+	}
+
+	holds, err := server.store.ListHolds(ctx, db.ListHoldsParams{
+		AccountID: account.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, holds)
+}
+
+type holdRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type captureHoldRequest struct {
+	// Amount defaults to the whole hold, a smaller amount releases the rest
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// captureHold transfers the held funds to the payee, only the payee's owner or a banker may do it
+func (server *Server) captureHold(ctx *gin.Context) {
+	var uri holdRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req captureHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, toAccount, ok := server.payeeHold(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	amount := hold.Amount
+	if req.Amount != 0 {
+		amount = req.Amount
+	}
+	if amount > hold.Amount {
+		ctx.JSON(http.StatusBadRequest, errorResponse(db.ErrHoldAmountExceeded))
+		return
+	}
+
+	fromAccount, err := server.store.GetAccount(ctx, hold.AccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	conversion, err := server.currencyExchange(ctx, money.New(amount, fromAccount.Currency), toAccount.Currency)
+	if err != nil {
+		return
+	}
+
+	arg := db.CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Transfer: db.TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			FromAmount:    conversion.Source.Amount,
+			ToAmount:      conversion.Target.Amount,
+			ExchangeRate:  money.NumericFromRat(conversion.Rate),
+			// The payer authorized the debit when placing the hold, so it counts against their limits
+			Username: hold.CreatedBy,
+		},
+	}
+	if conversion.Remainder.Sign() != 0 {
+		arg.Transfer.RoundingRemainder = money.NumericFromRat(conversion.Remainder)
+	}
+
+	// The hold keeps reserving the funds while a capture waits for approval
+	approval, ok := server.checkTransfer(ctx, posting.Transfer{
+		Username:    hold.CreatedBy,
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		Params:      arg.Transfer,
+		HoldID:      hold.ID,
+	})
+	if !ok {
+		return
+	}
+	if approval != nil {
+		ctx.JSON(http.StatusAccepted, approval)
+		return
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientBalance), errors.Is(err, db.ErrHoldAmountExceeded):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		case errors.Is(err, db.ErrHoldNotActive), errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrTransferLimitExceeded):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// releaseHold gives the held funds back to the account, only the payee's owner or a banker may do it
+func (server *Server) releaseHold(ctx *gin.Context) {
+	var uri holdRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, _, ok := server.payeeHold(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	hold, err := server.store.ReleaseHold(ctx, hold.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrHoldNotActive))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+// payeeHold loads a hold and its payee account, answering for the caller unless they own the payee account or are a banker
+func (server *Server) payeeHold(ctx *gin.Context, id int64) (db.Hold, db.Account, bool) {
+	hold, err := server.store.GetHold(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return hold, db.Account{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, db.Account{}, false
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, hold.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, toAccount, false
+	}
+
+	// Roles granted any scope, e.g. bankers, settle holds on behalf of any payee
+	if grantedScope(ctx) != rbac.ScopeAny && !server.authorizeMember(ctx, toAccount, util.MemberRoleCanTransfer) {
+		return hold, toAccount, false
+	}
+
+	return hold, toAccount, true
+}
@@ -0,0 +1,495 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/rbac"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateHoldAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	merchant, _ := randomUser(t)
+
+	account := randomAccount(user.Username)
+	payee := randomAccount(merchant.Username)
+	amount := int64(500)
+	testOTP := "777777"
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			body: gin.H{
+				"to_account_id":    payee.ID,
+				"amount":           amount,
+				"currency":         account.Currency,
+				"description":      "hotel deposit",
+				"otp":              testOTP,
+				"expires_in_hours": 48,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().
+					AuthorizeHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.AuthorizeHoldTxParams) (db.Hold, error) {
+						require.Equal(t, account.ID, arg.AccountID)
+						require.Equal(t, payee.ID, arg.ToAccountID)
+						require.Equal(t, amount, arg.Amount)
+						require.Equal(t, user.Username, arg.CreatedBy)
+						require.WithinDuration(t, time.Now().Add(48*time.Hour), arg.ExpiresAt, time.Minute)
+						return db.Hold{ID: 1, AccountID: arg.AccountID, ToAccountID: arg.ToAccountID, Amount: arg.Amount, Status: util.HoldStatusActive}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var hold db.Hold
+				err := json.Unmarshal(recorder.Body.Bytes(), &hold)
+				require.NoError(t, err)
+				require.Equal(t, util.HoldStatusActive, hold.Status)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: merchant.Username,
+			body: gin.H{
+				"to_account_id": payee.ID,
+				"amount":        amount,
+				"currency":      account.Currency,
+				"description":   "hotel deposit",
+				"otp":           testOTP,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectNoMember(store, account.ID, merchant.Username)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "SameAccount",
+			username: user.Username,
+			body: gin.H{
+				"to_account_id": account.ID,
+				"amount":        amount,
+				"currency":      account.Currency,
+				"description":   "hotel deposit",
+				"otp":           testOTP,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ExpiryTooLong",
+			username: user.Username,
+			body: gin.H{
+				"to_account_id":    payee.ID,
+				"amount":           amount,
+				"currency":         account.Currency,
+				"description":      "hotel deposit",
+				"otp":              testOTP,
+				"expires_in_hours": 721,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "MissingOTP",
+			username: user.Username,
+			body: gin.H{
+				"to_account_id": payee.ID,
+				"amount":        amount,
+				"currency":      account.Currency,
+				"description":   "hotel deposit",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "PayeeNotFound",
+			username: user.Username,
+			body: gin.H{
+				"to_account_id": payee.ID,
+				"amount":        amount,
+				"currency":      account.Currency,
+				"description":   "hotel deposit",
+				"otp":           testOTP,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InsufficientAvailableBalance",
+			username: user.Username,
+			body: gin.H{
+				"to_account_id": payee.ID,
+				"amount":        amount,
+				"currency":      account.Currency,
+				"description":   "hotel deposit",
+				"otp":           testOTP,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Hold{}, db.ErrInsufficientBalance)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: user.Username,
+			body: gin.H{
+				"to_account_id": payee.ID,
+				"amount":        amount,
+				"currency":      account.Currency,
+				"description":   "hotel deposit",
+				"otp":           testOTP,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMember(store, account.ID, user.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().AuthorizeHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Hold{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			// OTP, idempotency and rate limits are covered by their middleware tests
+			server.router.POST(
+				"/api/test/accounts/:id/holds",
+				csrfVerifyMiddleware(),
+				csrfTokenMiddleware(),
+				authMiddleware(server.tokenMaker),
+				server.requirePermission(rbac.CreateTransfer),
+				server.createHold,
+			)
+			url := fmt.Sprintf("/api/test/accounts/%d/holds", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSettleHoldAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	merchant, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	account := randomAccount(user.Username)
+	payee := randomAccount(merchant.Username)
+	payee.Currency = account.Currency
+	hold := db.Hold{
+		ID:          7,
+		AccountID:   account.ID,
+		ToAccountID: payee.ID,
+		Amount:      500,
+		Status:      util.HoldStatusActive,
+		CreatedBy:   user.Username,
+		ExpiresAt:   time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+	released := hold
+	released.Status = util.HoldStatusReleased
+
+	testCases := []struct {
+		name     string
+		action   string
+		username string
+		role     string
+		body     gin.H
+		// approvalThreshold is in the currency of the held account, zero when captures never need approval
+		approvalThreshold int64
+		buildStubs        func(store *mockdb.MockStore)
+		checkResponse     func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "PartialCapture",
+			action:   "capture",
+			username: merchant.Username,
+			role:     merchant.Role,
+			body:     gin.H{"amount": 300},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				expectMember(store, payee.ID, merchant.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(account.Currency)).Times(2).Return(newRate(t, "1"), nil)
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Eq(db.CaptureHoldTxParams{
+						HoldID: hold.ID,
+						Transfer: db.TransferTxParams{
+							FromAccountID: account.ID,
+							ToAccountID:   payee.ID,
+							Username:      user.Username,
+							FromAmount:    300,
+							ToAmount:      300,
+							ExchangeRate:  money.NumericFromRat(big.NewRat(1, 1)),
+						},
+					})).
+					Times(1).
+					Return(db.CaptureHoldTxResult{Hold: db.Hold{ID: hold.ID, Status: util.HoldStatusCaptured}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.CaptureHoldTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Equal(t, util.HoldStatusCaptured, result.Hold.Status)
+			},
+		},
+		{
+			name:              "CaptureAboveApprovalThreshold",
+			action:            "capture",
+			username:          merchant.Username,
+			role:              merchant.Role,
+			approvalThreshold: 400,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				expectMember(store, payee.ID, merchant.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(account.Currency)).Times(2).Return(newRate(t, "1"), nil)
+				store.EXPECT().
+					CreateTransferApproval(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTransferApprovalParams) (db.TransferApproval, error) {
+						require.Equal(t, account.ID, arg.FromAccountID)
+						require.Equal(t, payee.ID, arg.ToAccountID)
+						require.Equal(t, hold.Amount, arg.Amount)
+						// The payer placed the hold, so they request the approval
+						require.Equal(t, user.Username, arg.RequestedBy)
+						require.Equal(t, pgtype.Int8{Int64: hold.ID, Valid: true}, arg.HoldID)
+
+						return db.TransferApproval{
+							ID:          1,
+							Amount:      arg.Amount,
+							Status:      util.ApprovalStatusPending,
+							RequestedBy: arg.RequestedBy,
+							HoldID:      arg.HoldID,
+						}, nil
+					})
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var approval db.TransferApproval
+				err := json.Unmarshal(recorder.Body.Bytes(), &approval)
+				require.NoError(t, err)
+				require.Equal(t, util.ApprovalStatusPending, approval.Status)
+				require.Equal(t, hold.ID, approval.HoldID.Int64)
+			},
+		},
+		{
+			name:     "CaptureMoreThanHeld",
+			action:   "capture",
+			username: merchant.Username,
+			role:     merchant.Role,
+			body:     gin.H{"amount": 501},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				expectMember(store, payee.ID, merchant.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "PayerCantCapture",
+			action:   "capture",
+			username: user.Username,
+			role:     user.Role,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				expectNoMember(store, payee.ID, user.Username)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "CaptureInactiveHold",
+			action:   "capture",
+			username: banker.Username,
+			role:     banker.Role,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(2).Return(newRate(t, "1"), nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldTxResult{}, db.ErrHoldNotActive)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "Release",
+			action:   "release",
+			username: merchant.Username,
+			role:     merchant.Role,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				expectMember(store, payee.ID, merchant.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().ReleaseHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(released, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.Hold
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, released, got)
+			},
+		},
+		{
+			name:     "ReleaseInactiveHold",
+			action:   "release",
+			username: banker.Username,
+			role:     banker.Role,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(released, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().ReleaseHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Hold{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "HoldNotFound",
+			action:   "release",
+			username: merchant.Username,
+			role:     merchant.Role,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Hold{}, db.ErrRecordNotFound)
+				store.EXPECT().ReleaseHold(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			if tc.approvalThreshold != 0 {
+				threshold := money.New(tc.approvalThreshold, account.Currency)
+				server.controls.ApprovalThreshold = &threshold
+			}
+			recorder := httptest.NewRecorder()
+
+			var body []byte
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = data
+			}
+
+			url := fmt.Sprintf("/api/auth/holds/%d/%s", hold.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
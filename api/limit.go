@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type transferLimitsResponse struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	// Limits are the role defaults followed by the user's overrides
+	Limits    []db.TransferLimit          `json:"limits"`
+	Effective []db.EffectiveTransferLimit `json:"effective"`
+}
+
+// getMyTransferLimits returns the limits applied to the authenticated user
+func (server *Server) getMyTransferLimits(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	server.respondTransferLimits(ctx, authPayload.Username)
+}
+
+type userLimitsRequest struct {
+	Username string `uri:"username" binding:"required"`
+}
+
+// getUserTransferLimits returns the limits applied to any user
+func (server *Server) getUserTransferLimits(ctx *gin.Context) {
+	var req userLimitsRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	server.respondTransferLimits(ctx, req.Username)
+}
+
+func (server *Server) respondTransferLimits(ctx *gin.Context, username string) {
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	limits, err := server.store.ListTransferLimits(ctx, db.ListTransferLimitsParams{
+		Username: pgtype.Text{String: user.Username, Valid: true},
+		Role:     pgtype.Text{String: user.Role, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transferLimitsResponse{
+		Username:  user.Username,
+		Role:      user.Role,
+		Limits:    limits,
+		Effective: db.EffectiveTransferLimits(limits),
+	})
+}
+
+// transferLimitRequest sets the limits of one currency in its minor unit, an omitted limit is inherited or unlimited
+type transferLimitRequest struct {
+	Currency   string `json:"currency" binding:"required,currency"`
+	MaxSingle  *int64 `json:"max_single" binding:"omitempty,gt=0"`
+	MaxDaily   *int64 `json:"max_daily" binding:"omitempty,gt=0"`
+	MaxMonthly *int64 `json:"max_monthly" binding:"omitempty,gt=0"`
+}
+
+// userTransferLimitRequest overrides the limits of one currency, 0 makes a limit unlimited whatever the role default is
+type userTransferLimitRequest struct {
+	Currency   string `json:"currency" binding:"required,currency"`
+	MaxSingle  *int64 `json:"max_single" binding:"omitempty,gte=0"`
+	MaxDaily   *int64 `json:"max_daily" binding:"omitempty,gte=0"`
+	MaxMonthly *int64 `json:"max_monthly" binding:"omitempty,gte=0"`
+}
+
+// setUserTransferLimit overrides the role defaults of a single user
+func (server *Server) setUserTransferLimit(ctx *gin.Context) {
+	var uri userLimitsRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req userTransferLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	limit, err := server.store.UpsertUserTransferLimit(ctx, db.UpsertUserTransferLimitParams{
+		Username:   pgtype.Text{String: uri.Username, Valid: true},
+		Currency:   req.Currency,
+		MaxSingle:  optionalInt8(req.MaxSingle),
+		MaxDaily:   optionalInt8(req.MaxDaily),
+		MaxMonthly: optionalInt8(req.MaxMonthly),
+	})
+	if err != nil {
+		if db.ErrorCode(err) == db.ForeignKeyViolation {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("user not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
+
+type roleLimitsRequest struct {
+	Role string `uri:"role" binding:"required,oneof=depositor banker"`
+}
+
+// setRoleTransferLimit sets the default limits of every user of a role
+func (server *Server) setRoleTransferLimit(ctx *gin.Context) {
+	var uri roleLimitsRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req transferLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	limit, err := server.store.UpsertRoleTransferLimit(ctx, db.UpsertRoleTransferLimitParams{
+		Role:       pgtype.Text{String: uri.Role, Valid: true},
+		Currency:   req.Currency,
+		MaxSingle:  optionalInt8(req.MaxSingle),
+		MaxDaily:   optionalInt8(req.MaxDaily),
+		MaxMonthly: optionalInt8(req.MaxMonthly),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
+
+func optionalInt8(value *int64) pgtype.Int8 {
+	if value == nil {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: *value, Valid: true}
+}
@@ -0,0 +1,302 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransferLimitsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	limits := []db.TransferLimit{
+		{ID: 1, Role: pgtype.Text{String: user.Role, Valid: true}, Currency: util.USD,
+			MaxSingle: pgtype.Int8{Int64: 1000, Valid: true}, MaxDaily: pgtype.Int8{Int64: 5000, Valid: true}},
+		{ID: 2, Username: pgtype.Text{String: user.Username, Valid: true}, Currency: util.USD,
+			MaxDaily: pgtype.Int8{Int64: 9000, Valid: true}},
+	}
+
+	testCases := []struct {
+		name          string
+		url           string
+		authUser      db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Own",
+			url:      "/api/auth/limits",
+			authUser: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					ListTransferLimits(gomock.Any(), gomock.Eq(db.ListTransferLimitsParams{
+						Username: pgtype.Text{String: user.Username, Valid: true},
+						Role:     pgtype.Text{String: user.Role, Valid: true},
+					})).
+					Times(1).
+					Return(limits, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response transferLimitsResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response.Limits, 2)
+				require.Equal(t, []db.EffectiveTransferLimit{{
+					Currency:  util.USD,
+					MaxSingle: pgtype.Int8{Int64: 1000, Valid: true},
+					MaxDaily:  pgtype.Int8{Int64: 9000, Valid: true},
+				}}, response.Effective)
+			},
+		},
+		{
+			name:     "BankerViewsUser",
+			url:      "/api/auth/limits/users/" + user.Username,
+			authUser: banker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().ListTransferLimits(gomock.Any(), gomock.Any()).Times(1).Return(limits, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "DepositorForbidden",
+			url:      "/api/auth/limits/users/" + banker.Username,
+			authUser: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListTransferLimits(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			url:      "/api/auth/limits/users/" + user.Username,
+			authUser: banker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().ListTransferLimits(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			url:      "/api/auth/limits",
+			authUser: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().ListTransferLimits(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.authUser.Username, tc.authUser.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetTransferLimitAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	testCases := []struct {
+		name          string
+		url           string
+		role          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "UserOverride",
+			url:  "/api/auth/limits/users/" + user.Username,
+			role: banker.Role,
+			body: gin.H{"currency": util.USD, "max_daily": 20000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertUserTransferLimit(gomock.Any(), gomock.Eq(db.UpsertUserTransferLimitParams{
+						Username: pgtype.Text{String: user.Username, Valid: true},
+						Currency: util.USD,
+						MaxDaily: pgtype.Int8{Int64: 20000, Valid: true},
+					})).
+					Times(1).
+					Return(db.TransferLimit{ID: 1, Username: pgtype.Text{String: user.Username, Valid: true}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UserOverrideUnlimited",
+			url:  "/api/auth/limits/users/" + user.Username,
+			role: banker.Role,
+			body: gin.H{"currency": util.USD, "max_daily": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertUserTransferLimit(gomock.Any(), gomock.Eq(db.UpsertUserTransferLimitParams{
+						Username: pgtype.Text{String: user.Username, Valid: true},
+						Currency: util.USD,
+						MaxDaily: pgtype.Int8{Int64: 0, Valid: true},
+					})).
+					Times(1).
+					Return(db.TransferLimit{ID: 1, Username: pgtype.Text{String: user.Username, Valid: true}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RoleDefault",
+			url:  "/api/auth/limits/roles/" + util.DepositorRole,
+			role: banker.Role,
+			body: gin.H{"currency": util.EUR, "max_single": 1000, "max_daily": 5000, "max_monthly": 50000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertRoleTransferLimit(gomock.Any(), gomock.Eq(db.UpsertRoleTransferLimitParams{
+						Role:       pgtype.Text{String: util.DepositorRole, Valid: true},
+						Currency:   util.EUR,
+						MaxSingle:  pgtype.Int8{Int64: 1000, Valid: true},
+						MaxDaily:   pgtype.Int8{Int64: 5000, Valid: true},
+						MaxMonthly: pgtype.Int8{Int64: 50000, Valid: true},
+					})).
+					Times(1).
+					Return(db.TransferLimit{ID: 2}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DepositorForbidden",
+			url:  "/api/auth/limits/users/" + user.Username,
+			role: util.DepositorRole,
+			body: gin.H{"currency": util.USD, "max_daily": 20000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertUserTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidLimit",
+			url:  "/api/auth/limits/users/" + user.Username,
+			role: banker.Role,
+			body: gin.H{"currency": util.USD, "max_daily": -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertUserTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "RoleDefaultZero",
+			url:  "/api/auth/limits/roles/" + util.DepositorRole,
+			role: banker.Role,
+			body: gin.H{"currency": util.USD, "max_daily": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertRoleTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRole",
+			url:  "/api/auth/limits/roles/admin",
+			role: banker.Role,
+			body: gin.H{"currency": util.USD, "max_daily": 20000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertRoleTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			url:  "/api/auth/limits/users/" + user.Username,
+			role: banker.Role,
+			body: gin.H{"currency": util.USD, "max_daily": 20000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertUserTransferLimit(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferLimit{}, db.ErrForeignKeyViolation)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, banker.Username, tc.role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
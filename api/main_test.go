@@ -1,12 +1,16 @@
 package api
 
 import (
+	"context"
 	"os"
 	"testing"
 	"time"
 
 	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/fraud"
+	"github.com/RobertChienShiba/simplebank/rbac"
 	rds "github.com/RobertChienShiba/simplebank/redis"
+	"github.com/RobertChienShiba/simplebank/token"
 	"github.com/RobertChienShiba/simplebank/util"
 	"github.com/RobertChienShiba/simplebank/worker"
 	"github.com/gin-gonic/gin"
@@ -20,14 +24,44 @@ func newTestServer(t *testing.T, store db.Store, sessionStore rds.Store, taskDis
 		AccessTokenDuration: time.Minute,
 		APILimitBound:       int64(10),
 		APILimitDuration:    5 * time.Minute,
+		FXQuoteTTL:          time.Minute,
 	}
 
-	server, err := NewServer(config, store, sessionStore, taskDistributor)
+	server, err := NewServer(config, store, sessionStore, taskDistributor, rbac.DefaultPolicy())
 	require.NoError(t, err)
 
+	// Handler tests don't expect the audit rows, TestAuditMiddleware checks them against the store
+	server.auditLog = discardAuditLog{}
+	// Nor the revocation lookups, TestTokenRevocationMiddleware checks them against the session store
+	server.tokenRevoker = noTokenRevoker{}
+	// Nor the fraud rules, TestCreateTransferFraudScreening swaps in its own screener
+	server.controls.Fraud = allowScreener{}
+
 	return server
 }
 
+type discardAuditLog struct{}
+
+func (discardAuditLog) CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+	return db.AuditLog{}, nil
+}
+
+type noTokenRevoker struct{}
+
+func (noTokenRevoker) RevokeTokens(username string) error {
+	return nil
+}
+
+func (noTokenRevoker) IsRevoked(payload *token.Payload) (bool, error) {
+	return false, nil
+}
+
+type allowScreener struct{}
+
+func (allowScreener) Screen(ctx context.Context, transfer fraud.Transfer) (fraud.Screening, error) {
+	return fraud.Screening{Decision: fraud.Allow}, nil
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 
@@ -2,6 +2,9 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,12 +14,15 @@ import (
 	"strings"
 	"time"
 
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
 	rds "github.com/RobertChienShiba/simplebank/redis"
 	"github.com/RobertChienShiba/simplebank/token"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/csrf"
 	adapter "github.com/gwatts/gin-adapter"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 	"github.com/xlzd/gotp"
 )
 
@@ -24,6 +30,9 @@ const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotentReplayHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
 )
 
 func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
@@ -109,6 +118,145 @@ func verifyOTPMiddleware(otpStore rds.Store, otpDuration time.Duration) gin.Hand
 	}
 }
 
+// responseRecorder keeps a copy of the response body so that it can be persisted
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMiddleware replays the stored response of a request that carries an
+// Idempotency-Key which has already been processed for the authenticated user.
+// A key can be reused once it is older than ttl.
+func idempotencyMiddleware(store db.Store, ttl time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			err := fmt.Errorf("idempotency key must not exceed %d characters", maxIdempotencyKeyLength)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		body, _ := ctx.GetRawData()
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		requestPath := ctx.FullPath()
+
+		hash := sha256.New()
+		hash.Write([]byte(ctx.Request.Method + " " + requestPath + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		keyParams := db.DeleteIdempotencyKeyParams{
+			Username:       authPayload.Username,
+			IdempotencyKey: key,
+		}
+
+		record, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+			Username:       authPayload.Username,
+			IdempotencyKey: key,
+		})
+		// An expired key is dropped before the sweep gets to it, so the request runs as a new one
+		if err == nil && !record.ExpiresAt.After(time.Now()) {
+			if err = store.DeleteIdempotencyKey(ctx, keyParams); err == nil {
+				err = db.ErrRecordNotFound
+			}
+		}
+		if err == nil {
+			if record.RequestHash != requestHash {
+				err := errors.New("idempotency key has already been used with a different request")
+				ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse(err))
+				return
+			}
+
+			if !record.ResponseCode.Valid {
+				err := errors.New("a request with this idempotency key is still being processed")
+				ctx.AbortWithStatusJSON(http.StatusConflict, errorResponse(err))
+				return
+			}
+
+			ctx.Header(idempotentReplayHeader, "true")
+			ctx.Data(int(record.ResponseCode.Int32), "application/json; charset=utf-8", record.ResponseBody)
+			ctx.Abort()
+			return
+		}
+
+		if !errors.Is(err, db.ErrRecordNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		_, err = store.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+			Username:       authPayload.Username,
+			IdempotencyKey: key,
+			RequestPath:    requestPath,
+			RequestHash:    requestHash,
+			ExpiresAt:      time.Now().Add(ttl),
+		})
+		if err != nil {
+			if db.ErrorCode(err) == db.UniqueViolation {
+				err := errors.New("a request with this idempotency key is still being processed")
+				ctx.AbortWithStatusJSON(http.StatusConflict, errorResponse(err))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}}
+		ctx.Writer = recorder
+		ctx.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		// gin's recovery answers a panic with 500 after this returns, so the key is released like any server error
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if err := store.DeleteIdempotencyKey(context.Background(), keyParams); err != nil {
+					log.Error().Err(err).Str("idempotency_key", key).Msg("failed to release idempotency key")
+				}
+				panic(recovered)
+			}
+		}()
+		ctx.Next()
+
+		// Transient failures are not cached so that the client can safely retry them
+		if isRetryableStatus(ctx.Writer.Status()) {
+			err = store.DeleteIdempotencyKey(ctx, keyParams)
+		} else {
+			_, err = store.UpdateIdempotencyKeyResponse(ctx, db.UpdateIdempotencyKeyResponseParams{
+				ResponseCode: pgtype.Int4{
+					Int32: int32(ctx.Writer.Status()),
+					Valid: true,
+				},
+				ResponseBody:   recorder.body.Bytes(),
+				Username:       authPayload.Username,
+				IdempotencyKey: key,
+			})
+		}
+		if err != nil {
+			log.Error().Err(err).Str("idempotency_key", key).Msg("failed to persist idempotent response")
+		}
+	}
+}
+
+func isRetryableStatus(status int) bool {
+	return status >= http.StatusInternalServerError ||
+		status == http.StatusUnauthorized ||
+		status == http.StatusTooManyRequests
+}
+
 func rateLimitMiddleware(apiName string, limiter rds.Store, maxRequests int64, windowSize time.Duration) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
@@ -2,6 +2,8 @@ package api
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,12 +12,15 @@ import (
 	"testing"
 	"time"
 
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
 	mockss "github.com/RobertChienShiba/simplebank/redis/mock"
 	"github.com/RobertChienShiba/simplebank/token"
 	"github.com/RobertChienShiba/simplebank/util"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis"
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 	"github.com/xlzd/gotp"
 )
@@ -348,3 +353,286 @@ func TestVerifyOTPMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	username := util.RandomOwner()
+	idempotencyKey := util.RandomID()
+	path := "/middleware/test/idempotency"
+
+	body := gin.H{
+		"amount": 10,
+	}
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	hash := sha256.New()
+	hash.Write([]byte(http.MethodPost + " " + path + "\n"))
+	hash.Write(data)
+	requestHash := hex.EncodeToString(hash.Sum(nil))
+
+	storedResponse := []byte(`{"id":42}`)
+
+	testCases := []struct {
+		name          string
+		key           string
+		handlerStatus int
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:          "NoIdempotencyKey",
+			key:           "",
+			handlerStatus: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayHeader))
+			},
+		},
+		{
+			name:          "FirstRequest",
+			key:           idempotencyKey,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{
+						Username:       username,
+						IdempotencyKey: idempotencyKey,
+					})).
+					Times(1).
+					Return(db.IdempotencyKey{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+						require.Equal(t, username, arg.Username)
+						require.Equal(t, idempotencyKey, arg.IdempotencyKey)
+						require.Equal(t, path, arg.RequestPath)
+						require.Equal(t, requestHash, arg.RequestHash)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt, time.Second)
+						return db.IdempotencyKey{}, nil
+					})
+
+				store.EXPECT().
+					UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateIdempotencyKeyResponseParams) (db.IdempotencyKey, error) {
+						require.Equal(t, int32(http.StatusOK), arg.ResponseCode.Int32)
+						require.JSONEq(t, `{"status":200}`, string(arg.ResponseBody))
+						return db.IdempotencyKey{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayHeader))
+			},
+		},
+		{
+			name:          "ServerErrorNotCached",
+			key:           idempotencyKey,
+			handlerStatus: http.StatusInternalServerError,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{
+						Username:       username,
+						IdempotencyKey: idempotencyKey,
+					})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:          "Replay",
+			key:           idempotencyKey,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Username:       username,
+						IdempotencyKey: idempotencyKey,
+						RequestPath:    path,
+						RequestHash:    requestHash,
+						ResponseCode:   pgtype.Int4{Int32: http.StatusCreated, Valid: true},
+						ResponseBody:   storedResponse,
+						ExpiresAt:      time.Now().Add(time.Minute),
+					}, nil)
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayHeader))
+				require.Equal(t, storedResponse, recorder.Body.Bytes())
+			},
+		},
+		{
+			name:          "DifferentRequest",
+			key:           idempotencyKey,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						RequestHash:  util.RandomString(64),
+						ResponseCode: pgtype.Int4{Int32: http.StatusOK, Valid: true},
+						ExpiresAt:    time.Now().Add(time.Minute),
+					}, nil)
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:          "StillProcessing",
+			key:           idempotencyKey,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						RequestHash: requestHash,
+						ExpiresAt:   time.Now().Add(time.Minute),
+					}, nil)
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:          "ExpiredInProgress",
+			key:           idempotencyKey,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				// the first request died without releasing the key
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						RequestHash: requestHash,
+						ExpiresAt:   time.Now().Add(-time.Minute),
+					}, nil)
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{
+						Username:       username,
+						IdempotencyKey: idempotencyKey,
+					})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayHeader))
+			},
+		},
+		{
+			name:          "HandlerPanics",
+			key:           idempotencyKey,
+			handlerStatus: -1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{
+						Username:       username,
+						IdempotencyKey: idempotencyKey,
+					})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:          "ConcurrentRequest",
+			key:           idempotencyKey,
+			handlerStatus: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, db.ErrUniqueViolation)
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:          "KeyTooLong",
+			key:           util.RandomString(maxIdempotencyKeyLength + 1),
+			handlerStatus: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			server.router.POST(
+				path,
+				authMiddleware(server.tokenMaker),
+				idempotencyMiddleware(server.store, time.Hour),
+				func(ctx *gin.Context) {
+					if tc.handlerStatus < 0 {
+						panic("handler failed")
+					}
+					ctx.JSON(tc.handlerStatus, gin.H{"status": tc.handlerStatus})
+				},
+			)
+
+			request, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+			require.NoError(t, err)
+			if tc.key != "" {
+				request.Header.Set(idempotencyKeyHeader, tc.key)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -43,22 +43,13 @@ func (server *Server) googleOAuth(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.UpsertUserParams{
-		Username:       googleUser.Name + "-google",
-		FullName:       googleUser.Given_name + " " + googleUser.Family_name,
-		Email:          googleUser.Email,
-		HashedPassword: "",
-		Provider:       "Google",
+	user, ok := server.upsertGoogleUser(ctx, googleUser)
+	if !ok {
+		return
 	}
 
-	user, err := server.store.UpsertUser(ctx, arg)
-	if err != nil {
-		errCode := db.ErrorCode(err)
-		if errCode == db.UniqueViolation {
-			ctx.JSON(http.StatusConflict, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	if user.IsLocked {
+		ctx.JSON(http.StatusForbidden, errorResponse(ErrUserLocked))
 		return
 	}
 
@@ -87,6 +78,54 @@ func (server *Server) googleOAuth(ctx *gin.Context) {
 	ctx.Redirect(http.StatusTemporaryRedirect, fmt.Sprint(server.config.AllowedOrigins[0], pathUrl))
 }
 
+// upsertGoogleUser creates or updates the user signing in with Google. A new user and a changed full name are
+// screened against the sanctions list like a registration and a profile update. The response is written when the sign-in must stop.
+func (server *Server) upsertGoogleUser(ctx *gin.Context, googleUser *GoogleUserResult) (db.User, bool) {
+	arg := db.UpsertUserParams{
+		Username:       googleUser.Name + "-google",
+		FullName:       googleUser.Given_name + " " + googleUser.Family_name,
+		Email:          googleUser.Email,
+		HashedPassword: "",
+		Provider:       "Google",
+	}
+
+	existing, err := server.store.GetUser(ctx, arg.Username)
+	switch {
+	case errors.Is(err, db.ErrRecordNotFound):
+		if !server.screenSanctions(ctx, db.CreateSanctionsCaseParams{
+			Action:       util.SanctionsActionRegistration,
+			Username:     arg.Username,
+			ScreenedName: arg.FullName,
+		}) {
+			return db.User{}, false
+		}
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.User{}, false
+	case existing.FullName != arg.FullName:
+		if !server.screenSanctions(ctx, db.CreateSanctionsCaseParams{
+			Action:       util.SanctionsActionProfileUpdate,
+			Username:     arg.Username,
+			ScreenedName: arg.FullName,
+		}) {
+			return db.User{}, false
+		}
+	}
+
+	user, err := server.store.UpsertUser(ctx, arg)
+	if err != nil {
+		errCode := db.ErrorCode(err)
+		if errCode == db.UniqueViolation {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return db.User{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.User{}, false
+	}
+
+	return user, true
+}
+
 type GoogleOauthToken struct {
 	Access_token string
 	Id_token     string
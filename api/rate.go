@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// rateResponse renders a rate as a decimal string so clients never lose precision parsing a JSON number
+type rateResponse struct {
+	Currency    string    `json:"currency"`
+	Rate        string    `json:"rate"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+func numericString(value pgtype.Numeric) string {
+	decimal, err := value.Value()
+	if err != nil || decimal == nil {
+		return ""
+	}
+	return decimal.(string)
+}
+
+func (server *Server) listRates(ctx *gin.Context) {
+	currencies, err := server.store.ListCurrencies(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]rateResponse, 0, len(currencies))
+	for _, currency := range currencies {
+		rsp = append(rsp, rateResponse{
+			Currency:    currency.Currency,
+			Rate:        numericString(currency.Rate),
+			EffectiveAt: currency.CreatedAt.Time,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type listRateHistoryRequest struct {
+	Currency string `uri:"currency" binding:"required,alpha,len=3"`
+}
+
+type listRateHistoryQuery struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+func (server *Server) listRateHistory(ctx *gin.Context) {
+	var req listRateHistoryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var query listRateHistoryQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rates, err := server.store.ListCurrencyRateHistory(ctx, db.ListCurrencyRateHistoryParams{
+		Currency: req.Currency,
+		Limit:    query.PageSize,
+		Offset:   (query.PageID - 1) * query.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]rateResponse, 0, len(rates))
+	for _, rate := range rates {
+		rsp = append(rsp, rateResponse{
+			Currency:    rate.Currency,
+			Rate:        numericString(rate.Rate),
+			EffectiveAt: rate.EffectiveAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/util"
+)
+
+func TestListRatesAPI(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	currencies := []db.Currency{
+		{Currency: util.CAD, Rate: newRate(t, "22.675"), CreatedAt: pgtype.Timestamp{Time: now, Valid: true}},
+		{Currency: util.USD, Rate: newRate(t, "32.155"), CreatedAt: pgtype.Timestamp{Time: now, Valid: true}},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return(currencies, nil)
+
+	server := newTestServer(t, store, nil, nil)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/rates", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []rateResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, []rateResponse{
+		{Currency: util.CAD, Rate: "22.675", EffectiveAt: now},
+		{Currency: util.USD, Rate: "32.155", EffectiveAt: now},
+	}, got)
+}
+
+func TestListRateHistoryAPI(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	rates := []db.CurrencyRate{
+		{ID: 2, Currency: util.USD, Rate: newRate(t, "32.2"), EffectiveAt: now},
+		{ID: 1, Currency: util.USD, Rate: newRate(t, "32.155"), EffectiveAt: now.Add(-24 * time.Hour)},
+	}
+
+	testCases := []struct {
+		name          string
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			url:  "/api/rates/USD/history?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListCurrencyRateHistoryParams{
+					Currency: util.USD,
+					Limit:    5,
+					Offset:   0,
+				}
+				store.EXPECT().ListCurrencyRateHistory(gomock.Any(), gomock.Eq(arg)).Times(1).Return(rates, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []rateResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got, 2)
+				require.Equal(t, "32.2", got[0].Rate)
+				require.Equal(t, "32.155", got[1].Rate)
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			url:  "/api/rates/US1/history?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListCurrencyRateHistory(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidPageSize",
+			url:  "/api/rates/USD/history?page_id=1&page_size=500",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListCurrencyRateHistory(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,10 +1,38 @@
 package api
 
-func roleCheck(userRole string, accessibleRoles []string) bool {
-	for _, role := range accessibleRoles {
-		if userRole == role {
-			return true
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/RobertChienShiba/simplebank/rbac"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/gin-gonic/gin"
+)
+
+const permissionScopeKey = "permission_scope"
+
+// requirePermission answers 403 unless the role of the authenticated user has the permission.
+// Handlers of a single resource check with grantedScope whether it must belong to the user.
+func (server *Server) requirePermission(permission rbac.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		scope, ok := server.policy.Scope(authPayload.Role, permission)
+		if !ok {
+			err := fmt.Errorf("no permission to %s", permission)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
 		}
+
+		ctx.Set(permissionScopeKey, scope)
+		ctx.Next()
+	}
+}
+
+// grantedScope is the scope requirePermission granted the request with, own if the route requires no permission
+func grantedScope(ctx *gin.Context) rbac.Scope {
+	if scope, ok := ctx.Get(permissionScopeKey); ok {
+		return scope.(rbac.Scope)
 	}
-	return false
+	return rbac.ScopeOwn
 }
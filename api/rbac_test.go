@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/rbac"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequirePermission(t *testing.T) {
+	testCases := []struct {
+		name          string
+		role          string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ScopeAny",
+			role: util.BankerRole,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"scope":"any"}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "ScopeOwn",
+			role: util.DepositorRole,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"scope":"own"}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "NotGranted",
+			role: "auditor",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil, nil, nil)
+
+			policy, err := rbac.NewPolicy([]rbac.Rule{
+				{Role: util.BankerRole, Permission: rbac.ReadAudit, Scope: rbac.ScopeAny},
+				{Role: util.DepositorRole, Permission: rbac.ReadAudit, Scope: rbac.ScopeOwn},
+			})
+			require.NoError(t, err)
+			server.policy = policy
+
+			permissionPath := "/permission"
+			server.router.GET(
+				permissionPath,
+				authMiddleware(server.tokenMaker),
+				server.requirePermission(rbac.ReadAudit),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{"scope": grantedScope(ctx)})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, permissionPath, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
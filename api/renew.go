@@ -64,6 +64,11 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	if user.IsLocked {
+		ctx.JSON(http.StatusForbidden, errorResponse(ErrUserLocked))
+		return
+	}
+
 	accessToken, _, err := server.tokenMaker.CreateToken(
 		user.Username,
 		user.Role,
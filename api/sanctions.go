@@ -0,0 +1,160 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/posting"
+	"github.com/RobertChienShiba/simplebank/sanctions"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrSanctionsCaseClosed = errors.New("sanctions case was already reviewed")
+	ErrSanctionsSelfReview = errors.New("cannot review a sanctions case about yourself")
+)
+
+// screenSanctions matches the screened name of arg against the sanctions list through the posting controls.
+// The response is written when the action must stop.
+func (server *Server) screenSanctions(ctx *gin.Context, arg db.CreateSanctionsCaseParams) bool {
+	if err := server.controls.ScreenName(ctx, arg); err != nil {
+		if errors.Is(err, posting.ErrSanctionsMatch) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// screenRecipient matches the owner of the account receiving a transfer against the sanctions list.
+// The response is written when the transfer must stop.
+func (server *Server) screenRecipient(ctx *gin.Context, username string, toAccount db.Account) bool {
+	if err := server.controls.ScreenRecipient(ctx, username, toAccount); err != nil {
+		if errors.Is(err, posting.ErrSanctionsMatch) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+type listSanctionsCasesRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=open cleared confirmed"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// listSanctionsCases lists the cases opened by sanctions list matches, newest first
+func (server *Server) listSanctionsCases(ctx *gin.Context) {
+	var req listSanctionsCasesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cases, err := server.store.ListSanctionsCases(ctx, db.ListSanctionsCasesParams{
+		Status:     pgtype.Text{String: req.Status, Valid: req.Status != ""},
+		PageLimit:  req.PageSize,
+		PageOffset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cases)
+}
+
+type sanctionsCaseRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reviewSanctionsCaseRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+func (server *Server) clearSanctionsCase(ctx *gin.Context) {
+	server.reviewSanctionsCase(ctx, util.SanctionsCaseStatusCleared)
+}
+
+func (server *Server) confirmSanctionsCase(ctx *gin.Context) {
+	server.reviewSanctionsCase(ctx, util.SanctionsCaseStatusConfirmed)
+}
+
+// reviewSanctionsCase closes an open case as cleared, which lets the name pass for that entry from now on, or confirmed
+func (server *Server) reviewSanctionsCase(ctx *gin.Context, status string) {
+	var uri sanctionsCaseRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req reviewSanctionsCaseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	sanctionsCase, err := server.store.GetSanctionsCase(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if sanctionsCase.Username == authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(ErrSanctionsSelfReview))
+		return
+	}
+
+	sanctionsCase, err = server.store.ReviewSanctionsCase(ctx, db.ReviewSanctionsCaseParams{
+		Status:     status,
+		ReviewedBy: pgtype.Text{String: authPayload.Username, Valid: true},
+		Reason:     pgtype.Text{String: req.Reason, Valid: true},
+		ID:         uri.ID,
+	})
+	if err != nil {
+		// the case is there, so no row means it is no longer open
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusConflict, errorResponse(ErrSanctionsCaseClosed))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sanctionsCase)
+}
+
+// reloadSanctionsList reads the list file again right away, other processes follow the file on their next screening.
+// The loaded list stays in use when the file can't be read.
+func (server *Server) reloadSanctionsList(ctx *gin.Context) {
+	if server.controls.Sanctions == nil {
+		ctx.JSON(http.StatusConflict, errorResponse(sanctions.ErrNoList))
+		return
+	}
+
+	count, err := server.controls.Sanctions.Reload()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	log.Info().Int("entries", count).Msg("sanctions list reloaded")
+	ctx.JSON(http.StatusOK, gin.H{"entries": count})
+}
@@ -0,0 +1,919 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/posting"
+	"github.com/RobertChienShiba/simplebank/sanctions"
+	"github.com/RobertChienShiba/simplebank/util"
+)
+
+type stubSanctionsScreener struct {
+	match     sanctions.Match
+	found     bool
+	reloadErr error
+}
+
+func (screener stubSanctionsScreener) Screen(name string) (sanctions.Match, bool) {
+	return screener.match, screener.found
+}
+
+func (screener stubSanctionsScreener) Reload() (int, error) {
+	if screener.reloadErr != nil {
+		return 0, screener.reloadErr
+	}
+	return 3, nil
+}
+
+var testSanctionsMatch = sanctions.Match{
+	Entry: sanctions.Entry{ID: "36", Name: "KOVALENKO, Viktor", Program: "SDGT"},
+	Name:  "KOVALENKO, Viktor",
+	Score: 0.97,
+}
+
+func TestCreateUserSanctionsScreening(t *testing.T) {
+	user, password := randomUser(t)
+	body := gin.H{
+		"username":  user.Username,
+		"full_name": user.FullName,
+		"password":  password,
+		"email":     user.Email,
+	}
+
+	cleared := db.CountClearedSanctionsCasesParams{
+		ScreenedName: user.FullName,
+		ListEntryID:  testSanctionsMatch.Entry.ID,
+	}
+	openCase := db.GetOpenSanctionsCaseParams{
+		Username:    user.Username,
+		ListEntryID: testSanctionsMatch.Entry.ID,
+		Action:      util.SanctionsActionRegistration,
+	}
+
+	testCases := []struct {
+		name          string
+		found         bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "NoMatch",
+			found: false,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:  "Match",
+			found: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Eq(cleared)).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Eq(openCase)).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+
+				arg := db.CreateSanctionsCaseParams{
+					Action:       util.SanctionsActionRegistration,
+					Username:     user.Username,
+					ScreenedName: user.FullName,
+					ListEntryID:  testSanctionsMatch.Entry.ID,
+					MatchedName:  testSanctionsMatch.Name,
+					Program:      testSanctionsMatch.Entry.Program,
+					Score:        testSanctionsMatch.Score,
+				}
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.SanctionsCase{ID: 1}, nil)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), posting.ErrSanctionsMatch.Error())
+				require.NotContains(t, recorder.Body.String(), testSanctionsMatch.Name)
+			},
+		},
+		{
+			name:  "OpenCase",
+			found: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Eq(cleared)).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Eq(openCase)).Times(1).Return(db.SanctionsCase{ID: 1}, nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), posting.ErrSanctionsMatch.Error())
+			},
+		},
+		{
+			name:  "Cleared",
+			found: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Eq(cleared)).Times(1).Return(int64(1), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:  "CreateSanctionsCaseError",
+			found: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, sql.ErrConnDone)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			server.controls.Sanctions = stubSanctionsScreener{match: testSanctionsMatch, found: tc.found}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api/users", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateTransferSanctionsScreening(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+
+	body := transferRequest{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10000,
+		Currency:      util.USD,
+		OTP:           "777777",
+	}
+
+	expectAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+		expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		found         bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "NoMatch",
+			found: false,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user2.Username)).Times(1).Return(user2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(2).Return(newRate(t, "1"), nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "Match",
+			found: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user2.Username)).Times(1).Return(user2, nil)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					CreateSanctionsCase(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+						require.Equal(t, util.SanctionsActionTransfer, arg.Action)
+						require.Equal(t, user1.Username, arg.Username)
+						require.Equal(t, pgtype.Int8{Int64: account2.ID, Valid: true}, arg.AccountID)
+						require.Equal(t, user2.FullName, arg.ScreenedName)
+						require.Equal(t, testSanctionsMatch.Entry.ID, arg.ListEntryID)
+						return db.SanctionsCase{ID: 1}, nil
+					})
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(2).Return(newRate(t, "1"), nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "GetRecipientError",
+			found: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccounts(store)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrConnDone)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(2).Return(newRate(t, "1"), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			server.controls.Sanctions = stubSanctionsScreener{match: testSanctionsMatch, found: tc.found}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			onlyTransferURL := "/api/test/transfers"
+			server.router.POST(
+				onlyTransferURL,
+				csrfVerifyMiddleware(),
+				csrfTokenMiddleware(),
+				authMiddleware(server.tokenMaker),
+				server.createTransfer,
+			)
+			request, err := http.NewRequest(http.MethodPost, onlyTransferURL, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateUserSanctionsScreening(t *testing.T) {
+	user, _ := randomUser(t)
+	newName := util.RandomOwner()
+	newEmail := util.RandomEmail()
+
+	cleared := db.CountClearedSanctionsCasesParams{
+		ScreenedName: newName,
+		ListEntryID:  testSanctionsMatch.Entry.ID,
+	}
+
+	testCases := []struct {
+		name          string
+		body          UpdateUserRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Match",
+			body: UpdateUserRequest{Username: user.Username, FullName: &newName},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Eq(cleared)).Times(1).Return(int64(0), nil)
+
+				arg := db.CreateSanctionsCaseParams{
+					Action:       util.SanctionsActionProfileUpdate,
+					Username:     user.Username,
+					ScreenedName: newName,
+					ListEntryID:  testSanctionsMatch.Entry.ID,
+					MatchedName:  testSanctionsMatch.Name,
+					Program:      testSanctionsMatch.Entry.Program,
+					Score:        testSanctionsMatch.Score,
+				}
+				store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.SanctionsCase{ID: 1}, nil)
+				store.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), posting.ErrSanctionsMatch.Error())
+			},
+		},
+		{
+			name: "Cleared",
+			body: UpdateUserRequest{Username: user.Username, FullName: &newName},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Eq(cleared)).Times(1).Return(int64(1), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NameUnchanged",
+			body: UpdateUserRequest{Username: user.Username, Email: &newEmail},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			server.controls.Sanctions = stubSanctionsScreener{match: testSanctionsMatch, found: true}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/api/auth/users/update"
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateScheduledTransferSanctionsScreening(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+
+	body := gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          10,
+		"currency":        util.USD,
+		"frequency":       util.FrequencyMonthly,
+		"start_at":        time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+		"otp":             "777777",
+	}
+
+	testCases := []struct {
+		name          string
+		found         bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "NoMatch",
+			found: false,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransfer{ID: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "Match",
+			found: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					CreateSanctionsCase(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+						require.Equal(t, util.SanctionsActionTransfer, arg.Action)
+						require.Equal(t, user1.Username, arg.Username)
+						require.Equal(t, pgtype.Int8{Int64: account2.ID, Valid: true}, arg.AccountID)
+						require.Equal(t, user2.FullName, arg.ScreenedName)
+						return db.SanctionsCase{ID: 1}, nil
+					})
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+			expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user2.Username)).Times(1).Return(user2, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			server.controls.Sanctions = stubSanctionsScreener{match: testSanctionsMatch, found: tc.found}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			onlyScheduleURL := "/api/test/transfers/scheduled"
+			server.router.POST(
+				onlyScheduleURL,
+				csrfVerifyMiddleware(),
+				csrfTokenMiddleware(),
+				authMiddleware(server.tokenMaker),
+				server.createScheduledTransfer,
+			)
+			request, err := http.NewRequest(http.MethodPost, onlyScheduleURL, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGoogleSignInSanctionsScreening(t *testing.T) {
+	googleUser := &GoogleUserResult{
+		Email:       util.RandomEmail(),
+		Name:        "Viktor K",
+		Given_name:  "Viktor",
+		Family_name: "Kovalenko",
+	}
+	username := googleUser.Name + "-google"
+	fullName := "Viktor Kovalenko"
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, ok bool, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NewUserMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					CreateSanctionsCase(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+						require.Equal(t, util.SanctionsActionRegistration, arg.Action)
+						require.Equal(t, username, arg.Username)
+						require.Equal(t, fullName, arg.ScreenedName)
+						return db.SanctionsCase{ID: 1}, nil
+					})
+				store.EXPECT().UpsertUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, ok bool, recorder *httptest.ResponseRecorder) {
+				require.False(t, ok)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NameChangedMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(db.User{Username: username, FullName: "Viktor K"}, nil)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					CreateSanctionsCase(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+						require.Equal(t, util.SanctionsActionProfileUpdate, arg.Action)
+						require.Equal(t, fullName, arg.ScreenedName)
+						return db.SanctionsCase{ID: 1}, nil
+					})
+				store.EXPECT().UpsertUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, ok bool, recorder *httptest.ResponseRecorder) {
+				require.False(t, ok)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NameUnchanged",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(db.User{Username: username, FullName: fullName}, nil)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpsertUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{Username: username, FullName: fullName}, nil)
+			},
+			checkResponse: func(t *testing.T, ok bool, recorder *httptest.ResponseRecorder) {
+				require.True(t, ok)
+			},
+		},
+		{
+			name: "GetUserError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrConnDone)
+				store.EXPECT().UpsertUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, ok bool, recorder *httptest.ResponseRecorder) {
+				require.False(t, ok)
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			server.controls.Sanctions = stubSanctionsScreener{match: testSanctionsMatch, found: true}
+			recorder := httptest.NewRecorder()
+
+			// The Google token exchange is outside the test, the user it returned is signed in directly
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/sessions/oauth/google", nil)
+
+			_, ok := server.upsertGoogleUser(ctx, googleUser)
+			tc.checkResponse(t, ok, recorder)
+		})
+	}
+}
+
+func TestListSanctionsCasesAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+
+	cases := []db.SanctionsCase{
+		{ID: 2, Action: util.SanctionsActionTransfer, Status: util.SanctionsCaseStatusOpen, Score: 0.95},
+		{ID: 1, Action: util.SanctionsActionRegistration, Status: util.SanctionsCaseStatusOpen, Score: 0.99},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "status=open&page_id=2&page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListSanctionsCasesParams{
+					Status:     pgtype.Text{String: util.SanctionsCaseStatusOpen, Valid: true},
+					PageLimit:  5,
+					PageOffset: 5,
+				}
+				store.EXPECT().ListSanctionsCases(gomock.Any(), gomock.Eq(arg)).Times(1).Return(cases, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response []db.SanctionsCase
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, cases, response)
+			},
+		},
+		{
+			name:  "InvalidStatus",
+			query: "status=pending&page_id=1&page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListSanctionsCases(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "Depositor",
+			query: "page_id=1&page_size=5",
+			role:  util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListSanctionsCases(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_id=1&page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListSanctionsCases(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/auth/sanctions/cases?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReviewSanctionsCaseAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+
+	caseID := util.RandomInt(1, 1000)
+	reason := "date of birth differs from the listed person"
+	openCase := db.SanctionsCase{
+		ID:       caseID,
+		Action:   util.SanctionsActionRegistration,
+		Username: util.RandomOwner(),
+		Status:   util.SanctionsCaseStatusOpen,
+	}
+
+	reviewed := func(status string) db.SanctionsCase {
+		sanctionsCase := openCase
+		sanctionsCase.Status = status
+		sanctionsCase.ReviewedBy = pgtype.Text{String: banker.Username, Valid: true}
+		sanctionsCase.Reason = pgtype.Text{String: reason, Valid: true}
+		return sanctionsCase
+	}
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Clear",
+			action: "clear",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSanctionsCase(gomock.Any(), gomock.Eq(caseID)).Times(1).Return(openCase, nil)
+
+				arg := db.ReviewSanctionsCaseParams{
+					Status:     util.SanctionsCaseStatusCleared,
+					ReviewedBy: pgtype.Text{String: banker.Username, Valid: true},
+					Reason:     pgtype.Text{String: reason, Valid: true},
+					ID:         caseID,
+				}
+				store.EXPECT().ReviewSanctionsCase(gomock.Any(), gomock.Eq(arg)).Times(1).Return(reviewed(util.SanctionsCaseStatusCleared), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var sanctionsCase db.SanctionsCase
+				err := json.Unmarshal(recorder.Body.Bytes(), &sanctionsCase)
+				require.NoError(t, err)
+				require.Equal(t, util.SanctionsCaseStatusCleared, sanctionsCase.Status)
+			},
+		},
+		{
+			name:   "Confirm",
+			action: "confirm",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSanctionsCase(gomock.Any(), gomock.Eq(caseID)).Times(1).Return(openCase, nil)
+				store.EXPECT().
+					ReviewSanctionsCase(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ReviewSanctionsCaseParams) (db.SanctionsCase, error) {
+						require.Equal(t, util.SanctionsCaseStatusConfirmed, arg.Status)
+						return reviewed(arg.Status), nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "MissingReason",
+			action: "clear",
+			body:   gin.H{},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			action: "clear",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+				store.EXPECT().ReviewSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "SelfReview",
+			action: "clear",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				ownCase := openCase
+				ownCase.Username = banker.Username
+				store.EXPECT().GetSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(ownCase, nil)
+				store.EXPECT().ReviewSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "AlreadyReviewed",
+			action: "confirm",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(reviewed(util.SanctionsCaseStatusCleared), nil)
+				store.EXPECT().ReviewSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "Depositor",
+			action: "clear",
+			body:   gin.H{"reason": reason},
+			role:   util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/api/auth/sanctions/cases/%d/%s", caseID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, banker.Username, tc.role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReloadSanctionsListAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		screener      posting.SanctionsScreener
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			screener: stubSanctionsScreener{},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"entries":3}`, recorder.Body.String())
+			},
+		},
+		{
+			name:     "NotConfigured",
+			screener: nil,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "ReloadError",
+			screener: stubSanctionsScreener{reloadErr: errors.New("failed to open sanctions list")},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			server := newTestServer(t, store, nil, nil)
+			server.controls.Sanctions = tc.screener
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/api/auth/sanctions/reload", nil)
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, banker.Username, util.BankerRole, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64      `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64      `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64      `json:"amount" binding:"required,gt=0"`
+	Currency      string     `json:"currency" binding:"required,currency"`
+	Frequency     string     `json:"frequency" binding:"required,frequency"`
+	StartAt       time.Time  `json:"start_at" binding:"required"`
+	EndAt         *time.Time `json:"end_at"`
+	MaxRuns       *int32     `json:"max_runs" binding:"omitempty,min=1"`
+	OTP           string     `json:"otp" binding:"required,min=6,max=6,numeric"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.StartAt.After(time.Now()) {
+		err := errors.New("start_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.EndAt != nil && req.EndAt.Before(req.StartAt) {
+		err := errors.New("end_at must not be before start_at")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	// Scheduled transfers run as their creator, so even a transfer:create scope of any needs membership
+	if !server.authorizeMember(ctx, fromAccount, util.MemberRoleCanTransfer) {
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, req.ToAccountID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Every run screens the recipient again, so a name listed later stops the schedule
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.screenRecipient(ctx, authPayload.Username, toAccount) {
+		return
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Frequency:     req.Frequency,
+		NextRunAt:     req.StartAt,
+		AnchorAt:      req.StartAt,
+	}
+	if req.EndAt != nil {
+		arg.EndAt = pgtype.Timestamptz{Time: *req.EndAt, Valid: true}
+	}
+	if req.MaxRuns != nil {
+		arg.RemainingRuns = pgtype.Int4{Int32: *req.MaxRuns, Valid: true}
+	}
+
+	scheduledTransfer, err := server.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		if db.ErrorCode(err) == db.ForeignKeyViolation {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+type getScheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.validScheduledTransfer(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+type listScheduledTransfersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfers)
+}
+
+// updateScheduledTransferRequest accepts an amount only to refuse it with 400. Raising it would skip the OTP and
+// idempotency key that scheduling a transfer requires, so a different amount takes cancelling the schedule and creating a new one.
+type updateScheduledTransferRequest struct {
+	// Amount is never applied, it is bound so that a request changing it fails instead of being silently ignored
+	Amount    *int64     `json:"amount"`
+	NextRunAt *time.Time `json:"next_run_at"`
+	EndAt     *time.Time `json:"end_at"`
+	MaxRuns   *int32     `json:"max_runs" binding:"omitempty,min=1"`
+	Status    *string    `json:"status" binding:"omitempty,oneof=active paused"`
+}
+
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Amount != nil {
+		err := errors.New("amount can't be changed, cancel the scheduled transfer and schedule a new one")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.NextRunAt != nil && !req.NextRunAt.After(time.Now()) {
+		err := errors.New("next_run_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.validScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	auditBefore(ctx, scheduledTransfer.ID, scheduledTransfer)
+
+	if !isScheduleOpen(scheduledTransfer.Status) {
+		err := errors.New("scheduled transfer is no longer active")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	// The end is checked against the next run the update leaves, whichever of the two it changes
+	nextRunAt := scheduledTransfer.NextRunAt
+	if req.NextRunAt != nil {
+		nextRunAt = *req.NextRunAt
+	}
+	endAt := scheduledTransfer.EndAt
+	if req.EndAt != nil {
+		endAt = pgtype.Timestamptz{Time: *req.EndAt, Valid: true}
+	}
+	if endAt.Valid && endAt.Time.Before(nextRunAt) {
+		err := errors.New("end_at must not be before next_run_at")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.UpdateScheduledTransferParams{
+		ID: scheduledTransfer.ID,
+	}
+	if req.NextRunAt != nil {
+		arg.NextRunAt = pgtype.Timestamptz{Time: *req.NextRunAt, Valid: true}
+	}
+	if req.EndAt != nil {
+		arg.EndAt = pgtype.Timestamptz{Time: *req.EndAt, Valid: true}
+	}
+	if req.MaxRuns != nil {
+		arg.RemainingRuns = pgtype.Int4{Int32: *req.MaxRuns, Valid: true}
+	}
+	if req.Status != nil {
+		arg.Status = pgtype.Text{String: *req.Status, Valid: true}
+	}
+
+	scheduledTransfer, err := server.store.UpdateScheduledTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.validScheduledTransfer(ctx, req.ID)
+	if !valid {
+		return
+	}
+	auditBefore(ctx, scheduledTransfer.ID, scheduledTransfer)
+
+	if !isScheduleOpen(scheduledTransfer.Status) {
+		err := errors.New("scheduled transfer is no longer active")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	// Cancelling keeps the row so the history of past runs stays available
+	scheduledTransfer, err := server.store.UpdateScheduledTransfer(ctx, db.UpdateScheduledTransferParams{
+		ID:     scheduledTransfer.ID,
+		Status: pgtype.Text{String: util.ScheduleStatusCancelled, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+type listScheduledTransferRunsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listScheduledTransferRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.validScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               req.PageSize,
+		Offset:              (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+func (server *Server) validScheduledTransfer(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduledTransfer, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if scheduledTransfer.Owner != authPayload.Username {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	return scheduledTransfer, true
+}
+
+func isScheduleOpen(status string) bool {
+	return status == util.ScheduleStatusActive || status == util.ScheduleStatusPaused
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/posting"
+	"github.com/RobertChienShiba/simplebank/rbac"
 	rds "github.com/RobertChienShiba/simplebank/redis"
 	"github.com/RobertChienShiba/simplebank/token"
 	"github.com/RobertChienShiba/simplebank/util"
@@ -22,20 +25,39 @@ type Server struct {
 	store           db.Store
 	kvStore         rds.Store
 	taskDistributor worker.TaskDistributor
-	router          *gin.Engine
+	roundingMode    money.RoundingMode
+	// controls screen every transfer and decide whether it needs approval
+	controls     *posting.Controls
+	auditLog     auditLogger
+	tokenRevoker tokenRevoker
+	policy       *rbac.Policy
+	router       *gin.Engine
 }
 
-func NewServer(config util.Config, store db.Store, kvStore rds.Store, taskDistributor worker.TaskDistributor) (*Server, error) {
+func NewServer(config util.Config, store db.Store, kvStore rds.Store, taskDistributor worker.TaskDistributor, policy *rbac.Policy) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker %w", err)
 	}
+	roundingMode, err := money.ParseRoundingMode(config.RoundingMode)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse rounding mode %w", err)
+	}
+	controls, err := posting.NewControls(config, store)
+	if err != nil {
+		return nil, err
+	}
 	server := &Server{
 		config:          config,
 		store:           store,
 		kvStore:         kvStore,
 		taskDistributor: taskDistributor,
 		tokenMaker:      tokenMaker,
+		roundingMode:    roundingMode,
+		auditLog:        store,
+		tokenRevoker:    newRedisTokenRevoker(kvStore, config.AccessTokenDuration),
+		policy:          policy,
+		controls:        controls,
 	}
 	router := gin.Default()
 
@@ -56,6 +78,7 @@ func NewServer(config util.Config, store db.Store, kvStore rds.Store, taskDistri
 			"Content-Type",
 			"Authorization",
 			"X-CSRF-Token",
+			idempotencyKeyHeader,
 		},
 		AllowCredentials: true,
 		ExposeHeaders: []string{
@@ -64,12 +87,17 @@ func NewServer(config util.Config, store db.Store, kvStore rds.Store, taskDistri
 			"X-RateLimit-Reset",
 			"X-RateLimit-Retry-After",
 			"X-CSRF-Token",
+			idempotentReplayHeader,
 		},
 		MaxAge: 12 * time.Hour,
 	}))
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("frequency", validFrequency)
+		v.RegisterValidation("event_type", validEventType)
+		v.RegisterValidation("member_role", validMemberRole)
+		v.RegisterValidation("webhook_url", validWebhookURL)
 	}
 
 	apiRoutes := router.Group("/api")
@@ -77,26 +105,78 @@ func NewServer(config util.Config, store db.Store, kvStore rds.Store, taskDistri
 	apiRoutes.POST("/users/login", server.loginUser)
 	apiRoutes.GET("/tokens/renew_access", server.renewAccessToken)
 	apiRoutes.GET("/users/logout", server.logoutUser)
+	apiRoutes.GET("/rates", server.listRates)
+	apiRoutes.GET("/rates/:currency/history", server.listRateHistory)
 
 	authRoutes := apiRoutes.Group("/auth").Use(
 		csrfVerifyMiddleware(),
 		csrfTokenMiddleware(),
 		authMiddleware(tokenMaker),
+		server.tokenRevocationMiddleware(),
+		server.auditMiddleware(),
 	)
 
 	authRoutes.GET("/users/update", func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{"message": "fetch csrf token successfully"})
 	})
-	authRoutes.PATCH("/users/update", server.updateUser)
+	authRoutes.PATCH("/users/update", server.requirePermission(rbac.UpdateUser), server.updateUser)
 
 	authRoutes.GET("/users/me", server.getUser)
+	authRoutes.GET("/users", server.requirePermission(rbac.ManageUsers), server.listUsers)
+	authRoutes.PUT("/users/:username/role", server.requirePermission(rbac.ManageUsers), server.updateUserRole)
+	authRoutes.POST("/users/:username/lock", server.requirePermission(rbac.ManageUsers), server.lockUser)
+	authRoutes.POST("/users/:username/unlock", server.requirePermission(rbac.ManageUsers), server.unlockUser)
 
 	authRoutes.GET("/accounts", func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{"message": "fetch csrf token successfully"})
 	})
-	authRoutes.POST("/accounts", server.createAccount)
+	authRoutes.POST("/accounts", idempotencyMiddleware(store, config.IdempotencyKeyTTL), server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccount)
 	authRoutes.GET("/accounts/all", server.listAccounts)
+	authRoutes.GET("/accounts/invitations", server.listAccountInvitations)
+	authRoutes.GET("/accounts/:id/entries", server.listAccountEntries)
+	authRoutes.GET("/accounts/:id/statement", server.getAccountStatement)
+	authRoutes.POST("/accounts/:id/deposit", server.requirePermission(rbac.PostCash), server.depositCash)
+	authRoutes.POST("/accounts/:id/withdraw", server.requirePermission(rbac.PostCash), server.withdrawCash)
+	authRoutes.POST("/accounts/:id/freeze", server.requirePermission(rbac.FreezeAccount), server.freezeAccount)
+	authRoutes.POST("/accounts/:id/unfreeze", server.requirePermission(rbac.FreezeAccount), server.unfreezeAccount)
+	authRoutes.POST("/accounts/:id/close", server.closeAccount)
+	authRoutes.GET("/accounts/:id/members", server.listAccountMembers)
+	authRoutes.POST("/accounts/:id/members", server.inviteAccountMember)
+	authRoutes.POST("/accounts/:id/members/accept", server.acceptAccountInvitation)
+	authRoutes.PATCH("/accounts/:id/members/:username", server.updateAccountMember)
+	authRoutes.DELETE("/accounts/:id/members/:username", server.removeAccountMember)
+	authRoutes.GET("/accounts/:id/holds", server.listHolds)
+	authRoutes.POST("/accounts/:id/holds",
+		server.requirePermission(rbac.CreateTransfer),
+		rateLimitMiddleware("verifyOTP", kvStore, config.APILimitBound, config.APILimitDuration),
+		idempotencyMiddleware(store, config.IdempotencyKeyTTL),
+		verifyOTPMiddleware(kvStore, config.APILimitDuration),
+		server.createHold,
+	)
+	authRoutes.POST("/holds/:id/capture", server.requirePermission(rbac.SettleHold), server.captureHold)
+	authRoutes.POST("/holds/:id/release", server.requirePermission(rbac.SettleHold), server.releaseHold)
+
+	authRoutes.GET("/limits", server.getMyTransferLimits)
+	authRoutes.GET("/limits/users/:username", server.requirePermission(rbac.ManageLimits), server.getUserTransferLimits)
+	authRoutes.PUT("/limits/users/:username", server.requirePermission(rbac.ManageLimits), server.setUserTransferLimit)
+	authRoutes.PUT("/limits/roles/:role", server.requirePermission(rbac.ManageLimits), server.setRoleTransferLimit)
+
+	authRoutes.GET("/audit", server.requirePermission(rbac.ReadAudit), server.listAuditLogs)
+	authRoutes.GET("/fraud/decisions", server.requirePermission(rbac.ReviewFraud), server.listFraudDecisions)
+	authRoutes.GET("/sanctions/cases", server.requirePermission(rbac.ReviewSanctions), server.listSanctionsCases)
+	authRoutes.POST("/sanctions/cases/:id/clear", server.requirePermission(rbac.ReviewSanctions), server.clearSanctionsCase)
+	authRoutes.POST("/sanctions/cases/:id/confirm", server.requirePermission(rbac.ReviewSanctions), server.confirmSanctionsCase)
+	authRoutes.POST("/sanctions/reload", server.requirePermission(rbac.ReviewSanctions), server.reloadSanctionsList)
+
+	authRoutes.GET("/webhooks", server.listWebhooks)
+	authRoutes.POST("/webhooks", server.createWebhook)
+	authRoutes.DELETE("/webhooks/:id", server.deleteWebhook)
+	authRoutes.POST("/webhooks/:id/enable", server.enableWebhook)
+	authRoutes.GET("/webhooks/:id/deliveries", server.listWebhookDeliveries)
+	authRoutes.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", server.redeliverWebhook)
+
+	authRoutes.POST("/fx/quotes", server.createFXQuote)
 
 	authRoutes.GET("/transfers/sendOTP",
 		rateLimitMiddleware("sendOTP", kvStore, config.APILimitBound, config.APILimitDuration),
@@ -107,10 +187,30 @@ func NewServer(config util.Config, store db.Store, kvStore rds.Store, taskDistri
 		ctx.JSON(http.StatusOK, gin.H{"message": "fetch csrf token successfully"})
 	})
 	authRoutes.POST("/transfers",
+		server.requirePermission(rbac.CreateTransfer),
 		rateLimitMiddleware("verifyOTP", kvStore, config.APILimitBound, config.APILimitDuration),
+		idempotencyMiddleware(store, config.IdempotencyKeyTTL),
 		verifyOTPMiddleware(kvStore, config.APILimitDuration),
 		server.createTransfer,
 	)
+	authRoutes.POST("/transfers/:id/reverse", server.requirePermission(rbac.ReverseTransfer), server.reverseTransfer)
+
+	authRoutes.GET("/transfers/approvals", server.requirePermission(rbac.ApproveTransfer), server.listTransferApprovals)
+	authRoutes.POST("/transfers/approvals/:id/approve", server.requirePermission(rbac.ApproveTransfer), server.approveTransfer)
+	authRoutes.POST("/transfers/approvals/:id/reject", server.requirePermission(rbac.ApproveTransfer), server.rejectTransfer)
+
+	authRoutes.GET("/transfers/scheduled", server.listScheduledTransfers)
+	authRoutes.POST("/transfers/scheduled",
+		server.requirePermission(rbac.CreateTransfer),
+		rateLimitMiddleware("verifyOTP", kvStore, config.APILimitBound, config.APILimitDuration),
+		idempotencyMiddleware(store, config.IdempotencyKeyTTL),
+		verifyOTPMiddleware(kvStore, config.APILimitDuration),
+		server.createScheduledTransfer,
+	)
+	authRoutes.GET("/transfers/scheduled/:id", server.getScheduledTransfer)
+	authRoutes.PATCH("/transfers/scheduled/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/transfers/scheduled/:id", server.cancelScheduledTransfer)
+	authRoutes.GET("/transfers/scheduled/:id/runs", server.listScheduledTransferRuns)
 
 	apiRoutes.GET("/stress_test",
 		func(ctx *gin.Context) {
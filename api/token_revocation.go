@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	rds "github.com/RobertChienShiba/simplebank/redis"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrTokenRevoked is returned for an access token issued before the tokens of its user were revoked
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// tokenRevoker invalidates the access tokens already issued to a user,
+// e.g. after their role changed or they were locked
+type tokenRevoker interface {
+	RevokeTokens(username string) error
+	IsRevoked(payload *token.Payload) (bool, error)
+}
+
+func revokedTokensKey(username string) string {
+	return "revoked:" + username
+}
+
+// redisTokenRevoker keeps the time of the last revocation of a user in Redis.
+// The key outlives every access token issued before it, so it can expire with them.
+type redisTokenRevoker struct {
+	kvStore  rds.Store
+	duration time.Duration
+}
+
+func newRedisTokenRevoker(kvStore rds.Store, accessTokenDuration time.Duration) tokenRevoker {
+	return redisTokenRevoker{
+		kvStore:  kvStore,
+		duration: accessTokenDuration,
+	}
+}
+
+func (revoker redisTokenRevoker) RevokeTokens(username string) error {
+	return revoker.kvStore.Set(revokedTokensKey(username), time.Now().UnixNano(), revoker.duration)
+}
+
+func (revoker redisTokenRevoker) IsRevoked(payload *token.Payload) (bool, error) {
+	value, err := revoker.kvStore.Get(revokedTokensKey(payload.Username))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	revokedAt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false, err
+	}
+
+	return !payload.IssuedAt.After(time.Unix(0, revokedAt)), nil
+}
+
+// tokenRevocationMiddleware rejects access tokens revoked after they were issued, it must run after authMiddleware
+func (server *Server) tokenRevocationMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		revoked, err := server.tokenRevoker.IsRevoked(authPayload)
+		if err != nil {
+			err := errors.New("failed to check token revocation")
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if revoked {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrTokenRevoked))
+			return
+		}
+
+		ctx.Next()
+	}
+}
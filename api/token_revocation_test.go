@@ -0,0 +1,126 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	mocksession "github.com/RobertChienShiba/simplebank/redis/mock"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRevocationMiddleware(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(session *mocksession.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NeverRevoked",
+			buildStubs: func(session *mocksession.MockStore) {
+				session.EXPECT().Get(gomock.Eq(revokedTokensKey(username))).Times(1).Return("", redis.Nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RevokedAfterIssued",
+			buildStubs: func(session *mocksession.MockStore) {
+				revokedAt := time.Now().Add(time.Second).UnixNano()
+				session.EXPECT().Get(gomock.Eq(revokedTokensKey(username))).Times(1).Return(strconv.FormatInt(revokedAt, 10), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "IssuedAfterRevocation",
+			buildStubs: func(session *mocksession.MockStore) {
+				revokedAt := time.Now().Add(-time.Second).UnixNano()
+				session.EXPECT().Get(gomock.Eq(revokedTokensKey(username))).Times(1).Return(strconv.FormatInt(revokedAt, 10), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "SessionStoreError",
+			buildStubs: func(session *mocksession.MockStore) {
+				session.EXPECT().Get(gomock.Any()).Times(1).Return("", errors.New("redis is down"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			session := mocksession.NewMockStore(ctrl)
+			tc.buildStubs(session)
+
+			server := newTestServer(t, nil, session, nil)
+			server.tokenRevoker = newRedisTokenRevoker(session, server.config.AccessTokenDuration)
+
+			revocationPath := "/revocation"
+			server.router.GET(
+				revocationPath,
+				authMiddleware(server.tokenMaker),
+				server.tokenRevocationMiddleware(),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, revocationPath, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRedisTokenRevoker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := mocksession.NewMockStore(ctrl)
+	revoker := newRedisTokenRevoker(session, time.Minute)
+
+	payload, err := token.NewPayload(util.RandomOwner(), util.DepositorRole, time.Minute)
+	require.NoError(t, err)
+
+	var revokedAt string
+	session.EXPECT().
+		Set(gomock.Eq(revokedTokensKey(payload.Username)), gomock.Any(), gomock.Eq(time.Minute)).
+		Times(1).
+		DoAndReturn(func(key string, value interface{}, expiration time.Duration) error {
+			revokedAt = strconv.FormatInt(value.(int64), 10)
+			return nil
+		})
+	require.NoError(t, revoker.RevokeTokens(payload.Username))
+
+	session.EXPECT().Get(gomock.Eq(revokedTokensKey(payload.Username))).Times(1).Return(revokedAt, nil)
+	revoked, err := revoker.IsRevoked(payload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
@@ -3,19 +3,34 @@ package api
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 
 	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/posting"
+	"github.com/RobertChienShiba/simplebank/rbac"
 	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/util"
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// AmountTypeSource means the amount is debited from the sender, in the currency of the source account
+	AmountTypeSource = "source"
+	// AmountTypeDestination means the amount is credited to the recipient, in the currency of the destination account
+	AmountTypeDestination = "destination"
+)
+
 type transferRequest struct {
 	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
 	Amount        int64  `json:"amount" binding:"required,gt=0"`
 	Currency      string `json:"currency" binding:"required,currency"`
 	OTP           string `json:"otp" binding:"required,min=6,max=6,numeric"`
+	AmountType    string `json:"amount_type" binding:"omitempty,oneof=source destination"`
+	// QuoteID transfers at the rate locked by POST /fx/quotes instead of the current one
+	QuoteID string `json:"quote_id" binding:"omitempty,uuid"`
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
@@ -25,67 +40,175 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	// the currency of the request is the one of the account the amount is given for
+	amountType := req.AmountType
+	if amountType == "" {
+		amountType = AmountTypeSource
+	}
+
+	var fromAccount db.Account
+	var valid bool
+	switch amountType {
+	case AmountTypeSource:
+		fromAccount, valid = server.validAccount(ctx, req.FromAccountID, req.Currency)
+	case AmountTypeDestination:
+		fromAccount, valid = server.existingAccount(ctx, req.FromAccountID)
+	}
 	if !valid {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if grantedScope(ctx) != rbac.ScopeAny && !server.authorizeMember(ctx, fromAccount, util.MemberRoleCanTransfer) {
 		return
 	}
 
-	// _, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
-	// if !valid {
-	// 	return
-	// }
+	var Toaccount db.Account
+	switch amountType {
+	case AmountTypeSource:
+		Toaccount, valid = server.existingAccount(ctx, req.ToAccountID)
+	case AmountTypeDestination:
+		Toaccount, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	}
+	if !valid {
+		return
+	}
 
-	Toaccount, err := server.store.GetAccount(ctx, req.ToAccountID)
-	if err != nil {
-		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	if fromAccount.Owner == util.CashAccountOwner || Toaccount.Owner == util.CashAccountOwner {
+		ctx.JSON(http.StatusBadRequest, errorResponse(db.ErrCashAccountTransfer))
 		return
 	}
 
-	afterExchange, err := server.currencyExchange(ctx, fromAccount.Currency, Toaccount.Currency, req.Amount)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var conversion money.Conversion
+	var quote *claimedQuote
+	var err error
+	switch amountType {
+	case AmountTypeSource:
+		if req.QuoteID != "" {
+			conversion, quote, err = server.quotedExchange(ctx, req.QuoteID, money.New(req.Amount, fromAccount.Currency), Toaccount.Currency)
+		} else {
+			conversion, err = server.currencyExchange(ctx, money.New(req.Amount, fromAccount.Currency), Toaccount.Currency)
+		}
+	case AmountTypeDestination:
+		if req.QuoteID != "" {
+			err := errors.New("quotes lock source amounts and can't be used with a destination amount")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		conversion, err = server.destinationExchange(ctx, money.New(req.Amount, Toaccount.Currency), fromAccount.Currency)
+	}
 	if err != nil {
 		return
 	}
 
+	// the quote goes back to Redis unless a transfer or an approval request used it
+	used := false
+	defer func() {
+		if !used {
+			server.releaseQuote(quote)
+		}
+	}()
+
 	arg := db.TransferTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
-		FromAmount:    req.Amount,
-		ToAmount:      afterExchange,
+		FromAmount:    conversion.Source.Amount,
+		ToAmount:      conversion.Target.Amount,
+		ExchangeRate:  money.NumericFromRat(conversion.Rate),
+		Username:      authPayload.Username,
+	}
+	if conversion.Remainder.Sign() != 0 {
+		arg.RoundingRemainder = money.NumericFromRat(conversion.Remainder)
+	}
+
+	approval, ok := server.checkTransfer(ctx, posting.Transfer{
+		Username:    authPayload.Username,
+		FromAccount: fromAccount,
+		ToAccount:   Toaccount,
+		Params:      arg,
+	})
+	if !ok {
+		return
+	}
+	if approval != nil {
+		used = true
+		ctx.JSON(http.StatusAccepted, approval)
+		return
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		if errors.Is(err, db.ErrInsufficientBalance) {
+		switch {
+		case errors.Is(err, db.ErrInsufficientBalance), errors.Is(err, db.ErrCashAccountTransfer):
 			ctx.JSON(http.StatusBadRequest, errorResponse(err))
-			return
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrTransferLimitExceeded):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, result)
+	used = true
+	ctx.JSON(http.StatusOK, newTransferResponse(result, conversion))
 }
 
-func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
-	account, err := server.store.GetAccount(ctx, accountID)
+// transferResponse extends the transfer result with the amounts in both currencies,
+// so clients never have to guess the minor-unit exponent of an account
+type transferResponse struct {
+	db.TransferTxResult
+	SentAmount     money.Money `json:"sent_amount"`
+	ReceivedAmount money.Money `json:"received_amount"`
+	ExchangeRate   string      `json:"exchange_rate"`
+}
+
+func newTransferResponse(result db.TransferTxResult, conversion money.Conversion) transferResponse {
+	return transferResponse{
+		TransferTxResult: result,
+		SentAmount:       conversion.Source,
+		ReceivedAmount:   conversion.Target,
+		ExchangeRate:     conversion.Rate.FloatString(money.RateScale),
+	}
+}
+
+type reverseTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: req.ID,
+	})
 	if err != nil {
-		if errors.Is(err, db.ErrRecordNotFound) {
+		switch {
+		case errors.Is(err, db.ErrRecordNotFound):
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return account, false
+		case errors.Is(err, db.ErrTransferNotReversible), errors.Is(err, db.ErrTransferEntriesNotFound),
+			errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrInsufficientBalance):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		}
+		return
+	}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+	account, valid := server.existingAccount(ctx, accountID)
+	if !valid {
 		return account, false
 	}
 
@@ -99,29 +222,82 @@ func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency s
 
 }
 
-func (server *Server) currencyExchange(ctx *gin.Context, fromCurrency string, toCurrency string, amount int64) (int64, error) {
-	fromExchangeRate, errFrom := server.store.GetExchangeRate(ctx, fromCurrency)
-	if errFrom != nil {
-		if errors.Is(errFrom, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, errorResponse(errFrom))
-			return int64(-1), errFrom
+// existingAccount gets an account whose currency doesn't have to match the request
+func (server *Server) existingAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(errFrom))
-		return int64(-1), errFrom
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+// currencyExchange converts an amount into the destination currency using the exact rates of the currencies table
+func (server *Server) currencyExchange(ctx *gin.Context, amount money.Money, toCurrency string) (money.Conversion, error) {
+	fromExchangeRate, err := server.exchangeRate(ctx, amount.Currency)
+	if err != nil {
+		return money.Conversion{}, err
+	}
+
+	toExchangeRate, err := server.exchangeRate(ctx, toCurrency)
+	if err != nil {
+		return money.Conversion{}, err
+	}
+
+	conversion, err := money.Convert(amount, toCurrency, fromExchangeRate, toExchangeRate, server.roundingMode)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return money.Conversion{}, err
 	}
 
-	toExchangeRate, errTo := server.store.GetExchangeRate(ctx, toCurrency)
-	if errTo != nil {
-		if errors.Is(errTo, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, errorResponse(errTo))
-			return int64(-1), errTo
+	return conversion, nil
+}
+
+// destinationExchange works out the amount to debit for the recipient to get exactly the given amount,
+// the debit is always rounded up in favor of the bank
+func (server *Server) destinationExchange(ctx *gin.Context, amount money.Money, fromCurrency string) (money.Conversion, error) {
+	fromExchangeRate, err := server.exchangeRate(ctx, fromCurrency)
+	if err != nil {
+		return money.Conversion{}, err
+	}
+
+	toExchangeRate, err := server.exchangeRate(ctx, amount.Currency)
+	if err != nil {
+		return money.Conversion{}, err
+	}
+
+	conversion, err := money.ConvertToTarget(amount, fromCurrency, fromExchangeRate, toExchangeRate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return money.Conversion{}, err
+	}
+
+	return conversion, nil
+}
+
+func (server *Server) exchangeRate(ctx *gin.Context, currency string) (*big.Rat, error) {
+	rate, err := server.store.GetExchangeRate(ctx, currency)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return nil, err
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(errFrom))
-		return int64(-1), errTo
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, err
+	}
+
+	exchangeRate, err := money.RatFromNumeric(rate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, err
 	}
 
-	exchangeRate := fromExchangeRate / toExchangeRate
-	return int64(float64(amount) * exchangeRate), nil
+	return exchangeRate, nil
 }
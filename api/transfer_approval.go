@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/posting"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/RobertChienShiba/simplebank/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// checkTransfer runs the posting controls on a transfer and returns the approval it waits for, if any.
+// The response is written when the transfer must stop.
+func (server *Server) checkTransfer(ctx *gin.Context, transfer posting.Transfer) (*db.TransferApproval, bool) {
+	approval, err := server.controls.Check(ctx, transfer)
+	if err != nil {
+		abortTransfer(ctx, err)
+		return nil, false
+	}
+
+	return approval, true
+}
+
+// screenTransfer runs the fraud rules on a transfer that isn't posted yet, like the one a hold reserves funds for.
+// The response is written when the transfer is blocked.
+func (server *Server) screenTransfer(ctx *gin.Context, transfer posting.Transfer) bool {
+	if _, err := server.controls.Screen(ctx, transfer); err != nil {
+		abortTransfer(ctx, err)
+		return false
+	}
+
+	return true
+}
+
+// abortTransfer answers for a transfer the posting controls stopped, neither the list entry nor the fraud rule is disclosed
+func abortTransfer(ctx *gin.Context, err error) {
+	if errors.Is(err, posting.ErrSanctionsMatch) || errors.Is(err, posting.ErrTransferBlocked) {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
+
+type listTransferApprovalsRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending approved rejected expired"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// listTransferApprovals is the approval queue, oldest first
+func (server *Server) listTransferApprovals(ctx *gin.Context) {
+	var req listTransferApprovalsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	approvals, err := server.store.ListTransferApprovals(ctx, db.ListTransferApprovalsParams{
+		Status:     pgtype.Text{String: req.Status, Valid: req.Status != ""},
+		PageLimit:  req.PageSize,
+		PageOffset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, approvals)
+}
+
+type transferApprovalRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reviewTransferApprovalRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+func (server *Server) approveTransfer(ctx *gin.Context) {
+	server.reviewTransferApproval(ctx, true)
+}
+
+func (server *Server) rejectTransfer(ctx *gin.Context) {
+	server.reviewTransferApproval(ctx, false)
+}
+
+// reviewTransferApproval approves or rejects a pending transfer, only a user other than the requester may review it
+func (server *Server) reviewTransferApproval(ctx *gin.Context, approve bool) {
+	var uri transferApprovalRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req reviewTransferApprovalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.ReviewTransferApprovalTx(ctx, db.ReviewTransferApprovalTxParams{
+		ID:       uri.ID,
+		Reviewer: authPayload.Username,
+		Approve:  approve,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrSelfApproval):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+		case errors.Is(err, db.ErrApprovalNotPending), errors.Is(err, db.ErrAccountNotActive), errors.Is(err, db.ErrHoldNotActive):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrInsufficientBalance):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		case errors.Is(err, db.ErrTransferLimitExceeded):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	// The review is already committed, a lost notice must not turn it into an error
+	err = server.taskDistributor.DistributeTaskSendTransferApprovalNotice(ctx,
+		&worker.PayloadSendTransferApprovalNotice{ApprovalID: result.Approval.ID},
+		asynq.MaxRetry(3), asynq.Queue(worker.QueueDefault))
+	if err != nil {
+		log.Error().Err(err).Int64("approval_id", result.Approval.ID).Msg("failed to enqueue transfer approval notice")
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,539 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/RobertChienShiba/simplebank/worker"
+	mockwk "github.com/RobertChienShiba/simplebank/worker/mock"
+)
+
+func TestCreateTransferApprovalThreshold(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+
+	eurAccount1 := account1
+	eurAccount1.Currency = util.EUR
+	eurAccount2 := account2
+	eurAccount2.Currency = util.EUR
+
+	threshold := money.New(50000, util.USD)
+
+	newBody := func(amount int64, currency string) transferRequest {
+		return transferRequest{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Currency:      currency,
+			OTP:           "777777",
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          transferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "AboveThreshold",
+			body: newBody(50001, util.USD),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(2).Return(newRate(t, "1"), nil)
+
+				store.EXPECT().
+					CreateTransferApproval(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTransferApprovalParams) (db.TransferApproval, error) {
+						require.Equal(t, account1.ID, arg.FromAccountID)
+						require.Equal(t, account2.ID, arg.ToAccountID)
+						require.Equal(t, int64(50001), arg.Amount)
+						require.Equal(t, int64(50001), arg.ToAmount)
+						require.Equal(t, user1.Username, arg.RequestedBy)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt, time.Second)
+
+						return db.TransferApproval{
+							ID:            1,
+							FromAccountID: arg.FromAccountID,
+							ToAccountID:   arg.ToAccountID,
+							Amount:        arg.Amount,
+							ToAmount:      arg.ToAmount,
+							Status:        util.ApprovalStatusPending,
+							RequestedBy:   arg.RequestedBy,
+							ExpiresAt:     arg.ExpiresAt,
+						}, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var approval db.TransferApproval
+				err := json.Unmarshal(recorder.Body.Bytes(), &approval)
+				require.NoError(t, err)
+				require.Equal(t, util.ApprovalStatusPending, approval.Status)
+				require.Equal(t, int64(50001), approval.Amount)
+			},
+		},
+		{
+			name: "AtThreshold",
+			body: newBody(50000, util.USD),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(2).Return(newRate(t, "1"), nil)
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ConvertedAboveThreshold",
+			// 46000 EUR cents at 35 and 32 TWD per EUR and USD are 50312 USD cents
+			body: newBody(46000, util.EUR),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(eurAccount1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(eurAccount2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.EUR)).Times(3).Return(newRate(t, "35"), nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(newRate(t, "32"), nil)
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferApproval{}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "ConvertedBelowThreshold",
+			// 45000 EUR cents are 49218 USD cents
+			body: newBody(45000, util.EUR),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(eurAccount1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(eurAccount2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.EUR)).Times(3).Return(newRate(t, "35"), nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(newRate(t, "32"), nil)
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "CreateTransferApprovalError",
+			body: newBody(50001, util.USD),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(2).Return(newRate(t, "1"), nil)
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferApproval{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			server.controls.ApprovalThreshold = &threshold
+			server.controls.ApprovalTTL = time.Hour
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			onlyTransferURL := "/api/test/transfers"
+			server.router.POST(
+				onlyTransferURL,
+				csrfVerifyMiddleware(),
+				csrfTokenMiddleware(),
+				authMiddleware(server.tokenMaker),
+				server.createTransfer,
+			)
+			request, err := http.NewRequest(http.MethodPost, onlyTransferURL, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListTransferApprovalsAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	approvals := []db.TransferApproval{
+		{ID: 1, Amount: 60000, Status: util.ApprovalStatusPending, RequestedBy: util.RandomOwner()},
+		{ID: 2, Amount: 70000, Status: util.ApprovalStatusPending, RequestedBy: util.RandomOwner()},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "status=pending&page_id=2&page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListTransferApprovalsParams{
+					Status:     pgtype.Text{String: util.ApprovalStatusPending, Valid: true},
+					PageLimit:  5,
+					PageOffset: 5,
+				}
+				store.EXPECT().ListTransferApprovals(gomock.Any(), gomock.Eq(arg)).Times(1).Return(approvals, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response []db.TransferApproval
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response, 2)
+				require.Equal(t, approvals[0].ID, response[0].ID)
+			},
+		},
+		{
+			name:  "InvalidStatus",
+			query: "status=done&page_id=1&page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferApprovals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "Depositor",
+			query: "page_id=1&page_size=5",
+			role:  util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferApprovals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_id=1&page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferApprovals(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/auth/transfers/approvals?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, banker.Username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReviewTransferApprovalAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	approvalID := util.RandomInt(1, 1000)
+	reason := "verified with the customer by phone"
+
+	approved := db.TransferApproval{
+		ID:         approvalID,
+		Amount:     60000,
+		Status:     util.ApprovalStatusApproved,
+		ReviewedBy: pgtype.Text{String: banker.Username, Valid: true},
+		Reason:     pgtype.Text{String: reason, Valid: true},
+	}
+	rejected := approved
+	rejected.Status = util.ApprovalStatusRejected
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Approve",
+			action: "approve",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				arg := db.ReviewTransferApprovalTxParams{
+					ID:       approvalID,
+					Reviewer: banker.Username,
+					Approve:  true,
+					Reason:   reason,
+				}
+				store.EXPECT().
+					ReviewTransferApprovalTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ReviewTransferApprovalTxResult{Approval: approved, Transfer: &db.TransferTxResult{}}, nil)
+				distributor.EXPECT().
+					DistributeTaskSendTransferApprovalNotice(gomock.Any(), gomock.Eq(&worker.PayloadSendTransferApprovalNotice{ApprovalID: approvalID}), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response db.ReviewTransferApprovalTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, util.ApprovalStatusApproved, response.Approval.Status)
+				require.NotNil(t, response.Transfer)
+			},
+		},
+		{
+			name:   "Reject",
+			action: "reject",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				arg := db.ReviewTransferApprovalTxParams{
+					ID:       approvalID,
+					Reviewer: banker.Username,
+					Approve:  false,
+					Reason:   reason,
+				}
+				store.EXPECT().
+					ReviewTransferApprovalTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ReviewTransferApprovalTxResult{Approval: rejected}, nil)
+				distributor.EXPECT().DistributeTaskSendTransferApprovalNotice(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response db.ReviewTransferApprovalTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, util.ApprovalStatusRejected, response.Approval.Status)
+				require.Nil(t, response.Transfer)
+			},
+		},
+		{
+			name:   "NoticeNotEnqueued",
+			action: "approve",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReviewTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewTransferApprovalTxResult{Approval: approved, Transfer: &db.TransferTxResult{}}, nil)
+				distributor.EXPECT().
+					DistributeTaskSendTransferApprovalNotice(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("redis is down"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "MissingReason",
+			action: "reject",
+			body:   gin.H{},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().ReviewTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "Depositor",
+			action: "approve",
+			body:   gin.H{"reason": reason},
+			role:   util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().ReviewTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			action: "approve",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReviewTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewTransferApprovalTxResult{}, db.ErrRecordNotFound)
+				distributor.EXPECT().DistributeTaskSendTransferApprovalNotice(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "SelfApproval",
+			action: "approve",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReviewTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewTransferApprovalTxResult{}, db.ErrSelfApproval)
+				distributor.EXPECT().DistributeTaskSendTransferApprovalNotice(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "NotPending",
+			action: "reject",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReviewTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewTransferApprovalTxResult{}, db.ErrApprovalNotPending)
+				distributor.EXPECT().DistributeTaskSendTransferApprovalNotice(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "InsufficientBalance",
+			action: "approve",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReviewTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewTransferApprovalTxResult{}, db.ErrInsufficientBalance)
+				distributor.EXPECT().DistributeTaskSendTransferApprovalNotice(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "LimitExceeded",
+			action: "approve",
+			body:   gin.H{"reason": reason},
+			role:   util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReviewTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewTransferApprovalTxResult{}, db.ErrTransferLimitExceeded)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			distributor := mockwk.NewMockTaskDistributor(ctrl)
+			tc.buildStubs(store, distributor)
+
+			server := newTestServer(t, store, nil, distributor)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/api/auth/transfers/approvals/%d/%s", approvalID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, banker.Username, tc.role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
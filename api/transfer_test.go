@@ -3,6 +3,8 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,10 +14,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 
 	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
 	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
 	"github.com/RobertChienShiba/simplebank/token"
 	"github.com/RobertChienShiba/simplebank/util"
 )
@@ -61,15 +65,18 @@ func TestCreateTransfer(t *testing.T) {
 			buildStubs: func(store *mockdb.MockStore) {
 				// transfers api endpoints
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
-				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(account1.Currency)).Times(1).Return(1.0, nil)
-				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(account2.Currency)).Times(1).Return(1.0, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(account1.Currency)).Times(1).Return(newRate(t, "1"), nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(account2.Currency)).Times(1).Return(newRate(t, "1"), nil)
 
 				arg := db.TransferTxParams{
 					FromAccountID: account1.ID,
 					ToAccountID:   account2.ID,
 					FromAmount:    amount,
 					ToAmount:      amount,
+					ExchangeRate:  money.NumericFromRat(big.NewRat(1, 1)),
+					Username:      user1.Username,
 				}
 
 				store.EXPECT().
@@ -97,6 +104,7 @@ func TestCreateTransfer(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectNoMember(store, account1.ID, user2.Username)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
@@ -104,6 +112,30 @@ func TestCreateTransfer(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "ViewOnlyMember",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				OTP:           testOTP,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user2.Username, user2.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user2.Username, util.MemberRoleViewOnly)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "NoAuthorization",
 			body: transferRequest{
@@ -141,15 +173,18 @@ func TestCreateTransfer(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
-				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(account1.Currency)).Times(1).Return(1.0, nil)
-				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(account2.Currency)).Times(1).Return(1.0, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(account1.Currency)).Times(1).Return(newRate(t, "1"), nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(account2.Currency)).Times(1).Return(newRate(t, "1"), nil)
 
 				arg := db.TransferTxParams{
 					FromAccountID: account1.ID,
 					ToAccountID:   account2.ID,
 					FromAmount:    amount,
 					ToAmount:      amount,
+					ExchangeRate:  money.NumericFromRat(big.NewRat(1, 1)),
+					Username:      user1.Username,
 				}
 
 				store.EXPECT().
@@ -161,6 +196,261 @@ func TestCreateTransfer(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "AccountNotActive",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				OTP:           testOTP,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(2).Return(newRate(t, "1"), nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrAccountNotActive)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "TransferLimitExceeded",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				OTP:           testOTP,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(2).Return(newRate(t, "1"), nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, fmt.Errorf("%w: a single transfer can't exceed 10", db.ErrTransferLimitExceeded))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "CrossCurrencyRounding",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account3.ID,
+				Amount:        1000,
+				Currency:      util.USD,
+				OTP:           testOTP,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(newRate(t, "32.5"), nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.EUR)).Times(1).Return(newRate(t, "35"), nil)
+
+				// 10.00 USD * 32.5 / 35 = 9.285714... EUR, rounded half to even
+				arg := db.TransferTxParams{
+					FromAccountID:     account1.ID,
+					ToAccountID:       account3.ID,
+					FromAmount:        1000,
+					ToAmount:          929,
+					ExchangeRate:      money.NumericFromRat(big.NewRat(13, 14)),
+					RoundingRemainder: money.NumericFromRat(big.NewRat(-3, 7)),
+					Username:          user1.Username,
+				}
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response transferResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, money.New(1000, util.USD), response.SentAmount)
+				require.Equal(t, money.New(929, util.EUR), response.ReceivedAmount)
+				require.Equal(t, "0.928571428571", response.ExchangeRate)
+			},
+		},
+		{
+			name: "DestinationAmount",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account3.ID,
+				Amount:        929,
+				Currency:      util.EUR,
+				OTP:           testOTP,
+				AmountType:    AmountTypeDestination,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(newRate(t, "32.5"), nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.EUR)).Times(1).Return(newRate(t, "35"), nil)
+
+				// 9.29 EUR * 35 / 32.5 = 10.004615... USD, the sender pays the cent rounded up
+				arg := db.TransferTxParams{
+					FromAccountID:     account1.ID,
+					ToAccountID:       account3.ID,
+					FromAmount:        1001,
+					ToAmount:          929,
+					ExchangeRate:      money.NumericFromRat(big.NewRat(13, 14)),
+					RoundingRemainder: money.NumericFromRat(big.NewRat(1, 2)),
+					Username:          user1.Username,
+				}
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response transferResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, money.New(1001, util.USD), response.SentAmount)
+				require.Equal(t, money.New(929, util.EUR), response.ReceivedAmount)
+				require.Equal(t, "0.928571428571", response.ExchangeRate)
+			},
+		},
+		{
+			name: "DestinationAmountSourceCurrency",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account3.ID,
+				Amount:        929,
+				Currency:      util.USD,
+				OTP:           testOTP,
+				AmountType:    AmountTypeDestination,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				// the amount is in the currency of the destination account, not the source account
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SourceAmountDestinationCurrency",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account3.ID,
+				Amount:        1000,
+				Currency:      util.EUR,
+				OTP:           testOTP,
+				AmountType:    AmountTypeSource,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DestinationAmountWithQuote",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account3.ID,
+				Amount:        929,
+				Currency:      util.EUR,
+				OTP:           testOTP,
+				AmountType:    AmountTypeDestination,
+				QuoteID:       "9b2f3c1e-8f4d-4a51-9c3a-2b1d6e7f8a90",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmountType",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account3.ID,
+				Amount:        929,
+				Currency:      util.USD,
+				OTP:           testOTP,
+				AmountType:    "target",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "NegativeAmount",
 			body: transferRequest{
@@ -230,6 +520,33 @@ func TestCreateTransfer(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "ToCashAccount",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				OTP:           testOTP,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user1.Username, user1.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				cashAccount := account2
+				cashAccount.Owner = util.CashAccountOwner
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				expectMember(store, account1.ID, user1.Username, util.MemberRoleCanTransfer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(cashAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "AccountNotFound",
 			body: transferRequest{
@@ -311,3 +628,194 @@ func TestCreateTransfer(t *testing.T) {
 		})
 	}
 }
+
+func TestReverseTransferAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	transferID := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		transferID    int64
+		setupAuth     func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			transferID: transferID,
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(db.ReverseTransferTxParams{TransferID: transferID})).
+					Times(1).
+					Return(db.ReverseTransferTxResult{
+						OriginalTransfer: db.Transfer{ID: transferID, Status: util.TransferStatusReversed},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.ReverseTransferTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Equal(t, util.TransferStatusReversed, result.OriginalTransfer.Status)
+			},
+		},
+		{
+			name:       "DepositorForbidden",
+			transferID: transferID,
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:       "NotFound",
+			transferID: transferID,
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "AlreadyReversed",
+			transferID: transferID,
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrTransferNotReversible)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:       "AccountNotActive",
+			transferID: transferID,
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrAccountNotActive)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:       "RecipientInsufficientBalance",
+			transferID: transferID,
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrInsufficientBalance)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "InternalError",
+			transferID: transferID,
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:       "InvalidID",
+			transferID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/auth/transfers/%d/reverse", tc.transferID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.router, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func newRate(t *testing.T, rate string) pgtype.Numeric {
+	var numeric pgtype.Numeric
+	require.NoError(t, numeric.Scan(rate))
+	return numeric
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/rbac"
 	"github.com/RobertChienShiba/simplebank/token"
 	"github.com/RobertChienShiba/simplebank/util"
 	"github.com/gin-gonic/gin"
@@ -45,6 +46,14 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
+	if !server.screenSanctions(ctx, db.CreateSanctionsCaseParams{
+		Action:       util.SanctionsActionRegistration,
+		Username:     req.Username,
+		ScreenedName: req.FullName,
+	}) {
+		return
+	}
+
 	// Hash the user's password
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
@@ -98,9 +107,9 @@ func (server *Server) getUser(ctx *gin.Context) {
 	}
 
 	arg := db.ListAccountsParams{
-		Owner:  user.Username,
-		Limit:  int32(5),
-		Offset: 0,
+		Username: user.Username,
+		Limit:    int32(5),
+		Offset:   0,
 	}
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
@@ -124,6 +133,7 @@ func (server *Server) getUser(ctx *gin.Context) {
 			Owner:    account.Owner,
 			Currency: account.Currency,
 			Balance:  account.Balance,
+			Status:   account.Status,
 		})
 	}
 
@@ -166,6 +176,11 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	if user.IsLocked {
+		ctx.JSON(http.StatusForbidden, errorResponse(ErrUserLocked))
+		return
+	}
+
 	accessToken, _, err := server.tokenMaker.CreateToken(req.Username, user.Role, server.config.AccessTokenDuration)
 
 	if err != nil {
@@ -254,13 +269,6 @@ func (x *UpdateUserRequest) GetPassword() string {
 }
 
 func (server *Server) updateUser(ctx *gin.Context) {
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	permission := roleCheck(authPayload.Role, []string{util.BankerRole, util.DepositorRole})
-	if !permission {
-		err := errors.New("no permission")
-		ctx.JSON(http.StatusForbidden, errorResponse(err))
-		return
-	}
 	var req UpdateUserRequest
 	// Bind the request body to the req variable
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -273,12 +281,33 @@ func (server *Server) updateUser(ctx *gin.Context) {
 		return
 	}
 
-	if authPayload.Role != util.BankerRole && authPayload.Username != req.GetUsername() {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if grantedScope(ctx) != rbac.ScopeAny && authPayload.Username != req.GetUsername() {
 		err := errors.New("no permission")
 		ctx.JSON(http.StatusForbidden, errorResponse(err))
 		return
 	}
 
+	before, err := server.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	auditBefore(ctx, before.Username, newUserResponse(before))
+
+	if req.FullName != nil && !server.screenSanctions(ctx, db.CreateSanctionsCaseParams{
+		Action:       util.SanctionsActionProfileUpdate,
+		Username:     req.GetUsername(),
+		ScreenedName: req.GetFullName(),
+	}) {
+		return
+	}
+
 	arg := db.UpdateUserParams{
 		Username: req.GetUsername(),
 		FullName: pgtype.Text{
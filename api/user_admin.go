@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrUserLocked is returned when a locked user tries to sign in or renew their access token
+var ErrUserLocked = errors.New("user is locked")
+
+// managedUserResponse is what bankers see of a user, including role and lock state
+type managedUserResponse struct {
+	userResponse
+	Role     string `json:"role"`
+	Provider string `json:"provider"`
+	IsLocked bool   `json:"is_locked"`
+}
+
+func newManagedUserResponse(user db.User) managedUserResponse {
+	return managedUserResponse{
+		userResponse: newUserResponse(user),
+		Role:         user.Role,
+		Provider:     user.Provider,
+		IsLocked:     user.IsLocked,
+	}
+}
+
+type listUsersRequest struct {
+	Search   string `form:"search"`
+	Role     string `form:"role" binding:"omitempty,oneof=depositor banker"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+// listUsers searches users by username, full name or email
+func (server *Server) listUsers(ctx *gin.Context) {
+	var req listUsersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	users, err := server.store.ListUsers(ctx, db.ListUsersParams{
+		Role:       pgtype.Text{String: req.Role, Valid: req.Role != ""},
+		Search:     pgtype.Text{String: req.Search, Valid: req.Search != ""},
+		PageLimit:  req.PageSize,
+		PageOffset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]managedUserResponse, len(users))
+	for i, user := range users {
+		rsp[i] = newManagedUserResponse(user)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// manageUserRequest isn't limited to alphanumeric usernames, those of Google users end in "-google" and may contain spaces
+type manageUserRequest struct {
+	Username string `uri:"username" binding:"required"`
+}
+
+type updateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=depositor banker"`
+}
+
+// updateUserRole promotes or demotes a user. The new role is in the next token they are issued,
+// the tokens issued with the old role are revoked right away.
+func (server *Server) updateUserRole(ctx *gin.Context) {
+	var uri manageUserRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	before, ok := server.managedUser(ctx, uri.Username)
+	if !ok {
+		return
+	}
+
+	if before.Role == req.Role {
+		ctx.JSON(http.StatusOK, newManagedUserResponse(before))
+		return
+	}
+
+	user, err := server.store.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+		Role:     req.Role,
+		Username: before.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := server.tokenRevoker.RevokeTokens(user.Username); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newManagedUserResponse(user))
+}
+
+func (server *Server) lockUser(ctx *gin.Context) {
+	server.setUserLock(ctx, true)
+}
+
+func (server *Server) unlockUser(ctx *gin.Context) {
+	server.setUserLock(ctx, false)
+}
+
+// setUserLock locks or unlocks a user, locking also revokes the tokens already issued to them
+func (server *Server) setUserLock(ctx *gin.Context, locked bool) {
+	var uri manageUserRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	before, ok := server.managedUser(ctx, uri.Username)
+	if !ok {
+		return
+	}
+
+	user, err := server.store.UpdateUserLock(ctx, db.UpdateUserLockParams{
+		IsLocked: locked,
+		Username: before.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if locked {
+		if err := server.tokenRevoker.RevokeTokens(user.Username); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, newManagedUserResponse(user))
+}
+
+// managedUser loads a user a banker is about to change, bankers can't change themselves
+// so the bank can't be left without an unlocked banker by mistake
+func (server *Server) managedUser(ctx *gin.Context, username string) (db.User, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if username == authPayload.Username {
+		err := errors.New("bankers can't change their own role or lock themselves")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return db.User{}, false
+	}
+
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return user, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return user, false
+	}
+	auditBefore(ctx, user.Username, newManagedUserResponse(user))
+
+	return user, true
+}
@@ -0,0 +1,397 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	mocksession "github.com/RobertChienShiba/simplebank/redis/mock"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListUsersAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	testCases := []struct {
+		name          string
+		query         string
+		authUser      db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			query:    "?search=ali&role=depositor&page_id=2&page_size=5",
+			authUser: banker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Eq(db.ListUsersParams{
+						Role:       pgtype.Text{String: util.DepositorRole, Valid: true},
+						Search:     pgtype.Text{String: "ali", Valid: true},
+						PageLimit:  5,
+						PageOffset: 5,
+					})).
+					Times(1).
+					Return([]db.User{user}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response []managedUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response, 1)
+				require.Equal(t, user.Username, response[0].Username)
+				require.Equal(t, user.Role, response[0].Role)
+				require.False(t, response[0].IsLocked)
+				require.NotContains(t, recorder.Body.String(), "hashed_password")
+			},
+		},
+		{
+			name:     "NoFilters",
+			query:    "?page_id=1&page_size=5",
+			authUser: banker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Eq(db.ListUsersParams{PageLimit: 5, PageOffset: 0})).
+					Times(1).
+					Return([]db.User{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "DepositorForbidden",
+			query:    "?page_id=1&page_size=5",
+			authUser: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidRole",
+			query:    "?role=admin&page_id=1&page_size=5",
+			authUser: banker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			query:    "?page_id=1&page_size=5",
+			authUser: banker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, nil, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/auth/users"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.authUser.Username, tc.authUser.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestManageUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	promoted := user
+	promoted.Role = util.BankerRole
+	locked := user
+	locked.IsLocked = true
+	googleUser := user
+	googleUser.Username = "Jane Doe-google"
+	googleUser.Provider = "Google"
+	lockedGoogleUser := googleUser
+	lockedGoogleUser.IsLocked = true
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore, session *mocksession.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Promote",
+			method: http.MethodPut,
+			url:    "/api/auth/users/" + user.Username + "/role",
+			body:   gin.H{"role": util.BankerRole},
+			role:   banker.Role,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					UpdateUserRole(gomock.Any(), gomock.Eq(db.UpdateUserRoleParams{Role: util.BankerRole, Username: user.Username})).
+					Times(1).
+					Return(promoted, nil)
+				session.EXPECT().
+					Set(gomock.Eq(revokedTokensKey(user.Username)), gomock.Any(), gomock.Eq(time.Minute)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response managedUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, util.BankerRole, response.Role)
+			},
+		},
+		{
+			name:   "SameRole",
+			method: http.MethodPut,
+			url:    "/api/auth/users/" + user.Username + "/role",
+			body:   gin.H{"role": util.DepositorRole},
+			role:   banker.Role,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+				session.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidRole",
+			method: http.MethodPut,
+			url:    "/api/auth/users/" + user.Username + "/role",
+			body:   gin.H{"role": "admin"},
+			role:   banker.Role,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "DepositorForbidden",
+			method: http.MethodPut,
+			url:    "/api/auth/users/" + user.Username + "/role",
+			body:   gin.H{"role": util.BankerRole},
+			role:   util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Self",
+			method: http.MethodPut,
+			url:    "/api/auth/users/" + banker.Username + "/role",
+			body:   gin.H{"role": util.DepositorRole},
+			role:   banker.Role,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "UserNotFound",
+			method: http.MethodPut,
+			url:    "/api/auth/users/" + user.Username + "/role",
+			body:   gin.H{"role": util.BankerRole},
+			role:   banker.Role,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeError",
+			method: http.MethodPut,
+			url:    "/api/auth/users/" + user.Username + "/role",
+			body:   gin.H{"role": util.BankerRole},
+			role:   banker.Role,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(1).Return(promoted, nil)
+				session.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "Lock",
+			method: http.MethodPost,
+			url:    "/api/auth/users/" + user.Username + "/lock",
+			role:   banker.Role,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					UpdateUserLock(gomock.Any(), gomock.Eq(db.UpdateUserLockParams{IsLocked: true, Username: user.Username})).
+					Times(1).
+					Return(locked, nil)
+				session.EXPECT().
+					Set(gomock.Eq(revokedTokensKey(user.Username)), gomock.Any(), gomock.Eq(time.Minute)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response managedUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.True(t, response.IsLocked)
+			},
+		},
+		{
+			name:   "Unlock",
+			method: http.MethodPost,
+			url:    "/api/auth/users/" + user.Username + "/unlock",
+			role:   banker.Role,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(locked, nil)
+				store.EXPECT().
+					UpdateUserLock(gomock.Any(), gomock.Eq(db.UpdateUserLockParams{IsLocked: false, Username: user.Username})).
+					Times(1).
+					Return(user, nil)
+				session.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "LockGoogleUser",
+			method: http.MethodPost,
+			url:    "/api/auth/users/" + url.PathEscape(googleUser.Username) + "/lock",
+			role:   banker.Role,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(googleUser.Username)).Times(1).Return(googleUser, nil)
+				store.EXPECT().
+					UpdateUserLock(gomock.Any(), gomock.Eq(db.UpdateUserLockParams{IsLocked: true, Username: googleUser.Username})).
+					Times(1).
+					Return(lockedGoogleUser, nil)
+				session.EXPECT().
+					Set(gomock.Eq(revokedTokensKey(googleUser.Username)), gomock.Any(), gomock.Eq(time.Minute)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "LockSelf",
+			method: http.MethodPost,
+			url:    "/api/auth/users/" + banker.Username + "/lock",
+			role:   banker.Role,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateUserLock(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "LockForbidden",
+			method: http.MethodPost,
+			url:    "/api/auth/users/" + user.Username + "/lock",
+			role:   util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateUserLock(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			session := mocksession.NewMockStore(ctrl)
+			// no tokens were revoked before the request
+			session.EXPECT().Get(gomock.Any()).AnyTimes().Return("", redis.Nil)
+			tc.buildStubs(store, session)
+
+			server := newTestServer(t, store, session, nil)
+			server.tokenRevoker = newRedisTokenRevoker(session, server.config.AccessTokenDuration)
+			recorder := httptest.NewRecorder()
+
+			var body []byte
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = data
+			}
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			csrfReq, _ := http.NewRequest(http.MethodGet, "/api/auth/transfers", nil)
+			addAuthorization(t, csrfReq, server.tokenMaker, authorizationTypeBearer, banker.Username, tc.role, time.Minute)
+			addCSRFToken(t, csrfReq, request, server.router)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -217,9 +217,9 @@ func TestGetUserAPI(t *testing.T) {
 					Return(user, nil)
 
 				arg := db.ListAccountsParams{
-					Owner:  user.Username,
-					Limit:  int32(n),
-					Offset: 0,
+					Username: user.Username,
+					Limit:    int32(n),
+					Offset:   0,
 				}
 
 				store.EXPECT().
@@ -400,6 +400,28 @@ func TestLoginUserAPI(t *testing.T) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name: "LockedUser",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				lockedUser := user
+				lockedUser.IsLocked = true
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(lockedUser, nil)
+
+				session.EXPECT().
+					Set(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "IncorrectPassword",
 			body: gin.H{
@@ -521,6 +543,10 @@ func TestUpdateUserAPI(t *testing.T) {
 					Email:             newEmail,
 					PasswordChangedAt: user.PasswordChangedAt,
 				}
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
 				store.EXPECT().
 					UpdateUser(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
@@ -561,6 +587,10 @@ func TestUpdateUserAPI(t *testing.T) {
 					Email:             newEmail,
 					PasswordChangedAt: user.PasswordChangedAt,
 				}
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
 				store.EXPECT().
 					UpdateUser(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
@@ -575,6 +605,30 @@ func TestUpdateUserAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "UserNotFound",
+			body: UpdateUserRequest{
+				Username: user.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore, session *mocksession.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			setupAuth: func(t *testing.T, request *http.Request, router *gin.Engine, tokenMaker token.Maker) {
+				csrfReq, _ := http.NewRequest(http.MethodGet, url, nil)
+				addAuthorization(t, csrfReq, tokenMaker, authorizationTypeBearer, banker.Username, banker.Role, time.Minute)
+				addCSRFToken(t, csrfReq, request, router)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
 		{
 			name: "OtherDepositorCannotUpdateThisUserInfo",
 			body: UpdateUserRequest{
@@ -5,7 +5,9 @@ import (
 	"net/mail"
 	"regexp"
 
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
 	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/RobertChienShiba/simplebank/webhook"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -16,6 +18,30 @@ var (
 		}
 		return false
 	}
+	validFrequency validator.Func = func(fieldLevel validator.FieldLevel) bool {
+		if frequency, ok := fieldLevel.Field().Interface().(string); ok {
+			return util.IsSupportedFrequency(frequency)
+		}
+		return false
+	}
+	validEventType validator.Func = func(fieldLevel validator.FieldLevel) bool {
+		if eventType, ok := fieldLevel.Field().Interface().(string); ok {
+			return db.IsSupportedEventType(eventType)
+		}
+		return false
+	}
+	validMemberRole validator.Func = func(fieldLevel validator.FieldLevel) bool {
+		if role, ok := fieldLevel.Field().Interface().(string); ok {
+			return util.IsSupportedMemberRole(role)
+		}
+		return false
+	}
+	validWebhookURL validator.Func = func(fieldLevel validator.FieldLevel) bool {
+		if url, ok := fieldLevel.Field().Interface().(string); ok {
+			return webhook.ValidateURL(url) == nil
+		}
+		return false
+	}
 	isValidUsername = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
 	isValidFullName = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
 )
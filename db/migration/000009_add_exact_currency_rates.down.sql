@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "rounding_remainders";
+
+ALTER TABLE "currencies" ALTER COLUMN "rate" TYPE float USING "rate"::float;
@@ -0,0 +1,15 @@
+ALTER TABLE "currencies" ALTER COLUMN "rate" TYPE numeric USING "rate"::numeric;
+
+CREATE TABLE "rounding_remainders" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "amount" numeric NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "rounding_remainders" ("transfer_id");
+
+COMMENT ON COLUMN "rounding_remainders"."amount" IS 'exact minus rounded amount, in minor units';
+
+ALTER TABLE "rounding_remainders" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
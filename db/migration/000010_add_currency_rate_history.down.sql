@@ -0,0 +1,9 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "exchange_rate";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_amount";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_currency";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "from_currency";
+
+DROP TABLE IF EXISTS "currency_rates";
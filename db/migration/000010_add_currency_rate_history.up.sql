@@ -0,0 +1,37 @@
+CREATE TABLE "currency_rates" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "rate" numeric NOT NULL,
+  "effective_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "currency_rates" ("currency", "effective_at");
+
+COMMENT ON COLUMN "currency_rates"."rate" IS 'base currency units per major unit';
+
+INSERT INTO "currency_rates" ("currency", "rate", "effective_at")
+SELECT "currency", "rate", "created_at" FROM "currencies";
+
+ALTER TABLE "transfers" ADD COLUMN "from_currency" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "to_currency" varchar;
+
+UPDATE "transfers" AS t
+SET "from_currency" = fa."currency", "to_currency" = ta."currency"
+FROM "accounts" AS fa, "accounts" AS ta
+WHERE fa."id" = t."from_account_id" AND ta."id" = t."to_account_id";
+
+ALTER TABLE "transfers" ALTER COLUMN "from_currency" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "to_currency" SET NOT NULL;
+
+-- Transfers made before this migration did not record the received amount nor the rate
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" numeric;
+
+UPDATE "transfers" AS t
+SET "to_amount" = e."amount"
+FROM "entries" AS e
+WHERE e."transfer_id" = t."id" AND e."account_id" = t."to_account_id" AND e."amount" > 0;
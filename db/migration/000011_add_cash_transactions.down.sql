@@ -0,0 +1,9 @@
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "cash_transaction_id";
+
+DROP TABLE IF EXISTS "cash_transactions";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bank_cash');
+
+DELETE FROM "accounts" WHERE "owner" = 'bank_cash';
+
+DELETE FROM "users" WHERE "username" = 'bank_cash';
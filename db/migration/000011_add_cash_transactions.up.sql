@@ -0,0 +1,38 @@
+CREATE TABLE "cash_transactions" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "cash_account_id" bigint NOT NULL,
+  "type" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "reference" varchar UNIQUE NOT NULL,
+  "reason" varchar NOT NULL,
+  "banker" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "cash_transactions" ("account_id");
+
+COMMENT ON COLUMN "cash_transactions"."type" IS 'deposit or withdrawal';
+
+COMMENT ON COLUMN "cash_transactions"."amount" IS 'must be positive';
+
+ALTER TABLE "cash_transactions" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_transactions" ADD FOREIGN KEY ("cash_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_transactions" ADD FOREIGN KEY ("banker") REFERENCES "users" ("username");
+
+ALTER TABLE "entries" ADD COLUMN "cash_transaction_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("cash_transaction_id") REFERENCES "cash_transactions" ("id");
+
+CREATE INDEX ON "entries" ("cash_transaction_id");
+
+-- The bank's cash accounts belong to a system user that cannot log in, one account per currency
+INSERT INTO "users" ("username", "role", "provider", "hashed_password", "full_name", "email")
+VALUES ('bank_cash', 'depositor', 'system', '', 'Go2Bank Cash', 'cash@go2bank.internal')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+SELECT 'bank_cash', 0, "currency" FROM "currencies"
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "initiated_by";
+
+DROP TABLE IF EXISTS "transfer_limits";
@@ -0,0 +1,43 @@
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "role" varchar,
+  "username" varchar,
+  "currency" varchar NOT NULL,
+  "max_single" bigint,
+  "max_daily" bigint,
+  "max_monthly" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK (("role" IS NULL) <> ("username" IS NULL)),
+  CHECK ("max_single" >= 0 AND "max_daily" >= 0 AND "max_monthly" >= 0),
+  CHECK ("username" IS NOT NULL OR ("max_single" > 0 AND "max_daily" > 0 AND "max_monthly" > 0))
+);
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("role", "currency") WHERE "username" IS NULL;
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("username", "currency") WHERE "role" IS NULL;
+
+COMMENT ON COLUMN "transfer_limits"."role" IS 'set for the default limits of a role';
+
+COMMENT ON COLUMN "transfer_limits"."username" IS 'set for the overrides of a single user';
+
+COMMENT ON COLUMN "transfer_limits"."max_single" IS 'null inherits the role default or means unlimited, 0 in an override means unlimited';
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("currency");
+
+ALTER TABLE "transfers" ADD COLUMN "initiated_by" varchar;
+
+-- Transfers posted before limits were sent by the owner of the source account
+UPDATE "transfers" t
+SET "initiated_by" = a."owner"
+FROM "accounts" a
+WHERE a."id" = t."from_account_id"
+  AND t."reversal_of" IS NULL;
+
+CREATE INDEX ON "transfers" ("initiated_by", "created_at");
+
+COMMENT ON COLUMN "transfers"."initiated_by" IS 'the user the transfer is charged to against the limits, null for reversals';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("initiated_by") REFERENCES "users" ("username");
@@ -0,0 +1,25 @@
+CREATE TABLE "outbox_events" (
+  "id" bigserial PRIMARY KEY,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "published_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "claimed_until" timestamptz,
+  "dead_lettered_at" timestamptz
+);
+
+CREATE INDEX ON "outbox_events" ("id") WHERE "published_at" IS NULL AND "dead_lettered_at" IS NULL;
+
+COMMENT ON COLUMN "outbox_events"."aggregate_type" IS 'transfer, entry, account or cash_transaction';
+
+COMMENT ON COLUMN "outbox_events"."event_type" IS 'e.g. transfer.created or account.balance_changed';
+
+COMMENT ON COLUMN "outbox_events"."published_at" IS 'null until a relay published the event';
+
+COMMENT ON COLUMN "outbox_events"."claimed_until" IS 'a relay is publishing the event, other relays skip it until then';
+
+COMMENT ON COLUMN "outbox_events"."dead_lettered_at" IS 'the event failed too many times and is no longer relayed';
@@ -0,0 +1,42 @@
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "actor_role" varchar NOT NULL,
+  "ip" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "method" varchar NOT NULL,
+  "route" varchar NOT NULL,
+  "resource_type" varchar NOT NULL,
+  "resource_id" varchar,
+  "status_code" int NOT NULL,
+  "request" jsonb,
+  "changes" jsonb,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_log" ("actor", "id");
+
+CREATE INDEX ON "audit_log" ("resource_type", "resource_id", "id");
+
+CREATE INDEX ON "audit_log" ("created_at");
+
+COMMENT ON COLUMN "audit_log"."route" IS 'route pattern, e.g. /api/auth/accounts/:id/freeze';
+
+COMMENT ON COLUMN "audit_log"."request" IS 'request body with secrets redacted';
+
+COMMENT ON COLUMN "audit_log"."changes" IS 'changed fields as {"field": {"before": ..., "after": ...}}, secrets redacted';
+
+-- The audit log is append-only, even for the owner of the table
+CREATE FUNCTION audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_log_no_modify"
+BEFORE UPDATE OR DELETE ON "audit_log"
+FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
+
+CREATE TRIGGER "audit_log_no_truncate"
+BEFORE TRUNCATE ON "audit_log"
+FOR EACH STATEMENT EXECUTE FUNCTION audit_log_immutable();
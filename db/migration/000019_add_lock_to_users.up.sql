@@ -0,0 +1 @@
+ALTER TABLE "users" ADD COLUMN "is_locked" bool NOT NULL DEFAULT false;
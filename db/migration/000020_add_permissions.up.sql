@@ -0,0 +1,41 @@
+CREATE TABLE "permissions" (
+  "name" varchar PRIMARY KEY,
+  "description" varchar NOT NULL
+);
+
+CREATE TABLE "role_permissions" (
+  "role" varchar NOT NULL,
+  "permission" varchar NOT NULL,
+  "scope" varchar NOT NULL DEFAULT 'own',
+  PRIMARY KEY ("role", "permission")
+);
+
+COMMENT ON COLUMN "role_permissions"."scope" IS 'own for resources of the user only, any for every resource';
+
+ALTER TABLE "role_permissions" ADD FOREIGN KEY ("permission") REFERENCES "permissions" ("name");
+
+-- The policy the handlers used to hard-code
+INSERT INTO "permissions" ("name", "description") VALUES
+  ('user:update', 'Update the profile of a user'),
+  ('user:manage', 'Search users, change their role and lock them'),
+  ('account:freeze', 'Freeze and unfreeze accounts'),
+  ('cash:post', 'Deposit and withdraw cash'),
+  ('limit:manage', 'View and set the transfer limits of users and roles'),
+  ('audit:read', 'Search the audit log'),
+  ('transfer:create', 'Send and schedule transfers'),
+  ('transfer:reverse', 'Reverse completed transfers'),
+  ('hold:settle', 'Capture and release holds');
+
+INSERT INTO "role_permissions" ("role", "permission", "scope") VALUES
+  ('depositor', 'user:update', 'own'),
+  ('depositor', 'transfer:create', 'own'),
+  ('depositor', 'hold:settle', 'own'),
+  ('banker', 'user:update', 'any'),
+  ('banker', 'user:manage', 'any'),
+  ('banker', 'account:freeze', 'any'),
+  ('banker', 'cash:post', 'any'),
+  ('banker', 'limit:manage', 'any'),
+  ('banker', 'audit:read', 'any'),
+  ('banker', 'transfer:create', 'own'),
+  ('banker', 'transfer:reverse', 'any'),
+  ('banker', 'hold:settle', 'any');
@@ -0,0 +1,7 @@
+DELETE FROM "role_permissions" WHERE "permission" = 'transfer:approve';
+
+DELETE FROM "permissions" WHERE "name" = 'transfer:approve';
+
+ALTER TABLE IF EXISTS "scheduled_transfer_runs" DROP COLUMN IF EXISTS "approval_id";
+
+DROP TABLE IF EXISTS "transfer_approvals";
@@ -0,0 +1,57 @@
+CREATE TABLE "transfer_approvals" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "exchange_rate" numeric NOT NULL,
+  "rounding_remainder" numeric,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "requested_by" varchar NOT NULL,
+  "reviewed_by" varchar,
+  "reason" varchar,
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "reviewed_at" timestamptz,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "hold_id" bigint
+);
+
+CREATE INDEX ON "transfer_approvals" ("status", "expires_at");
+
+CREATE INDEX ON "transfer_approvals" ("requested_by");
+
+COMMENT ON COLUMN "transfer_approvals"."amount" IS 'debited from from_account_id, the amounts and rate are locked when the transfer is requested';
+
+COMMENT ON COLUMN "transfer_approvals"."status" IS 'pending, approved, rejected or expired';
+
+COMMENT ON COLUMN "transfer_approvals"."reviewed_by" IS 'a banker other than requested_by';
+
+COMMENT ON COLUMN "transfer_approvals"."hold_id" IS 'the hold an approved transfer captures';
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("hold_id") REFERENCES "holds" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD COLUMN "approval_id" bigint;
+
+CREATE INDEX ON "scheduled_transfer_runs" ("approval_id");
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."approval_id" IS 'the approval a pending run waits for';
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("approval_id") REFERENCES "transfer_approvals" ("id");
+
+INSERT INTO "permissions" ("name", "description") VALUES
+  ('transfer:approve', 'Approve and reject transfers above the approval threshold');
+
+INSERT INTO "role_permissions" ("role", "permission", "scope") VALUES
+  ('banker', 'transfer:approve', 'any');
@@ -0,0 +1,7 @@
+DELETE FROM "role_permissions" WHERE "permission" = 'fraud:review';
+
+DELETE FROM "permissions" WHERE "name" = 'fraud:review';
+
+ALTER TABLE "transfer_approvals" DROP COLUMN IF EXISTS "fraud_decision_id";
+
+DROP TABLE IF EXISTS "fraud_decisions";
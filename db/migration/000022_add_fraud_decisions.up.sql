@@ -0,0 +1,39 @@
+CREATE TABLE "fraud_decisions" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "decision" varchar NOT NULL,
+  "rules" jsonb NOT NULL DEFAULT '[]',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "fraud_decisions" ("decision", "created_at");
+
+CREATE INDEX ON "fraud_decisions" ("username");
+
+COMMENT ON COLUMN "fraud_decisions"."amount" IS 'debited from from_account_id, in currency';
+
+COMMENT ON COLUMN "fraud_decisions"."decision" IS 'allow, hold or block, the strictest of the triggered rules';
+
+COMMENT ON COLUMN "fraud_decisions"."rules" IS 'the triggered rules with their decision and detail';
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD COLUMN "fraud_decision_id" bigint;
+
+COMMENT ON COLUMN "transfer_approvals"."fraud_decision_id" IS 'the screening of the transfer, which may be why it is held';
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("fraud_decision_id") REFERENCES "fraud_decisions" ("id");
+
+INSERT INTO "permissions" ("name", "description") VALUES
+  ('fraud:review', 'Review the decisions of the fraud rules');
+
+INSERT INTO "role_permissions" ("role", "permission", "scope") VALUES
+  ('banker', 'fraud:review', 'any');
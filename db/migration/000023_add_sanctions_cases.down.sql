@@ -0,0 +1,5 @@
+DELETE FROM "role_permissions" WHERE "permission" = 'sanctions:review';
+
+DELETE FROM "permissions" WHERE "name" = 'sanctions:review';
+
+DROP TABLE IF EXISTS "sanctions_cases";
@@ -0,0 +1,40 @@
+CREATE TABLE "sanctions_cases" (
+  "id" bigserial PRIMARY KEY,
+  "action" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "account_id" bigint,
+  "screened_name" varchar NOT NULL,
+  "list_entry_id" varchar NOT NULL,
+  "matched_name" varchar NOT NULL,
+  "program" varchar NOT NULL,
+  "score" double precision NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'open',
+  "reviewed_by" varchar,
+  "reason" varchar,
+  "reviewed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "sanctions_cases" ("status", "created_at");
+
+CREATE INDEX ON "sanctions_cases" ("screened_name", "list_entry_id");
+
+COMMENT ON COLUMN "sanctions_cases"."action" IS 'registration, transfer or profile_update, the action that was blocked';
+
+COMMENT ON COLUMN "sanctions_cases"."username" IS 'the user registering, changing the full name or sending the transfer, not a user yet for a registration';
+
+COMMENT ON COLUMN "sanctions_cases"."account_id" IS 'the recipient account of a transfer';
+
+COMMENT ON COLUMN "sanctions_cases"."score" IS 'Jaro-Winkler similarity of the screened and matched names, from 0 to 1';
+
+COMMENT ON COLUMN "sanctions_cases"."status" IS 'open, cleared as a false positive or confirmed';
+
+ALTER TABLE "sanctions_cases" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "sanctions_cases" ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+
+INSERT INTO "permissions" ("name", "description") VALUES
+  ('sanctions:review', 'Review sanctions cases and reload the sanctions list');
+
+INSERT INTO "role_permissions" ("role", "permission", "scope") VALUES
+  ('banker', 'sanctions:review', 'any');
@@ -0,0 +1,4 @@
+-- A database that ran 000007 while it declared the check has it under the default name already
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_status_check";
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_status_check" CHECK ("status" IN ('completed', 'reversed'));
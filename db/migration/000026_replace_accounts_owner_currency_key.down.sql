@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "accounts_owner_currency_key";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen, dormant or closed';
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_status_check";
@@ -0,0 +1,11 @@
+-- Nothing ever marked an account dormant, the status is dropped
+UPDATE "accounts" SET "status" = 'active' WHERE "status" = 'dormant';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_status_check" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed';
+
+-- A closed account keeps its row, so only the accounts still open hold the owner's currency
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_key";
+
+CREATE UNIQUE INDEX "accounts_owner_currency_key" ON "accounts" ("owner", "currency") WHERE "status" <> 'closed';
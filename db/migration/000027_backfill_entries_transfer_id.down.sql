@@ -0,0 +1 @@
+-- The backfilled links are kept, they are dropped along with the column by 000007
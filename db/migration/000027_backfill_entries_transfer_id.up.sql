@@ -0,0 +1,13 @@
+-- Transfers posted before 000007 have entries without a transfer_id. They were written in the
+-- transaction of their transfer, so both share its created_at: pair them by account and time.
+-- The debit is the transfer amount, the credit of a cross-currency transfer is the converted amount.
+UPDATE "entries" e
+SET "transfer_id" = t."id"
+FROM "transfers" t
+WHERE e."transfer_id" IS NULL
+  AND e."created_at" = t."created_at"
+  AND (
+    (e."account_id" = t."from_account_id" AND e."amount" = -t."amount")
+    OR (e."account_id" = t."to_account_id" AND e."amount" > 0)
+  );
+
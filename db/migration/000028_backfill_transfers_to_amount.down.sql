@@ -0,0 +1 @@
+-- The backfilled received amounts are kept, they are dropped along with the column by 000010
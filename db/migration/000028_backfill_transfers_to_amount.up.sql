@@ -0,0 +1,7 @@
+-- 000010 found no linked credit for the transfers posted before 000007, 000027 linked them since,
+-- so their received amount is that credit
+UPDATE "transfers" AS t
+SET "to_amount" = e."amount"
+FROM "entries" AS e
+WHERE e."transfer_id" = t."id" AND e."account_id" = t."to_account_id" AND e."amount" > 0
+  AND t."to_amount" IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "scheduled_transfer_runs" DROP CONSTRAINT IF EXISTS "scheduled_transfer_runs_status_check";
@@ -0,0 +1,3 @@
+-- A run waits as pending for the approval it needs since transfer approvals were added
+ALTER TABLE "scheduled_transfer_runs" ADD CONSTRAINT "scheduled_transfer_runs_status_check"
+  CHECK ("status" IN ('claimed', 'completed', 'failed', 'pending'));
@@ -16,10 +16,25 @@ SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
--- name: ListAccounts :many
+-- name: GetAccountByOwner :one
 SELECT * FROM accounts
-WHERE owner = $1
-ORDER BY id
+WHERE owner = $1 AND currency = $2 AND status <> 'closed' LIMIT 1;
+
+-- name: CreateAccountIfNotExists :exec
+INSERT INTO accounts (
+  owner,
+  balance,
+  currency
+) VALUES (
+  $1, 0, $2
+) ON CONFLICT (owner, currency) WHERE status <> 'closed' DO NOTHING;
+
+-- name: ListAccounts :many
+-- Accounts the user is an active member of
+SELECT a.* FROM accounts a
+JOIN account_members m ON m.account_id = a.id
+WHERE m.username = $1 AND m.status = 'active'
+ORDER BY a.id
 LIMIT $2
 OFFSET $3;
 
@@ -35,6 +50,23 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id) AND status = ANY(sqlc.arg(from_statuses)::varchar[])
+RETURNING *;
+
+-- name: ListAccountsAfter :many
+-- Customer accounts that are still open, the bank's cash accounts are left out.
+SELECT * FROM accounts
+WHERE id > $1 AND owner <> 'bank_cash' AND status <> 'closed'
+ORDER BY id
+LIMIT $2;
+
+-- name: GetAccountBalanceAt :one
+-- Balance the account held at the given time, derived backwards from the current balance.
+SELECT (a.balance - COALESCE(SUM(e.amount), 0))::bigint AS balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id AND e.created_at >= sqlc.arg(at_time)::timestamptz
+WHERE a.id = sqlc.arg(account_id)
+GROUP BY a.id;
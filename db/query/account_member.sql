@@ -0,0 +1,47 @@
+-- name: CreateAccountMember :one
+INSERT INTO account_members (
+  account_id,
+  username,
+  role,
+  status,
+  invited_by,
+  accepted_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetAccountMember :one
+SELECT * FROM account_members
+WHERE account_id = $1 AND username = $2 LIMIT 1;
+
+-- name: ListAccountMembers :many
+SELECT * FROM account_members
+WHERE account_id = $1
+ORDER BY created_at, username;
+
+-- name: ListAccountInvitations :many
+-- Pending invitations of the user, the oldest first
+SELECT * FROM account_members
+WHERE username = $1 AND status = 'invited'
+ORDER BY created_at;
+
+-- name: AcceptAccountMember :one
+UPDATE account_members
+SET
+  status = 'active',
+  accepted_at = now(),
+  updated_at = now()
+WHERE account_id = sqlc.arg(account_id) AND username = sqlc.arg(username) AND status = 'invited'
+RETURNING *;
+
+-- name: UpdateAccountMemberRole :one
+UPDATE account_members
+SET
+  role = sqlc.arg(role),
+  updated_at = now()
+WHERE account_id = sqlc.arg(account_id) AND username = sqlc.arg(username)
+RETURNING *;
+
+-- name: DeleteAccountMember :exec
+DELETE FROM account_members
+WHERE account_id = $1 AND username = $2;
@@ -0,0 +1,23 @@
+-- name: CreateCashTransaction :one
+INSERT INTO cash_transactions (
+  account_id,
+  cash_account_id,
+  type,
+  amount,
+  reference,
+  reason,
+  banker
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetCashTransaction :one
+SELECT * FROM cash_transactions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListCashTransactions :many
+SELECT * FROM cash_transactions
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
@@ -1,3 +1,35 @@
 -- name: GetExchangeRate :one
 SELECT rate FROM currencies
-WHERE currency = $1 LIMIT 1;
\ No newline at end of file
+WHERE currency = $1 LIMIT 1;
+
+-- name: ListCurrencies :many
+SELECT * FROM currencies
+ORDER BY currency;
+
+-- name: CreateCurrencyRate :one
+INSERT INTO currency_rates (
+  currency,
+  rate,
+  effective_at
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: ListCurrencyRateHistory :many
+SELECT * FROM currency_rates
+WHERE currency = $1
+ORDER BY effective_at DESC, id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: UpsertCurrency :one
+INSERT INTO currencies (
+  currency,
+  rate
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (currency) DO UPDATE SET
+  rate = EXCLUDED.rate,
+  created_at = now()
+RETURNING *;
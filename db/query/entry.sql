@@ -1,9 +1,19 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3
+) RETURNING *;
+
+-- name: CreateCashEntry :one
+INSERT INTO entries (
+  account_id,
+  amount,
+  cash_transaction_id
+) VALUES (
+  $1, $2, $3
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +25,75 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: ListEntriesByTransfer :many
+SELECT * FROM entries
+WHERE transfer_id = $1
+ORDER BY id;
+
+-- name: ListAccountEntries :many
+-- running_balance is the account balance right after each entry. It is
+-- derived from the current balance minus the entries posted after it, so a
+-- page only reads the entries from its oldest line onwards.
+WITH page AS (
+  SELECT id, account_id, amount, transfer_id, cash_transaction_id, created_at
+  FROM entries
+  WHERE account_id = sqlc.arg(account_id)
+    AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+    AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+    AND (sqlc.narg(direction)::varchar IS NULL
+      OR (sqlc.narg(direction) = 'credit' AND amount > 0)
+      OR (sqlc.narg(direction) = 'debit' AND amount < 0))
+    AND (sqlc.narg(cursor)::bigint IS NULL OR id < sqlc.narg(cursor))
+  ORDER BY id DESC
+  LIMIT sqlc.arg(page_size)
+), later AS (
+  SELECT id, SUM(amount) OVER (ORDER BY id DESC) AS since_total
+  FROM entries
+  WHERE account_id = sqlc.arg(account_id) AND id >= (SELECT MIN(id) FROM page)
+)
+SELECT
+  p.id,
+  p.account_id,
+  p.amount,
+  p.transfer_id,
+  p.cash_transaction_id,
+  p.created_at,
+  (a.balance - l.since_total + p.amount)::bigint AS running_balance
+FROM page p
+JOIN later l ON l.id = p.id
+JOIN accounts a ON a.id = p.account_id
+ORDER BY p.id DESC;
+
+-- name: ListStatementEntries :many
+-- Every entry of the account in [from_time, to_time), oldest first, with the
+-- transfer it belongs to and the balance right after it.
+WITH period AS (
+  SELECT id, amount, transfer_id, cash_transaction_id, created_at
+  FROM entries
+  WHERE account_id = sqlc.arg(account_id)
+    AND created_at >= sqlc.arg(from_time)::timestamptz
+    AND created_at < sqlc.arg(to_time)::timestamptz
+), later AS (
+  SELECT id, SUM(amount) OVER (ORDER BY id DESC) AS since_total
+  FROM entries
+  WHERE account_id = sqlc.arg(account_id) AND id >= (SELECT MIN(id) FROM period)
+)
+SELECT
+  p.id,
+  p.amount,
+  p.transfer_id,
+  p.created_at,
+  (a.balance - l.since_total + p.amount)::bigint AS running_balance,
+  t.from_account_id,
+  t.to_account_id,
+  t.reversal_of,
+  p.cash_transaction_id,
+  c.reference AS cash_reference
+FROM period p
+JOIN later l ON l.id = p.id
+JOIN accounts a ON a.id = sqlc.arg(account_id)
+LEFT JOIN transfers t ON t.id = p.transfer_id
+LEFT JOIN cash_transactions c ON c.id = p.cash_transaction_id
+ORDER BY p.id;
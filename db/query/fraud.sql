@@ -0,0 +1,47 @@
+-- name: CountTransfersFrom :one
+-- Counts the transfers the account sent since the given time, reversals excluded
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = sqlc.arg(from_account_id)
+  AND reversal_of IS NULL
+  AND created_at >= sqlc.arg(since)::timestamptz;
+
+-- name: CountTransfersBetween :one
+-- Counts the transfers from one account to another since the given time, reversals excluded
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = sqlc.arg(from_account_id)
+  AND to_account_id = sqlc.arg(to_account_id)
+  AND reversal_of IS NULL
+  AND created_at >= sqlc.arg(since)::timestamptz;
+
+-- name: GetTransferAmountStats :one
+-- Averages the amounts of the latest transfers the account sent, reversals excluded
+SELECT
+  COUNT(*)::bigint AS transfer_count,
+  COALESCE(AVG(amount), 0)::bigint AS average_amount
+FROM (
+  SELECT amount FROM transfers
+  WHERE from_account_id = sqlc.arg(from_account_id) AND reversal_of IS NULL
+  ORDER BY id DESC
+  LIMIT sqlc.arg(history)
+) recent;
+
+-- name: CreateFraudDecision :one
+INSERT INTO fraud_decisions (
+  username,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  decision,
+  rules
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListFraudDecisions :many
+SELECT * FROM fraud_decisions
+WHERE (sqlc.narg(decision)::varchar IS NULL OR decision = sqlc.narg(decision))
+  AND (sqlc.narg(username)::varchar IS NULL OR username = sqlc.narg(username))
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
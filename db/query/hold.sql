@@ -0,0 +1,55 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  account_id,
+  to_account_id,
+  amount,
+  description,
+  created_by,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListHolds :many
+SELECT * FROM holds
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: GetAccountHeldAmount :one
+-- Expired holds stop counting as soon as they expire, even before ExpireHolds marks them
+SELECT COALESCE(SUM(amount), 0)::bigint AS held
+FROM holds
+WHERE account_id = $1 AND status = 'active' AND expires_at > now();
+
+-- name: UpdateHoldStatus :one
+UPDATE holds
+SET
+  status = sqlc.arg(status),
+  captured_amount = COALESCE(sqlc.narg(captured_amount), captured_amount),
+  transfer_id = COALESCE(sqlc.narg(transfer_id), transfer_id),
+  updated_at = now()
+WHERE
+  id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ReleaseHold :one
+UPDATE holds
+SET status = 'released', updated_at = now()
+WHERE id = $1 AND status = 'active' AND expires_at > now()
+RETURNING *;
+
+-- name: ExpireHolds :execrows
+UPDATE holds
+SET status = 'expired', updated_at = now()
+WHERE status = 'active' AND expires_at <= now();
@@ -0,0 +1,50 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (
+  aggregate_type,
+  aggregate_id,
+  event_type,
+  payload
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetOutboxEvent :one
+SELECT * FROM outbox_events
+WHERE id = $1 LIMIT 1;
+
+-- name: ClaimOutboxEvents :many
+-- Leases the oldest pending events to one relay until claimed_until, so they are published without holding row locks.
+-- Concurrent relays skip the locked rows instead of waiting, and an expired lease is claimed again.
+UPDATE outbox_events
+SET claimed_until = sqlc.arg(claimed_until)
+WHERE id IN (
+  SELECT id FROM outbox_events
+  WHERE published_at IS NULL
+    AND dead_lettered_at IS NULL
+    AND (claimed_until IS NULL OR claimed_until < now())
+  ORDER BY id
+  LIMIT sqlc.arg(limit)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ReleaseOutboxEvents :exec
+UPDATE outbox_events
+SET claimed_until = NULL
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: MarkOutboxEventsPublished :exec
+UPDATE outbox_events
+SET published_at = now(), attempts = attempts + 1, claimed_until = NULL
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: RecordOutboxEventFailure :one
+-- The event is dead-lettered once it failed max_attempts times
+UPDATE outbox_events
+SET
+  attempts = attempts + 1,
+  last_error = sqlc.arg(last_error),
+  claimed_until = NULL,
+  dead_lettered_at = CASE WHEN attempts + 1 >= sqlc.arg(max_attempts)::int THEN now() END
+WHERE id = sqlc.arg(id)
+RETURNING *;
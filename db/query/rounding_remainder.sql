@@ -0,0 +1,13 @@
+-- name: CreateRoundingRemainder :one
+INSERT INTO rounding_remainders (
+  transfer_id,
+  currency,
+  amount
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: ListRoundingRemaindersByTransfer :many
+SELECT * FROM rounding_remainders
+WHERE transfer_id = $1
+ORDER BY id;
@@ -0,0 +1,53 @@
+-- name: CreateSanctionsCase :one
+INSERT INTO sanctions_cases (
+  action,
+  username,
+  account_id,
+  screened_name,
+  list_entry_id,
+  matched_name,
+  program,
+  score
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetSanctionsCase :one
+SELECT * FROM sanctions_cases
+WHERE id = $1 LIMIT 1;
+
+-- name: GetOpenSanctionsCase :one
+-- The open case of a user for a list entry and action, a repeated attempt reuses it instead of opening another
+SELECT * FROM sanctions_cases
+WHERE username = sqlc.arg(username)
+  AND list_entry_id = sqlc.arg(list_entry_id)
+  AND action = sqlc.arg(action)
+  AND status = 'open'
+ORDER BY id
+LIMIT 1;
+
+-- name: ListSanctionsCases :many
+SELECT * FROM sanctions_cases
+WHERE sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status)
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
+
+-- name: ReviewSanctionsCase :one
+-- Closes an open case, no row is returned when it was already reviewed
+UPDATE sanctions_cases
+SET
+  status = sqlc.arg(status),
+  reviewed_by = sqlc.arg(reviewed_by),
+  reason = sqlc.arg(reason),
+  reviewed_at = now()
+WHERE
+  id = sqlc.arg(id) AND status = 'open'
+RETURNING *;
+
+-- name: CountClearedSanctionsCases :one
+-- Counts the cases that cleared a name as a false positive of a list entry
+SELECT COUNT(*) FROM sanctions_cases
+WHERE screened_name = sqlc.arg(screened_name)
+  AND list_entry_id = sqlc.arg(list_entry_id)
+  AND status = 'cleared';
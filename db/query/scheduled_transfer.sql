@@ -0,0 +1,100 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  frequency,
+  next_run_at,
+  end_at,
+  remaining_runs,
+  anchor_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListDueScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE status = 'active' AND next_run_at <= $1
+ORDER BY next_run_at
+LIMIT $2;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  next_run_at = COALESCE(sqlc.narg(next_run_at), next_run_at),
+  anchor_at = COALESCE(sqlc.narg(next_run_at), anchor_at),
+  end_at = COALESCE(sqlc.narg(end_at), end_at),
+  remaining_runs = COALESCE(sqlc.narg(remaining_runs), remaining_runs),
+  status = COALESCE(sqlc.narg(status), status)
+WHERE
+  id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ClaimScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  next_run_at = sqlc.arg(new_next_run_at),
+  remaining_runs = remaining_runs - 1,
+  status = sqlc.arg(status)
+WHERE
+  id = sqlc.arg(id) AND status = 'active' AND next_run_at = sqlc.arg(next_run_at)
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+  scheduled_transfer_id,
+  transfer_id,
+  status,
+  error,
+  scheduled_for,
+  approval_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: FinishScheduledTransferRun :one
+-- Records the outcome of a claimed run, a run that was already finished isn't updated
+UPDATE scheduled_transfer_runs
+SET
+  status = $2,
+  transfer_id = $3,
+  error = $4,
+  approval_id = $5
+WHERE id = $1 AND status = 'claimed'
+RETURNING *;
+
+-- name: SettleScheduledTransferRun :execrows
+-- Records the outcome of the approval a pending run waits for
+UPDATE scheduled_transfer_runs
+SET
+  status = $2,
+  transfer_id = $3,
+  error = $4
+WHERE approval_id = $1 AND status = 'pending';
+
+-- name: ListStaleScheduledTransferRuns :many
+-- Runs still claimed since before created_at, their worker stopped before recording the outcome
+SELECT * FROM scheduled_transfer_runs
+WHERE status = 'claimed' AND created_at < $1
+ORDER BY id
+LIMIT $2;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
@@ -2,15 +2,27 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  status,
+  reversal_of,
+  from_currency,
+  to_currency,
+  to_amount,
+  exchange_rate,
+  initiated_by
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE 
@@ -18,4 +30,24 @@ WHERE
     to_account_id = $2
 ORDER BY id
 LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUserOutboundTotals :one
+-- Sums the transfers the user sent in the currency since the start of the current day and month,
+-- whichever account they were sent from. Reversals and the transfers they reversed are excluded,
+-- since neither left money out of the user's accounts.
+SELECT
+  COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('day', now())), 0)::bigint AS daily_total,
+  COALESCE(SUM(amount), 0)::bigint AS monthly_total
+FROM transfers
+WHERE initiated_by = sqlc.arg(username)
+  AND from_currency = sqlc.arg(currency)
+  AND reversal_of IS NULL
+  AND status <> 'reversed'
+  AND created_at >= date_trunc('month', now());
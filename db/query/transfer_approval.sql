@@ -0,0 +1,51 @@
+-- name: CreateTransferApproval :one
+INSERT INTO transfer_approvals (
+  from_account_id,
+  to_account_id,
+  amount,
+  to_amount,
+  exchange_rate,
+  rounding_remainder,
+  requested_by,
+  expires_at,
+  fraud_decision_id,
+  hold_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: GetTransferApproval :one
+SELECT * FROM transfer_approvals
+WHERE id = $1 LIMIT 1;
+
+-- name: GetTransferApprovalForUpdate :one
+SELECT * FROM transfer_approvals
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListTransferApprovals :many
+-- The queue is oldest first, so the approvals closest to expiring are reviewed first
+SELECT * FROM transfer_approvals
+WHERE sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status)
+ORDER BY id
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
+
+-- name: ReviewTransferApproval :one
+UPDATE transfer_approvals
+SET
+  status = sqlc.arg(status),
+  reviewed_by = sqlc.arg(reviewed_by),
+  reason = sqlc.arg(reason),
+  transfer_id = sqlc.narg(transfer_id),
+  reviewed_at = now(),
+  updated_at = now()
+WHERE
+  id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ExpireTransferApprovals :many
+UPDATE transfer_approvals
+SET status = 'expired', updated_at = now()
+WHERE status = 'pending' AND expires_at <= now()
+RETURNING *;
@@ -0,0 +1,39 @@
+-- name: ListTransferLimits :many
+-- Returns the role defaults and the user overrides, optionally of a single currency
+SELECT * FROM transfer_limits
+WHERE (username = sqlc.arg(username) OR role = sqlc.arg(role))
+  AND (sqlc.narg(currency)::varchar IS NULL OR currency = sqlc.narg(currency))
+ORDER BY currency, username NULLS FIRST;
+
+-- name: UpsertRoleTransferLimit :one
+INSERT INTO transfer_limits (
+  role,
+  currency,
+  max_single,
+  max_daily,
+  max_monthly
+) VALUES (
+  $1, $2, $3, $4, $5
+) ON CONFLICT (role, currency) WHERE username IS NULL DO UPDATE
+SET max_single = EXCLUDED.max_single,
+    max_daily = EXCLUDED.max_daily,
+    max_monthly = EXCLUDED.max_monthly,
+    updated_at = now()
+RETURNING *;
+
+-- name: UpsertUserTransferLimit :one
+INSERT INTO transfer_limits (
+  username,
+  currency,
+  max_single,
+  max_daily,
+  max_monthly
+) VALUES (
+  $1, $2, $3, $4, $5
+) ON CONFLICT (username, currency) WHERE role IS NULL DO UPDATE
+SET max_single = EXCLUDED.max_single,
+    max_daily = EXCLUDED.max_daily,
+    max_monthly = EXCLUDED.max_monthly,
+    updated_at = now()
+RETURNING *;
+
@@ -12,6 +12,11 @@ INSERT INTO users (
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
 
+-- name: GetUserForUpdate :one
+SELECT * FROM users
+WHERE username = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: UpdateUser :one
 UPDATE users
 SET
@@ -38,4 +43,32 @@ SET
   email = EXCLUDED.email, 
   full_name = EXCLUDED.full_name,
   hashed_password = EXCLUDED.hashed_password
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: ListUsersByRole :many
+SELECT * FROM users
+WHERE role = $1
+ORDER BY username;
+
+-- name: ListUsers :many
+SELECT * FROM users
+WHERE (sqlc.narg(role)::varchar IS NULL OR role = sqlc.narg(role))
+  AND (sqlc.narg(search)::varchar IS NULL
+    OR username ILIKE '%' || sqlc.narg(search) || '%'
+    OR full_name ILIKE '%' || sqlc.narg(search) || '%'
+    OR email ILIKE '%' || sqlc.narg(search) || '%')
+ORDER BY username
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = sqlc.arg(role)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: UpdateUserLock :one
+UPDATE users
+SET is_locked = sqlc.arg(is_locked)
+WHERE username = sqlc.arg(username)
+RETURNING *;
@@ -0,0 +1,110 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+  owner,
+  url,
+  secret,
+  event_types
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListWebhookSubscriptionsForEvent :many
+-- Active subscriptions to the event type of the active members of any of the accounts
+SELECT * FROM webhook_subscriptions
+WHERE is_active
+  AND sqlc.arg(event_type)::varchar = ANY(event_types)
+  AND owner IN (
+    SELECT m.username FROM account_members m
+    WHERE m.account_id = ANY(sqlc.arg(account_ids)::bigint[]) AND m.status = 'active'
+  )
+ORDER BY id;
+
+-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: EnableWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET is_active = true, consecutive_failures = 0, disabled_at = NULL, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordWebhookSubscriptionFailure :one
+-- Disables the subscription once max_failures deliveries failed in a row
+UPDATE webhook_subscriptions
+SET
+  consecutive_failures = consecutive_failures + 1,
+  is_active = is_active AND consecutive_failures + 1 < sqlc.arg(max_failures)::int,
+  disabled_at = CASE
+    WHEN is_active AND consecutive_failures + 1 >= sqlc.arg(max_failures)::int THEN now()
+    ELSE disabled_at
+  END,
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ResetWebhookSubscriptionFailures :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0, updated_at = now()
+WHERE id = $1 AND consecutive_failures > 0;
+
+-- name: CreateWebhookDelivery :one
+-- A relayed event may be published twice, the second time returns the existing delivery
+INSERT INTO webhook_deliveries (
+  subscription_id,
+  event_id,
+  event_type
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (subscription_id, event_id) DO UPDATE
+SET updated_at = webhook_deliveries.updated_at
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListStaleWebhookDeliveries :many
+-- Deliveries still pending since before updated_at, their task may never have been queued
+SELECT * FROM webhook_deliveries
+WHERE status = 'pending' AND updated_at < $1
+ORDER BY id
+LIMIT $2;
+
+-- name: RecordWebhookDeliveryAttempt :one
+UPDATE webhook_deliveries
+SET
+  status = sqlc.arg(status),
+  attempts = attempts + 1,
+  response_status = sqlc.narg(response_status),
+  last_error = sqlc.narg(last_error),
+  delivered_at = CASE WHEN sqlc.arg(status) = 'succeeded' THEN now() ELSE delivered_at END,
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ResetWebhookDelivery :one
+-- Sends a settled delivery again, no row is returned while the delivery is still pending
+UPDATE webhook_deliveries
+SET status = 'pending', redeliveries = redeliveries + 1, updated_at = now()
+WHERE id = $1 AND status <> 'pending'
+RETURNING *;
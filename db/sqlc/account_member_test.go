@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func inviteRandomMember(t *testing.T, account Account, role string) AccountMember {
+	user := createRandomUser(t)
+	arg := CreateAccountMemberParams{
+		AccountID: account.ID,
+		Username:  user.Username,
+		Role:      role,
+		Status:    util.MemberStatusInvited,
+		InvitedBy: account.Owner,
+	}
+	member, err := testStore.CreateAccountMember(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, member.Username)
+	require.Equal(t, arg.Role, member.Role)
+	require.Equal(t, util.MemberStatusInvited, member.Status)
+	require.False(t, member.AcceptedAt.Valid)
+	return member
+}
+
+func TestCreateAccountTxOwner(t *testing.T) {
+	account := createRandomAccount(t)
+
+	member, err := testStore.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.MemberRoleOwner, member.Role)
+	require.Equal(t, util.MemberStatusActive, member.Status)
+	require.True(t, member.AcceptedAt.Valid)
+}
+
+func TestAcceptAccountMember(t *testing.T) {
+	account := createRandomAccount(t)
+	invited := inviteRandomMember(t, account, util.MemberRoleViewOnly)
+
+	invitations, err := testStore.ListAccountInvitations(context.Background(), invited.Username)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	require.Equal(t, account.ID, invitations[0].AccountID)
+
+	// A pending invitation doesn't list the account yet
+	arg := ListAccountsParams{Username: invited.Username, Limit: 5}
+	accounts, err := testStore.ListAccounts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+
+	member, err := testStore.AcceptAccountMember(context.Background(), AcceptAccountMemberParams{
+		AccountID: account.ID,
+		Username:  invited.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.MemberStatusActive, member.Status)
+	require.True(t, member.AcceptedAt.Valid)
+
+	accounts, err = testStore.ListAccounts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account.ID, accounts[0].ID)
+
+	// An accepted invitation can't be accepted again
+	_, err = testStore.AcceptAccountMember(context.Background(), AcceptAccountMemberParams{
+		AccountID: account.ID,
+		Username:  invited.Username,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestUpdateAndDeleteAccountMember(t *testing.T) {
+	account := createRandomAccount(t)
+	invited := inviteRandomMember(t, account, util.MemberRoleViewOnly)
+
+	member, err := testStore.UpdateAccountMemberRole(context.Background(), UpdateAccountMemberRoleParams{
+		Role:      util.MemberRoleCanTransfer,
+		AccountID: account.ID,
+		Username:  invited.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.MemberRoleCanTransfer, member.Role)
+
+	members, err := testStore.ListAccountMembers(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	require.Equal(t, account.Owner, members[0].Username)
+
+	err = testStore.DeleteAccountMember(context.Background(), DeleteAccountMemberParams{
+		AccountID: account.ID,
+		Username:  invited.Username,
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  invited.Username,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestListWebhookSubscriptionsForEventMembers(t *testing.T) {
+	account := createRandomAccount(t)
+	invited := inviteRandomMember(t, account, util.MemberRoleViewOnly)
+	subscription := createRandomWebhook(t, invited.Username, EventTransferCreated)
+
+	arg := ListWebhookSubscriptionsForEventParams{
+		EventType:  EventTransferCreated,
+		AccountIds: []int64{account.ID},
+	}
+	subscriptions, err := testStore.ListWebhookSubscriptionsForEvent(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, subscriptions)
+
+	_, err = testStore.AcceptAccountMember(context.Background(), AcceptAccountMemberParams{
+		AccountID: account.ID,
+		Username:  invited.Username,
+	})
+	require.NoError(t, err)
+
+	subscriptions, err = testStore.ListWebhookSubscriptionsForEvent(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []WebhookSubscription{subscription}, subscriptions)
+}
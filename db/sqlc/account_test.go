@@ -16,7 +16,7 @@ func createRandomAccount(t *testing.T) Account {
 		Balance:  util.RandomMoney(),    // Random money
 		Currency: util.RandomCurrency(), // Random currency
 	}
-	account, err := testStore.CreateAccount(context.Background(), arg)
+	account, err := testStore.CreateAccountTx(context.Background(), arg)
 	require.NoError(t, err)
 	require.NotEmpty(t, account)
 	require.Equal(t, arg.Owner, account.Owner)
@@ -58,13 +58,28 @@ func TestUpdateAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt.Time, account2.CreatedAt.Time, time.Second)
 }
 
-func TestDeleteAccount(t *testing.T) {
-	account1 := createRandomAccount(t)
-	err := testStore.DeleteAccount(context.Background(), account1.ID)
+func TestChangeAccountStatusTx(t *testing.T) {
+	account := createRandomAccount(t)
+
+	frozen, err := testStore.ChangeAccountStatusTx(context.Background(), UpdateAccountStatusParams{
+		Status:       util.AccountStatusFrozen,
+		ID:           account.ID,
+		FromStatuses: []string{util.AccountStatusActive},
+	})
 	require.NoError(t, err)
-	account2, err := testStore.GetAccount(context.Background(), account1.ID)
-	require.Error(t, err)
-	require.Empty(t, account2)
+	require.Equal(t, util.AccountStatusFrozen, frozen.Status)
+
+	// The account is no longer active, so a change expecting it to be active is refused
+	_, err = testStore.ChangeAccountStatusTx(context.Background(), UpdateAccountStatusParams{
+		Status:       util.AccountStatusClosed,
+		ID:           account.ID,
+		FromStatuses: []string{util.AccountStatusActive},
+	})
+	require.ErrorIs(t, err, ErrAccountStatusChanged)
+
+	unchanged, err := testStore.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.AccountStatusFrozen, unchanged.Status)
 }
 
 func TestListAccounts(t *testing.T) {
@@ -73,9 +88,9 @@ func TestListAccounts(t *testing.T) {
 		lastAccount = createRandomAccount(t)
 	}
 	arg := ListAccountsParams{
-		Owner:  lastAccount.Owner,
-		Limit:  5,
-		Offset: 0,
+		Username: lastAccount.Owner,
+		Limit:    5,
+		Offset:   0,
 	}
 	accounts, err := testStore.ListAccounts(context.Background(), arg)
 	require.NoError(t, err)
@@ -87,3 +102,53 @@ func TestListAccounts(t *testing.T) {
 		require.Equal(t, lastAccount.Owner, account.Owner)
 	}
 }
+
+func TestListAccountsAfter(t *testing.T) {
+	account := createRandomAccount(t)
+	for i := 0; i < 3; i++ {
+		createRandomAccount(t)
+	}
+
+	accounts, err := testStore.ListAccountsAfter(context.Background(), ListAccountsAfterParams{
+		ID:    account.ID,
+		Limit: 3,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	for _, a := range accounts {
+		require.Greater(t, a.ID, account.ID)
+	}
+}
+
+func TestListAccountsAfterSkipsCashAndClosedAccounts(t *testing.T) {
+	account := createRandomAccount(t)
+	closed := createRandomAccount(t)
+
+	_, err := testStore.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status:       util.AccountStatusClosed,
+		ID:           closed.ID,
+		FromStatuses: []string{util.AccountStatusActive},
+	})
+	require.NoError(t, err)
+
+	accounts, err := testStore.ListAccountsAfter(context.Background(), ListAccountsAfterParams{
+		ID:    account.ID - 1,
+		Limit: 2,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, accounts)
+	require.Equal(t, account.ID, accounts[0].ID)
+	for _, a := range accounts {
+		require.NotEqual(t, closed.ID, a.ID)
+	}
+
+	// The cash accounts are seeded by the migrations, so they come first
+	accounts, err = testStore.ListAccountsAfter(context.Background(), ListAccountsAfterParams{
+		ID:    0,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	for _, a := range accounts {
+		require.NotEqual(t, util.CashAccountOwner, a.Owner)
+	}
+}
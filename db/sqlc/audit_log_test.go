@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAuditLog(t *testing.T, actor string) AuditLog {
+	arg := CreateAuditLogParams{
+		Actor:        actor,
+		ActorRole:    util.BankerRole,
+		Ip:           "127.0.0.1",
+		UserAgent:    "go-test",
+		Method:       http.MethodPatch,
+		Route:        "/api/auth/users/update",
+		ResourceType: "users",
+		ResourceID:   pgtype.Text{String: util.RandomOwner(), Valid: true},
+		StatusCode:   http.StatusOK,
+		Request:      []byte(`{"password":"[REDACTED]"}`),
+		Changes:      []byte(`{"email":{"after":"b@example.com","before":"a@example.com"}}`),
+	}
+
+	entry, err := testStore.CreateAuditLog(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Actor, entry.Actor)
+	require.Equal(t, arg.ResourceID, entry.ResourceID)
+	require.JSONEq(t, string(arg.Changes), string(entry.Changes))
+	return entry
+}
+
+func TestListAuditLogs(t *testing.T) {
+	actor := util.RandomOwner()
+	entry1 := createRandomAuditLog(t, actor)
+	entry2 := createRandomAuditLog(t, actor)
+	createRandomAuditLog(t, util.RandomOwner())
+
+	entries, err := testStore.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		Actor:     pgtype.Text{String: actor, Valid: true},
+		PageLimit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, entry2.ID, entries[0].ID)
+	require.Equal(t, entry1.ID, entries[1].ID)
+
+	entries, err = testStore.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		ResourceType: pgtype.Text{String: "users", Valid: true},
+		ResourceID:   entry1.ResourceID,
+		PageLimit:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, entry1.ID, entries[0].ID)
+}
+
+func TestAuditLogIsAppendOnly(t *testing.T) {
+	entry := createRandomAuditLog(t, util.RandomOwner())
+	pool := testStore.(*SQLStore).connPool
+
+	_, err := pool.Exec(context.Background(), "UPDATE audit_log SET actor = 'someone_else' WHERE id = $1", entry.ID)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = pool.Exec(context.Background(), "DELETE FROM audit_log WHERE id = $1", entry.ID)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = pool.Exec(context.Background(), "TRUNCATE audit_log")
+	require.ErrorContains(t, err, "append-only")
+}
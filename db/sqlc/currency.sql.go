@@ -7,16 +7,146 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createCurrencyRate = `-- name: CreateCurrencyRate :one
+INSERT INTO currency_rates (
+  currency,
+  rate,
+  effective_at
+) VALUES (
+  $1, $2, $3
+) RETURNING id, currency, rate, effective_at, created_at
+`
+
+type CreateCurrencyRateParams struct {
+	Currency    string         `json:"currency"`
+	Rate        pgtype.Numeric `json:"rate"`
+	EffectiveAt time.Time      `json:"effective_at"`
+}
+
+func (q *Queries) CreateCurrencyRate(ctx context.Context, arg CreateCurrencyRateParams) (CurrencyRate, error) {
+	row := q.db.QueryRow(ctx, createCurrencyRate, arg.Currency, arg.Rate, arg.EffectiveAt)
+	var i CurrencyRate
+	err := row.Scan(
+		&i.ID,
+		&i.Currency,
+		&i.Rate,
+		&i.EffectiveAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getExchangeRate = `-- name: GetExchangeRate :one
 SELECT rate FROM currencies
 WHERE currency = $1 LIMIT 1
 `
 
-func (q *Queries) GetExchangeRate(ctx context.Context, currency string) (float64, error) {
+func (q *Queries) GetExchangeRate(ctx context.Context, currency string) (pgtype.Numeric, error) {
 	row := q.db.QueryRow(ctx, getExchangeRate, currency)
-	var rate float64
+	var rate pgtype.Numeric
 	err := row.Scan(&rate)
 	return rate, err
 }
+
+const listCurrencies = `-- name: ListCurrencies :many
+SELECT currency, rate, created_at FROM currencies
+ORDER BY currency
+`
+
+func (q *Queries) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	rows, err := q.db.Query(ctx, listCurrencies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Currency{}
+	for rows.Next() {
+		var i Currency
+		if err := rows.Scan(
+			&i.Currency,
+			&i.Rate,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCurrencyRateHistory = `-- name: ListCurrencyRateHistory :many
+SELECT id, currency, rate, effective_at, created_at FROM currency_rates
+WHERE currency = $1
+ORDER BY effective_at DESC, id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListCurrencyRateHistoryParams struct {
+	Currency string `json:"currency"`
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
+}
+
+func (q *Queries) ListCurrencyRateHistory(ctx context.Context, arg ListCurrencyRateHistoryParams) ([]CurrencyRate, error) {
+	rows, err := q.db.Query(ctx, listCurrencyRateHistory, arg.Currency, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CurrencyRate{}
+	for rows.Next() {
+		var i CurrencyRate
+		if err := rows.Scan(
+			&i.ID,
+			&i.Currency,
+			&i.Rate,
+			&i.EffectiveAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertCurrency = `-- name: UpsertCurrency :one
+INSERT INTO currencies (
+  currency,
+  rate
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (currency) DO UPDATE SET
+  rate = EXCLUDED.rate,
+  created_at = now()
+RETURNING currency, rate, created_at
+`
+
+type UpsertCurrencyParams struct {
+	Currency string         `json:"currency"`
+	Rate     pgtype.Numeric `json:"rate"`
+}
+
+func (q *Queries) UpsertCurrency(ctx context.Context, arg UpsertCurrencyParams) (Currency, error) {
+	row := q.db.QueryRow(ctx, upsertCurrency, arg.Currency, arg.Rate)
+	var i Currency
+	err := row.Scan(
+		&i.Currency,
+		&i.Rate,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrencyRateHistory(t *testing.T) {
+	currency := util.RandomString(3)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		var rate pgtype.Numeric
+		require.NoError(t, rate.Scan("32.155"))
+
+		created, err := testStore.CreateCurrencyRate(context.Background(), CreateCurrencyRateParams{
+			Currency:    currency,
+			Rate:        rate,
+			EffectiveAt: now.Add(time.Duration(i) * time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, currency, created.Currency)
+		require.NotZero(t, created.ID)
+	}
+
+	rates, err := testStore.ListCurrencyRateHistory(context.Background(), ListCurrencyRateHistoryParams{
+		Currency: currency,
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, rates, 3)
+
+	// The newest rate comes first
+	for i := 1; i < len(rates); i++ {
+		require.True(t, rates[i-1].EffectiveAt.After(rates[i].EffectiveAt))
+	}
+}
@@ -7,36 +7,73 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createCashEntry = `-- name: CreateCashEntry :one
+INSERT INTO entries (
+  account_id,
+  amount,
+  cash_transaction_id
+) VALUES (
+  $1, $2, $3
+) RETURNING id, account_id, amount, created_at, transfer_id, cash_transaction_id
+`
+
+type CreateCashEntryParams struct {
+	AccountID         int64       `json:"account_id"`
+	Amount            int64       `json:"amount"`
+	CashTransactionID pgtype.Int8 `json:"cash_transaction_id"`
+}
+
+func (q *Queries) CreateCashEntry(ctx context.Context, arg CreateCashEntryParams) (Entry, error) {
+	row := q.db.QueryRow(ctx, createCashEntry, arg.AccountID, arg.Amount, arg.CashTransactionID)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferID,
+		&i.CashTransactionID,
+	)
+	return i, err
+}
+
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3
+) RETURNING id, account_id, amount, created_at, transfer_id, cash_transaction_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64       `json:"amount"`
+	AccountID  int64       `json:"account_id"`
+	Amount     int64       `json:"amount"`
+	TransferID pgtype.Int8 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.CashTransactionID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, cash_transaction_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,12 +85,103 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.CashTransactionID,
 	)
 	return i, err
 }
 
+const listAccountEntries = `-- name: ListAccountEntries :many
+WITH page AS (
+  SELECT id, account_id, amount, transfer_id, cash_transaction_id, created_at
+  FROM entries
+  WHERE account_id = $1
+    AND ($2::timestamptz IS NULL OR created_at >= $2)
+    AND ($3::timestamptz IS NULL OR created_at < $3)
+    AND ($4::varchar IS NULL
+      OR ($4 = 'credit' AND amount > 0)
+      OR ($4 = 'debit' AND amount < 0))
+    AND ($5::bigint IS NULL OR id < $5)
+  ORDER BY id DESC
+  LIMIT $6
+), later AS (
+  SELECT id, SUM(amount) OVER (ORDER BY id DESC) AS since_total
+  FROM entries
+  WHERE account_id = $1 AND id >= (SELECT MIN(id) FROM page)
+)
+SELECT
+  p.id,
+  p.account_id,
+  p.amount,
+  p.transfer_id,
+  p.cash_transaction_id,
+  p.created_at,
+  (a.balance - l.since_total + p.amount)::bigint AS running_balance
+FROM page p
+JOIN later l ON l.id = p.id
+JOIN accounts a ON a.id = p.account_id
+ORDER BY p.id DESC
+`
+
+type ListAccountEntriesParams struct {
+	AccountID int64              `json:"account_id"`
+	FromTime  pgtype.Timestamptz `json:"from_time"`
+	ToTime    pgtype.Timestamptz `json:"to_time"`
+	Direction pgtype.Text        `json:"direction"`
+	Cursor    pgtype.Int8        `json:"cursor"`
+	PageSize  int32              `json:"page_size"`
+}
+
+type ListAccountEntriesRow struct {
+	ID                int64       `json:"id"`
+	AccountID         int64       `json:"account_id"`
+	Amount            int64       `json:"amount"`
+	TransferID        pgtype.Int8 `json:"transfer_id"`
+	CashTransactionID pgtype.Int8 `json:"cash_transaction_id"`
+	CreatedAt         time.Time   `json:"created_at"`
+	RunningBalance    int64       `json:"running_balance"`
+}
+
+// running_balance is the account balance right after each entry. It is
+// derived from the current balance minus the entries posted after it, so a
+// page only reads the entries from its oldest line onwards.
+func (q *Queries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listAccountEntries,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.Cursor,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountEntriesRow{}
+	for rows.Next() {
+		var i ListAccountEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.TransferID,
+			&i.CashTransactionID,
+			&i.CreatedAt,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, cash_transaction_id FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -62,8 +190,8 @@ OFFSET $3
 
 type ListEntriesParams struct {
 	AccountID int64 `json:"account_id"`
-	Limit     int32       `json:"limit"`
-	Offset    int32       `json:"offset"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
@@ -80,6 +208,124 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.CashTransactionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesByTransfer = `-- name: ListEntriesByTransfer :many
+SELECT id, account_id, amount, created_at, transfer_id, cash_transaction_id FROM entries
+WHERE transfer_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListEntriesByTransfer(ctx context.Context, transferID pgtype.Int8) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesByTransfer, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.CashTransactionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+WITH period AS (
+  SELECT id, amount, transfer_id, cash_transaction_id, created_at
+  FROM entries
+  WHERE account_id = $1
+    AND created_at >= $2::timestamptz
+    AND created_at < $3::timestamptz
+), later AS (
+  SELECT id, SUM(amount) OVER (ORDER BY id DESC) AS since_total
+  FROM entries
+  WHERE account_id = $1 AND id >= (SELECT MIN(id) FROM period)
+)
+SELECT
+  p.id,
+  p.amount,
+  p.transfer_id,
+  p.created_at,
+  (a.balance - l.since_total + p.amount)::bigint AS running_balance,
+  t.from_account_id,
+  t.to_account_id,
+  t.reversal_of,
+  p.cash_transaction_id,
+  c.reference AS cash_reference
+FROM period p
+JOIN later l ON l.id = p.id
+JOIN accounts a ON a.id = $1
+LEFT JOIN transfers t ON t.id = p.transfer_id
+LEFT JOIN cash_transactions c ON c.id = p.cash_transaction_id
+ORDER BY p.id
+`
+
+type ListStatementEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+type ListStatementEntriesRow struct {
+	ID                int64       `json:"id"`
+	Amount            int64       `json:"amount"`
+	TransferID        pgtype.Int8 `json:"transfer_id"`
+	CreatedAt         time.Time   `json:"created_at"`
+	RunningBalance    int64       `json:"running_balance"`
+	FromAccountID     pgtype.Int8 `json:"from_account_id"`
+	ToAccountID       pgtype.Int8 `json:"to_account_id"`
+	ReversalOf        pgtype.Int8 `json:"reversal_of"`
+	CashTransactionID pgtype.Int8 `json:"cash_transaction_id"`
+	CashReference     pgtype.Text `json:"cash_reference"`
+}
+
+// Every entry of the account in [from_time, to_time), oldest first, with the
+// transfer it belongs to and the balance right after it.
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementEntriesRow{}
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.TransferID,
+			&i.CreatedAt,
+			&i.RunningBalance,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.ReversalOf,
+			&i.CashTransactionID,
+			&i.CashReference,
 		); err != nil {
 			return nil, err
 		}
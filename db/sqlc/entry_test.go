@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
 
@@ -61,3 +62,117 @@ func TestListEntries(t *testing.T) {
 		require.Equal(t, arg.AccountID, entry.AccountID)
 	}
 }
+
+func TestListAccountEntries(t *testing.T) {
+	account := createRandomAccount(t)
+
+	// Post entries the way transfers do, so the account balance matches its ledger
+	var posted []Entry
+	for i := 0; i < 6; i++ {
+		amount := util.RandomMoney()
+		if i%2 == 1 {
+			amount = -amount
+		}
+		entry, err := testStore.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+		require.NoError(t, err)
+		account, err = testStore.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+			Amount: amount,
+			ID:     account.ID,
+		})
+		require.NoError(t, err)
+		posted = append(posted, entry)
+	}
+
+	firstPage, err := testStore.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account.ID,
+		PageSize:  4,
+	})
+	require.NoError(t, err)
+	require.Len(t, firstPage, 4)
+
+	// Newest first, and the newest line carries the current balance
+	require.Equal(t, posted[5].ID, firstPage[0].ID)
+	require.Equal(t, account.Balance, firstPage[0].RunningBalance)
+	for i := 1; i < len(firstPage); i++ {
+		require.Equal(t, firstPage[i-1].RunningBalance-firstPage[i-1].Amount, firstPage[i].RunningBalance)
+	}
+
+	secondPage, err := testStore.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account.ID,
+		Cursor:    pgtype.Int8{Int64: firstPage[3].ID, Valid: true},
+		PageSize:  4,
+	})
+	require.NoError(t, err)
+	require.Len(t, secondPage, 2)
+	require.Equal(t, posted[1].ID, secondPage[0].ID)
+	require.Equal(t, firstPage[3].RunningBalance-firstPage[3].Amount, secondPage[0].RunningBalance)
+
+	debits, err := testStore.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account.ID,
+		Direction: pgtype.Text{String: "debit", Valid: true},
+		PageSize:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, debits, 3)
+
+	// Filtered lines still carry the balance after them, counting the credits left out of the page
+	balanceAfter := make(map[int64]int64)
+	balance := account.Balance
+	for i := len(posted) - 1; i >= 0; i-- {
+		balanceAfter[posted[i].ID] = balance
+		balance -= posted[i].Amount
+	}
+	for _, entry := range debits {
+		require.Negative(t, entry.Amount)
+		require.Equal(t, balanceAfter[entry.ID], entry.RunningBalance)
+	}
+
+	future, err := testStore.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account.ID,
+		FromTime:  pgtype.Timestamptz{Time: time.Now().Add(24 * time.Hour), Valid: true},
+		PageSize:  10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, future)
+}
+
+func TestListStatementEntries(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	start := time.Now().Add(-time.Minute)
+	transfer := createRandomTransfer(t, account1, account2)
+	entry, err := testStore.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID:  account1.ID,
+		Amount:     -transfer.Amount,
+		TransferID: pgtype.Int8{Int64: transfer.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	account1, err = testStore.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		Amount: -transfer.Amount,
+		ID:     account1.ID,
+	})
+	require.NoError(t, err)
+
+	opening, err := testStore.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{
+		AtTime:    start,
+		AccountID: account1.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance+transfer.Amount, opening)
+
+	rows, err := testStore.ListStatementEntries(context.Background(), ListStatementEntriesParams{
+		AccountID: account1.ID,
+		FromTime:  start,
+		ToTime:    time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, entry.ID, rows[0].ID)
+	require.Equal(t, account1.Balance, rows[0].RunningBalance)
+	require.Equal(t, account2.ID, rows[0].ToAccountID.Int64)
+	require.False(t, rows[0].ReversalOf.Valid)
+}
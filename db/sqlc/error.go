@@ -8,12 +8,27 @@ import (
 )
 
 const (
-	ForeignKeyViolation = "23503"
-	UniqueViolation     = "23505"
+	ForeignKeyViolation  = "23503"
+	UniqueViolation      = "23505"
+	SerializationFailure = "40001"
+	DeadlockDetected     = "40P01"
 )
 
 var ErrRecordNotFound = pgx.ErrNoRows
 var ErrInsufficientBalance = errors.New("your account balance is insufficient")
+var ErrTransferNotReversible = errors.New("only completed transfers can be reversed")
+var ErrTransferEntriesNotFound = errors.New("transfer has no ledger entries to reverse")
+var ErrAccountNotActive = errors.New("frozen or closed accounts can neither send nor receive")
+var ErrCashAccountTransfer = errors.New("the bank's cash accounts only move money through cash deposits and withdrawals")
+var ErrAccountBalanceChanged = errors.New("account balance changed while closing, please retry")
+var ErrAccountStatusChanged = errors.New("account status changed concurrently, please retry")
+var ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+var ErrHoldNotActive = errors.New("hold has already been captured, released or expired")
+var ErrHoldAmountExceeded = errors.New("capture amount exceeds the hold")
+var ErrAccountHasHolds = errors.New("account has active holds, capture or release them first")
+var ErrApprovalNotPending = errors.New("transfer approval has already been reviewed or expired")
+var ErrSelfApproval = errors.New("a transfer can't be reviewed by the user who requested it")
+var ErrScheduledRunFinished = errors.New("scheduled transfer run has already been finished")
 
 var ErrUniqueViolation = &pgconn.PgError{
 	Code: UniqueViolation,
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+	since := time.Now().Add(-time.Minute)
+
+	createRandomTransfer(t, account1, account2)
+	createRandomTransfer(t, account1, account2)
+	createRandomTransfer(t, account1, account3)
+
+	count, err := testStore.CountTransfersFrom(context.Background(), CountTransfersFromParams{
+		FromAccountID: account1.ID,
+		Since:         since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+
+	count, err = testStore.CountTransfersFrom(context.Background(), CountTransfersFromParams{
+		FromAccountID: account1.ID,
+		Since:         time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	count, err = testStore.CountTransfersBetween(context.Background(), CountTransfersBetweenParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Since:         since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	count, err = testStore.CountTransfersBetween(context.Background(), CountTransfersBetweenParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestGetTransferAmountStats(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	stats, err := testStore.GetTransferAmountStats(context.Background(), GetTransferAmountStatsParams{
+		FromAccountID: account1.ID,
+		History:       20,
+	})
+	require.NoError(t, err)
+	require.Zero(t, stats.TransferCount)
+	require.Zero(t, stats.AverageAmount)
+
+	var total int64
+	for i := 0; i < 3; i++ {
+		total += createRandomTransfer(t, account1, account2).Amount
+	}
+
+	stats, err = testStore.GetTransferAmountStats(context.Background(), GetTransferAmountStatsParams{
+		FromAccountID: account1.ID,
+		History:       20,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), stats.TransferCount)
+	require.InDelta(t, float64(total)/3, float64(stats.AverageAmount), 1)
+
+	stats, err = testStore.GetTransferAmountStats(context.Background(), GetTransferAmountStatsParams{
+		FromAccountID: account1.ID,
+		History:       2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stats.TransferCount)
+}
+
+func createRandomFraudDecision(t *testing.T, account1, account2 Account, decision string) FraudDecision {
+	arg := CreateFraudDecisionParams{
+		Username:      account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        util.RandomMoney(),
+		Currency:      account1.Currency,
+		Decision:      decision,
+		Rules:         []byte(`[{"rule":"new_payee","decision":"allow"}]`),
+	}
+
+	fraudDecision, err := testStore.CreateFraudDecision(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, fraudDecision.ID)
+	require.Equal(t, arg.Username, fraudDecision.Username)
+	require.Equal(t, arg.Amount, fraudDecision.Amount)
+	require.Equal(t, arg.Decision, fraudDecision.Decision)
+	require.JSONEq(t, string(arg.Rules), string(fraudDecision.Rules))
+	require.NotZero(t, fraudDecision.CreatedAt)
+	return fraudDecision
+}
+
+func TestListFraudDecisions(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	allowed := createRandomFraudDecision(t, account1, account2, "allow")
+	blocked := createRandomFraudDecision(t, account1, account2, "block")
+
+	decisions, err := testStore.ListFraudDecisions(context.Background(), ListFraudDecisionsParams{
+		Username:   pgtype.Text{String: account1.Owner, Valid: true},
+		PageLimit:  5,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+	require.Equal(t, blocked.ID, decisions[0].ID)
+	require.Equal(t, allowed.ID, decisions[1].ID)
+
+	decisions, err = testStore.ListFraudDecisions(context.Background(), ListFraudDecisionsParams{
+		Decision:   pgtype.Text{String: "block", Valid: true},
+		Username:   pgtype.Text{String: account1.Owner, Valid: true},
+		PageLimit:  5,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	require.Equal(t, blocked.ID, decisions[0].ID)
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomIdempotencyKey(t *testing.T, user User) IdempotencyKey {
+	arg := CreateIdempotencyKeyParams{
+		Username:       user.Username,
+		IdempotencyKey: util.RandomID(),
+		RequestPath:    "/api/auth/transfers",
+		RequestHash:    util.RandomString(64),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+	record, err := testStore.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, record)
+	require.Equal(t, arg.Username, record.Username)
+	require.Equal(t, arg.IdempotencyKey, record.IdempotencyKey)
+	require.Equal(t, arg.RequestPath, record.RequestPath)
+	require.Equal(t, arg.RequestHash, record.RequestHash)
+	require.False(t, record.ResponseCode.Valid)
+	require.Empty(t, record.ResponseBody)
+	require.NotZero(t, record.CreatedAt)
+	require.WithinDuration(t, arg.ExpiresAt, record.ExpiresAt, time.Second)
+	return record
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	record := createRandomIdempotencyKey(t, user)
+
+	_, err := testStore.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Username:       record.Username,
+		IdempotencyKey: record.IdempotencyKey,
+		RequestPath:    record.RequestPath,
+		RequestHash:    record.RequestHash,
+		ExpiresAt:      record.ExpiresAt,
+	})
+	require.Error(t, err)
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+}
+
+func TestUpdateIdempotencyKeyResponse(t *testing.T) {
+	user := createRandomUser(t)
+	record1 := createRandomIdempotencyKey(t, user)
+
+	arg := UpdateIdempotencyKeyResponseParams{
+		ResponseCode: pgtype.Int4{
+			Int32: http.StatusOK,
+			Valid: true,
+		},
+		ResponseBody:   []byte(`{"id":1}`),
+		Username:       record1.Username,
+		IdempotencyKey: record1.IdempotencyKey,
+	}
+	_, err := testStore.UpdateIdempotencyKeyResponse(context.Background(), arg)
+	require.NoError(t, err)
+
+	record2, err := testStore.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       record1.Username,
+		IdempotencyKey: record1.IdempotencyKey,
+	})
+	require.NoError(t, err)
+	require.Equal(t, record1.RequestHash, record2.RequestHash)
+	require.Equal(t, arg.ResponseCode, record2.ResponseCode)
+	require.Equal(t, arg.ResponseBody, record2.ResponseBody)
+}
+
+func TestDeleteIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	record1 := createRandomIdempotencyKey(t, user)
+
+	err := testStore.DeleteIdempotencyKey(context.Background(), DeleteIdempotencyKeyParams{
+		Username:       record1.Username,
+		IdempotencyKey: record1.IdempotencyKey,
+	})
+	require.NoError(t, err)
+
+	record2, err := testStore.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       record1.Username,
+		IdempotencyKey: record1.IdempotencyKey,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+	require.Empty(t, record2)
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	user := createRandomUser(t)
+	live := createRandomIdempotencyKey(t, user)
+
+	expired, err := testStore.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Username:       user.Username,
+		IdempotencyKey: util.RandomID(),
+		RequestPath:    "/api/auth/transfers",
+		RequestHash:    util.RandomString(64),
+		ExpiresAt:      time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	deleted, err := testStore.DeleteExpiredIdempotencyKeys(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	_, err = testStore.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       expired.Username,
+		IdempotencyKey: expired.IdempotencyKey,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = testStore.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       live.Username,
+		IdempotencyKey: live.IdempotencyKey,
+	})
+	require.NoError(t, err)
+}
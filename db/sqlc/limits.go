@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// EffectiveTransferLimit is the limit applied to a user's transfers in one currency, an invalid field means unlimited
+type EffectiveTransferLimit struct {
+	Currency   string      `json:"currency"`
+	MaxSingle  pgtype.Int8 `json:"max_single"`
+	MaxDaily   pgtype.Int8 `json:"max_daily"`
+	MaxMonthly pgtype.Int8 `json:"max_monthly"`
+}
+
+// EffectiveTransferLimits merges the role defaults and the user overrides listed by ListTransferLimits.
+// An override replaces the default field by field, so an unset override field keeps the role default
+// and an override of 0 makes it unlimited.
+func EffectiveTransferLimits(limits []TransferLimit) []EffectiveTransferLimit {
+	effective := []EffectiveTransferLimit{}
+	index := map[string]int{}
+
+	for _, limit := range limits {
+		i, ok := index[limit.Currency]
+		if !ok {
+			i = len(effective)
+			index[limit.Currency] = i
+			effective = append(effective, EffectiveTransferLimit{Currency: limit.Currency})
+		}
+
+		current := &effective[i]
+		override := limit.Username.Valid
+		mergeTransferLimit(&current.MaxSingle, limit.MaxSingle, override)
+		mergeTransferLimit(&current.MaxDaily, limit.MaxDaily, override)
+		mergeTransferLimit(&current.MaxMonthly, limit.MaxMonthly, override)
+	}
+
+	return effective
+}
+
+// mergeTransferLimit applies a limit to the effective one, an override of 0 lifts the role default
+func mergeTransferLimit(current *pgtype.Int8, limit pgtype.Int8, override bool) {
+	switch {
+	case !limit.Valid:
+	case override && limit.Int64 == 0:
+		*current = pgtype.Int8{}
+	case override || !current.Valid:
+		*current = limit
+	}
+}
+
+// checkTransferLimit rejects amount if it would break the limits of username, the user sending it from account.
+// Their totals are the transfers they initiated in the currency from any account, and their user row is locked
+// first so their concurrent transfers can't both fit under the same total.
+func checkTransferLimit(ctx context.Context, q *Queries, username string, account Account, amount int64) error {
+	user, err := q.GetUserForUpdate(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	limits, err := q.ListTransferLimits(ctx, ListTransferLimitsParams{
+		Username: pgtype.Text{String: user.Username, Valid: true},
+		Role:     pgtype.Text{String: user.Role, Valid: true},
+		Currency: pgtype.Text{String: account.Currency, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	effective := EffectiveTransferLimits(limits)
+	if len(effective) == 0 {
+		return nil
+	}
+	limit := effective[0]
+
+	if limit.MaxSingle.Valid && amount > limit.MaxSingle.Int64 {
+		return fmt.Errorf("%w: a single transfer can't exceed %d", ErrTransferLimitExceeded, limit.MaxSingle.Int64)
+	}
+	if !limit.MaxDaily.Valid && !limit.MaxMonthly.Valid {
+		return nil
+	}
+
+	totals, err := q.GetUserOutboundTotals(ctx, GetUserOutboundTotalsParams{
+		Currency: account.Currency,
+		Username: user.Username,
+	})
+	if err != nil {
+		return err
+	}
+	if limit.MaxDaily.Valid && totals.DailyTotal+amount > limit.MaxDaily.Int64 {
+		return fmt.Errorf("%w: %d of the daily %d already sent", ErrTransferLimitExceeded, totals.DailyTotal, limit.MaxDaily.Int64)
+	}
+	if limit.MaxMonthly.Valid && totals.MonthlyTotal+amount > limit.MaxMonthly.Int64 {
+		return fmt.Errorf("%w: %d of the monthly %d already sent", ErrTransferLimitExceeded, totals.MonthlyTotal, limit.MaxMonthly.Int64)
+	}
+
+	return nil
+}
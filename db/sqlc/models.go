@@ -16,27 +16,232 @@ type Account struct {
 	Balance   int64            `json:"balance"`
 	Currency  string           `json:"currency"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
+	Status    string           `json:"status"`
+}
+
+type AccountMember struct {
+	AccountID  int64              `json:"account_id"`
+	Username   string             `json:"username"`
+	Role       string             `json:"role"`
+	Status     string             `json:"status"`
+	InvitedBy  string             `json:"invited_by"`
+	AcceptedAt pgtype.Timestamptz `json:"accepted_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID           int64       `json:"id"`
+	Actor        string      `json:"actor"`
+	ActorRole    string      `json:"actor_role"`
+	Ip           string      `json:"ip"`
+	UserAgent    string      `json:"user_agent"`
+	Method       string      `json:"method"`
+	Route        string      `json:"route"`
+	ResourceType string      `json:"resource_type"`
+	ResourceID   pgtype.Text `json:"resource_id"`
+	StatusCode   int32       `json:"status_code"`
+	Request      []byte      `json:"request"`
+	Changes      []byte      `json:"changes"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+type CashTransaction struct {
+	ID            int64     `json:"id"`
+	AccountID     int64     `json:"account_id"`
+	CashAccountID int64     `json:"cash_account_id"`
+	Type          string    `json:"type"`
+	Amount        int64     `json:"amount"`
+	Reference     string    `json:"reference"`
+	Reason        string    `json:"reason"`
+	Banker        string    `json:"banker"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type Currency struct {
 	Currency  string           `json:"currency"`
-	Rate      float64          `json:"rate"`
+	Rate      pgtype.Numeric   `json:"rate"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 }
 
+type CurrencyRate struct {
+	ID          int64          `json:"id"`
+	Currency    string         `json:"currency"`
+	Rate        pgtype.Numeric `json:"rate"`
+	EffectiveAt time.Time      `json:"effective_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
 type Entry struct {
-	ID        int64            `json:"id"`
-	AccountID int64     `json:"account_id"`
-	Amount    int64            `json:"amount"`
-	CreatedAt pgtype.Timestamp `json:"created_at"`
+	ID                int64            `json:"id"`
+	AccountID         int64            `json:"account_id"`
+	Amount            int64            `json:"amount"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	TransferID        pgtype.Int8      `json:"transfer_id"`
+	CashTransactionID pgtype.Int8      `json:"cash_transaction_id"`
 }
 
-type Transfer struct {
-	ID            int64            `json:"id"`
+type FraudDecision struct {
+	ID            int64     `json:"id"`
+	Username      string    `json:"username"`
 	FromAccountID int64     `json:"from_account_id"`
 	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	Decision      string    `json:"decision"`
+	Rules         []byte    `json:"rules"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type Hold struct {
+	ID             int64       `json:"id"`
+	AccountID      int64       `json:"account_id"`
+	ToAccountID    int64       `json:"to_account_id"`
+	Amount         int64       `json:"amount"`
+	CapturedAmount pgtype.Int8 `json:"captured_amount"`
+	Status         string      `json:"status"`
+	Description    string      `json:"description"`
+	CreatedBy      string      `json:"created_by"`
+	TransferID     pgtype.Int8 `json:"transfer_id"`
+	ExpiresAt      time.Time   `json:"expires_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username       string      `json:"username"`
+	IdempotencyKey string      `json:"idempotency_key"`
+	RequestPath    string      `json:"request_path"`
+	RequestHash    string      `json:"request_hash"`
+	ResponseCode   pgtype.Int4 `json:"response_code"`
+	ResponseBody   []byte      `json:"response_body"`
+	CreatedAt      time.Time   `json:"created_at"`
+	ExpiresAt      time.Time   `json:"expires_at"`
+}
+
+type OutboxEvent struct {
+	ID             int64              `json:"id"`
+	AggregateType  string             `json:"aggregate_type"`
+	AggregateID    int64              `json:"aggregate_id"`
+	EventType      string             `json:"event_type"`
+	Payload        []byte             `json:"payload"`
+	Attempts       int32              `json:"attempts"`
+	LastError      pgtype.Text        `json:"last_error"`
+	PublishedAt    pgtype.Timestamptz `json:"published_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	ClaimedUntil   pgtype.Timestamptz `json:"claimed_until"`
+	DeadLetteredAt pgtype.Timestamptz `json:"dead_lettered_at"`
+}
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type RolePermission struct {
+	Role       string `json:"role"`
+	Permission string `json:"permission"`
+	Scope      string `json:"scope"`
+}
+
+type RoundingRemainder struct {
+	ID         int64          `json:"id"`
+	TransferID int64          `json:"transfer_id"`
+	Currency   string         `json:"currency"`
+	Amount     pgtype.Numeric `json:"amount"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type SanctionsCase struct {
+	ID           int64              `json:"id"`
+	Action       string             `json:"action"`
+	Username     string             `json:"username"`
+	AccountID    pgtype.Int8        `json:"account_id"`
+	ScreenedName string             `json:"screened_name"`
+	ListEntryID  string             `json:"list_entry_id"`
+	MatchedName  string             `json:"matched_name"`
+	Program      string             `json:"program"`
+	Score        float64            `json:"score"`
+	Status       string             `json:"status"`
+	ReviewedBy   pgtype.Text        `json:"reviewed_by"`
+	Reason       pgtype.Text        `json:"reason"`
+	ReviewedAt   pgtype.Timestamptz `json:"reviewed_at"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64              `json:"id"`
+	Owner         string             `json:"owner"`
+	FromAccountID int64              `json:"from_account_id"`
+	ToAccountID   int64              `json:"to_account_id"`
+	Amount        int64              `json:"amount"`
+	Currency      string             `json:"currency"`
+	Frequency     string             `json:"frequency"`
+	NextRunAt     time.Time          `json:"next_run_at"`
+	EndAt         pgtype.Timestamptz `json:"end_at"`
+	RemainingRuns pgtype.Int4        `json:"remaining_runs"`
+	Status        string             `json:"status"`
+	CreatedAt     time.Time          `json:"created_at"`
+	AnchorAt      time.Time          `json:"anchor_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64       `json:"id"`
+	ScheduledTransferID int64       `json:"scheduled_transfer_id"`
+	TransferID          pgtype.Int8 `json:"transfer_id"`
+	Status              string      `json:"status"`
+	Error               pgtype.Text `json:"error"`
+	ScheduledFor        time.Time   `json:"scheduled_for"`
+	CreatedAt           time.Time   `json:"created_at"`
+	ApprovalID          pgtype.Int8 `json:"approval_id"`
+}
+
+type Transfer struct {
+	ID            int64            `json:"id"`
+	FromAccountID int64            `json:"from_account_id"`
+	ToAccountID   int64            `json:"to_account_id"`
 	Amount        int64            `json:"amount"`
 	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	Status        string           `json:"status"`
+	ReversalOf    pgtype.Int8      `json:"reversal_of"`
+	FromCurrency  string           `json:"from_currency"`
+	ToCurrency    string           `json:"to_currency"`
+	ToAmount      pgtype.Int8      `json:"to_amount"`
+	ExchangeRate  pgtype.Numeric   `json:"exchange_rate"`
+	InitiatedBy   pgtype.Text      `json:"initiated_by"`
+}
+
+type TransferApproval struct {
+	ID                int64              `json:"id"`
+	FromAccountID     int64              `json:"from_account_id"`
+	ToAccountID       int64              `json:"to_account_id"`
+	Amount            int64              `json:"amount"`
+	ToAmount          int64              `json:"to_amount"`
+	ExchangeRate      pgtype.Numeric     `json:"exchange_rate"`
+	RoundingRemainder pgtype.Numeric     `json:"rounding_remainder"`
+	Status            string             `json:"status"`
+	RequestedBy       string             `json:"requested_by"`
+	ReviewedBy        pgtype.Text        `json:"reviewed_by"`
+	Reason            pgtype.Text        `json:"reason"`
+	TransferID        pgtype.Int8        `json:"transfer_id"`
+	ExpiresAt         time.Time          `json:"expires_at"`
+	ReviewedAt        pgtype.Timestamptz `json:"reviewed_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+	CreatedAt         time.Time          `json:"created_at"`
+	HoldID            pgtype.Int8        `json:"hold_id"`
+	FraudDecisionID   pgtype.Int8        `json:"fraud_decision_id"`
+}
+
+type TransferLimit struct {
+	ID         int64       `json:"id"`
+	Role       pgtype.Text `json:"role"`
+	Username   pgtype.Text `json:"username"`
+	Currency   string      `json:"currency"`
+	MaxSingle  pgtype.Int8 `json:"max_single"`
+	MaxDaily   pgtype.Int8 `json:"max_daily"`
+	MaxMonthly pgtype.Int8 `json:"max_monthly"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	CreatedAt  time.Time   `json:"created_at"`
 }
 
 type User struct {
@@ -48,4 +253,33 @@ type User struct {
 	CreatedAt         time.Time `json:"created_at"`
 	Role              string    `json:"role"`
 	Provider          string    `json:"provider"`
+	IsLocked          bool      `json:"is_locked"`
+}
+
+type WebhookDelivery struct {
+	ID             int64              `json:"id"`
+	SubscriptionID int64              `json:"subscription_id"`
+	EventID        int64              `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	Redeliveries   int32              `json:"redeliveries"`
+	ResponseStatus pgtype.Int4        `json:"response_status"`
+	LastError      pgtype.Text        `json:"last_error"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID                  int64              `json:"id"`
+	Owner               string             `json:"owner"`
+	Url                 string             `json:"url"`
+	Secret              string             `json:"secret"`
+	EventTypes          []string           `json:"event_types"`
+	IsActive            bool               `json:"is_active"`
+	ConsecutiveFailures int32              `json:"consecutive_failures"`
+	DisabledAt          pgtype.Timestamptz `json:"disabled_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+	CreatedAt           time.Time          `json:"created_at"`
 }
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Types of the events written to the outbox, named after the aggregate they describe
+const (
+	EventTransferCreated        = "transfer.created"
+	EventTransferReversed       = "transfer.reversed"
+	EventEntryCreated           = "entry.created"
+	EventAccountCreated         = "account.created"
+	EventAccountBalanceChanged  = "account.balance_changed"
+	EventAccountStatusChanged   = "account.status_changed"
+	EventCashTransactionCreated = "cash_transaction.created"
+)
+
+// IsSupportedEventType returns true if the event type is written to the outbox
+func IsSupportedEventType(eventType string) bool {
+	switch eventType {
+	case EventTransferCreated, EventTransferReversed, EventEntryCreated, EventAccountCreated,
+		EventAccountBalanceChanged, EventAccountStatusChanged, EventCashTransactionCreated:
+		return true
+	}
+	return false
+}
+
+// recordEvent writes an event to the outbox within the transaction of q, so it is only published if the change commits
+func recordEvent(ctx context.Context, q *Queries, aggregateType string, aggregateID int64, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       data,
+	})
+	return err
+}
+
+// recordPosting writes the events of entries posted to the ledger and of the balances they changed
+func recordPosting(ctx context.Context, q *Queries, entries []Entry, accounts []Account) error {
+	for _, entry := range entries {
+		if err := recordEvent(ctx, q, "entry", entry.ID, EventEntryCreated, entry); err != nil {
+			return err
+		}
+	}
+
+	for _, account := range accounts {
+		if err := recordEvent(ctx, q, "account", account.ID, EventAccountBalanceChanged, account); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
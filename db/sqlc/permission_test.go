@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListRolePermissions(t *testing.T) {
+	rows, err := testStore.ListRolePermissions(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, rows)
+
+	scopes := map[string]map[string]string{}
+	for _, row := range rows {
+		if scopes[row.Role] == nil {
+			scopes[row.Role] = map[string]string{}
+		}
+		scopes[row.Role][row.Permission] = row.Scope
+	}
+
+	require.Equal(t, "own", scopes[util.DepositorRole]["transfer:create"])
+	require.Equal(t, "any", scopes[util.BankerRole]["transfer:reverse"])
+	require.NotContains(t, scopes[util.DepositorRole], "cash:post")
+}
@@ -6,27 +6,164 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AcceptAccountMember(ctx context.Context, arg AcceptAccountMemberParams) (AccountMember, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	// Leases the oldest pending events to one relay until claimed_until, so they are published without holding row locks.
+	// Concurrent relays skip the locked rows instead of waiting, and an expired lease is claimed again.
+	ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]OutboxEvent, error)
+	ClaimScheduledTransfer(ctx context.Context, arg ClaimScheduledTransferParams) (ScheduledTransfer, error)
+	// Counts the cases that cleared a name as a false positive of a list entry
+	CountClearedSanctionsCases(ctx context.Context, arg CountClearedSanctionsCasesParams) (int64, error)
+	// Counts the transfers from one account to another since the given time, reversals excluded
+	CountTransfersBetween(ctx context.Context, arg CountTransfersBetweenParams) (int64, error)
+	// Counts the transfers the account sent since the given time, reversals excluded
+	CountTransfersFrom(ctx context.Context, arg CountTransfersFromParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountIfNotExists(ctx context.Context, arg CreateAccountIfNotExistsParams) error
+	CreateAccountMember(ctx context.Context, arg CreateAccountMemberParams) (AccountMember, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	CreateCashEntry(ctx context.Context, arg CreateCashEntryParams) (Entry, error)
+	CreateCashTransaction(ctx context.Context, arg CreateCashTransactionParams) (CashTransaction, error)
+	CreateCurrencyRate(ctx context.Context, arg CreateCurrencyRateParams) (CurrencyRate, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFraudDecision(ctx context.Context, arg CreateFraudDecisionParams) (FraudDecision, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error)
+	CreateRoundingRemainder(ctx context.Context, arg CreateRoundingRemainderParams) (RoundingRemainder, error)
+	CreateSanctionsCase(ctx context.Context, arg CreateSanctionsCaseParams) (SanctionsCase, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferApproval(ctx context.Context, arg CreateTransferApprovalParams) (TransferApproval, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteAccount(ctx context.Context, id int64) error
+	// A relayed event may be published twice, the second time returns the existing delivery
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	DeleteAccountMember(ctx context.Context, arg DeleteAccountMemberParams) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
+	EnableWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	ExpireHolds(ctx context.Context) (int64, error)
+	ExpireTransferApprovals(ctx context.Context) ([]TransferApproval, error)
+	// Records the outcome of a claimed run, a run that was already finished isn't updated
+	FinishScheduledTransferRun(ctx context.Context, arg FinishScheduledTransferRunParams) (ScheduledTransferRun, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	// Balance the account held at the given time, derived backwards from the current balance.
+	GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error)
+	GetAccountByOwner(ctx context.Context, arg GetAccountByOwnerParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	// Expired holds stop counting as soon as they expire, even before ExpireHolds marks them
+	GetAccountHeldAmount(ctx context.Context, accountID int64) (int64, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	GetCashTransaction(ctx context.Context, id int64) (CashTransaction, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
-	GetExchangeRate(ctx context.Context, currency string) (float64, error)
+	GetExchangeRate(ctx context.Context, currency string) (pgtype.Numeric, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	// The open case of a user for a list entry and action, a repeated attempt reuses it instead of opening another
+	GetOpenSanctionsCase(ctx context.Context, arg GetOpenSanctionsCaseParams) (SanctionsCase, error)
+	GetOutboxEvent(ctx context.Context, id int64) (OutboxEvent, error)
+	GetSanctionsCase(ctx context.Context, id int64) (SanctionsCase, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	// Averages the amounts of the latest transfers the account sent, reversals excluded
+	GetTransferAmountStats(ctx context.Context, arg GetTransferAmountStatsParams) (GetTransferAmountStatsRow, error)
+	GetTransferApproval(ctx context.Context, id int64) (TransferApproval, error)
+	GetTransferApprovalForUpdate(ctx context.Context, id int64) (TransferApproval, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	// Sums the transfers the user sent in the currency since the start of the current day and month,
+	// whichever account they were sent from. Reversals and the transfers they reversed are excluded,
+	// since neither left money out of the user's accounts.
+	GetUserOutboundTotals(ctx context.Context, arg GetUserOutboundTotalsParams) (GetUserOutboundTotalsRow, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	// running_balance is the account balance right after each entry. It is
+	// derived from the current balance minus the entries posted after it, so a
+	// page only reads the entries from its oldest line onwards.
+	ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error)
+	// Pending invitations of the user, the oldest first
+	ListAccountInvitations(ctx context.Context, username string) ([]AccountMember, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
+	// Accounts the user is an active member of
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	// Customer accounts that are still open, the bank's cash accounts are left out.
+	ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	// Accounts whose balance isn't the sum of their entries
+	ListBalanceDrifts(ctx context.Context) ([]ListBalanceDriftsRow, error)
+	ListCashTransactions(ctx context.Context, arg ListCashTransactionsParams) ([]CashTransaction, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListCurrencyRateHistory(ctx context.Context, arg ListCurrencyRateHistoryParams) ([]CurrencyRate, error)
+	ListDueScheduledTransfers(ctx context.Context, arg ListDueScheduledTransfersParams) ([]ScheduledTransfer, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesByTransfer(ctx context.Context, transferID pgtype.Int8) ([]Entry, error)
+	ListFraudDecisions(ctx context.Context, arg ListFraudDecisionsParams) ([]FraudDecision, error)
+	ListHolds(ctx context.Context, arg ListHoldsParams) ([]Hold, error)
+	ListRolePermissions(ctx context.Context) ([]RolePermission, error)
+	ListRoundingRemaindersByTransfer(ctx context.Context, transferID int64) ([]RoundingRemainder, error)
+	ListSanctionsCases(ctx context.Context, arg ListSanctionsCasesParams) ([]SanctionsCase, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	// Runs still claimed since before created_at, their worker stopped before recording the outcome
+	ListStaleScheduledTransferRuns(ctx context.Context, arg ListStaleScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	// Deliveries still pending since before updated_at, their task may never have been queued
+	ListStaleWebhookDeliveries(ctx context.Context, arg ListStaleWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	// Every entry of the account in [from_time, to_time), oldest first, with the
+	// transfer it belongs to and the balance right after it.
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	// The queue is oldest first, so the approvals closest to expiring are reviewed first
+	ListTransferApprovals(ctx context.Context, arg ListTransferApprovalsParams) ([]TransferApproval, error)
+	// Transfers without exactly a debit of the sender and a credit of the receiver matching their amounts
+	ListTransferEntryMismatches(ctx context.Context) ([]ListTransferEntryMismatchesRow, error)
+	// Returns the role defaults and the user overrides, optionally of a single currency
+	ListTransferLimits(ctx context.Context, arg ListTransferLimitsParams) ([]TransferLimit, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListUsersByRole(ctx context.Context, role string) ([]User, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptions(ctx context.Context, arg ListWebhookSubscriptionsParams) ([]WebhookSubscription, error)
+	// Active subscriptions to the event type of the active members of any of the accounts
+	ListWebhookSubscriptionsForEvent(ctx context.Context, arg ListWebhookSubscriptionsForEventParams) ([]WebhookSubscription, error)
+	MarkOutboxEventsPublished(ctx context.Context, ids []int64) error
+	// The event is dead-lettered once it failed max_attempts times
+	RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) (OutboxEvent, error)
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) (WebhookDelivery, error)
+	// Disables the subscription once max_failures deliveries failed in a row
+	RecordWebhookSubscriptionFailure(ctx context.Context, arg RecordWebhookSubscriptionFailureParams) (WebhookSubscription, error)
+	ReleaseHold(ctx context.Context, id int64) (Hold, error)
+	ReleaseOutboxEvents(ctx context.Context, ids []int64) error
+	// Sends a settled delivery again, no row is returned while the delivery is still pending
+	ResetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	ResetWebhookSubscriptionFailures(ctx context.Context, id int64) error
+	// Closes an open case, no row is returned when it was already reviewed
+	ReviewSanctionsCase(ctx context.Context, arg ReviewSanctionsCaseParams) (SanctionsCase, error)
+	ReviewTransferApproval(ctx context.Context, arg ReviewTransferApprovalParams) (TransferApproval, error)
+	// Records the outcome of the approval a pending run waits for
+	SettleScheduledTransferRun(ctx context.Context, arg SettleScheduledTransferRunParams) (int64, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountMemberRole(ctx context.Context, arg UpdateAccountMemberRoleParams) (AccountMember, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) (IdempotencyKey, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserLock(ctx context.Context, arg UpdateUserLockParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpsertCurrency(ctx context.Context, arg UpsertCurrencyParams) (Currency, error)
+	UpsertRoleTransferLimit(ctx context.Context, arg UpsertRoleTransferLimitParams) (TransferLimit, error)
 	UpsertUser(ctx context.Context, arg UpsertUserParams) (User, error)
+	UpsertUserTransferLimit(ctx context.Context, arg UpsertUserTransferLimitParams) (TransferLimit, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func findBalanceDrift(t *testing.T, accountID int64) (ListBalanceDriftsRow, bool) {
+	drifts, err := testStore.ListBalanceDrifts(context.Background())
+	require.NoError(t, err)
+
+	for _, drift := range drifts {
+		if drift.ID == accountID {
+			return drift, true
+		}
+	}
+	return ListBalanceDriftsRow{}, false
+}
+
+func findTransferMismatch(t *testing.T, transferID int64) (ListTransferEntryMismatchesRow, bool) {
+	mismatches, err := testStore.ListTransferEntryMismatches(context.Background())
+	require.NoError(t, err)
+
+	for _, mismatch := range mismatches {
+		if mismatch.ID == transferID {
+			return mismatch, true
+		}
+	}
+	return ListTransferEntryMismatchesRow{}, false
+}
+
+func TestListBalanceDrifts(t *testing.T) {
+	// Random accounts are created with a balance but no entries
+	account := createRandomAccount(t)
+
+	drift, ok := findBalanceDrift(t, account.ID)
+	require.True(t, ok)
+	require.Equal(t, account.Owner, drift.Owner)
+	require.Equal(t, account.Balance, drift.Balance)
+	require.Zero(t, drift.EntriesTotal)
+
+	_, err := testStore.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: account.ID,
+		Amount:    account.Balance,
+	})
+	require.NoError(t, err)
+
+	_, ok = findBalanceDrift(t, account.ID)
+	require.False(t, ok)
+}
+
+func TestListTransferEntryMismatches(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	amount := int64(10)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    amount,
+		ToAmount:      amount,
+	})
+	require.NoError(t, err)
+
+	_, ok := findTransferMismatch(t, result.Transfer.ID)
+	require.False(t, ok)
+
+	// A transfer posted without its entries
+	transfer := createRandomTransfer(t, account1, account2)
+
+	mismatch, ok := findTransferMismatch(t, transfer.ID)
+	require.True(t, ok)
+	require.Equal(t, transfer.Amount, mismatch.Amount)
+	require.Zero(t, mismatch.EntryCount)
+	require.Zero(t, mismatch.FromTotal)
+	require.Zero(t, mismatch.ToTotal)
+}
+
+// execBackfills runs the UPDATE statements of a migration, the ones that backfill the rows it migrates
+func execBackfills(t *testing.T, store *SQLStore, migration string) {
+	data, err := os.ReadFile("../migration/" + migration)
+	require.NoError(t, err)
+
+	for _, statement := range strings.Split(string(data), ";\n") {
+		var lines []string
+		for _, line := range strings.Split(statement, "\n") {
+			if !strings.HasPrefix(line, "--") {
+				lines = append(lines, line)
+			}
+		}
+		statement = strings.TrimSpace(strings.Join(lines, "\n"))
+		if !strings.HasPrefix(statement, "UPDATE") {
+			continue
+		}
+
+		_, err = store.connPool.Exec(context.Background(), statement)
+		require.NoError(t, err)
+	}
+}
+
+func TestBackfillLegacyTransferEntries(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	store := testStore.(*SQLStore)
+
+	// A cross-currency transfer posted before entries were linked to it and received amounts were recorded
+	var transfer Transfer
+	var entries [2]Entry
+	err := store.execTx(context.Background(), func(q *Queries) error {
+		var err error
+		transfer, err = q.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+			Status:        util.TransferStatusCompleted,
+			FromCurrency:  account1.Currency,
+			ToCurrency:    account2.Currency,
+		})
+		if err != nil {
+			return err
+		}
+		entries[0], err = q.CreateEntry(context.Background(), CreateEntryParams{AccountID: account1.ID, Amount: -10})
+		if err != nil {
+			return err
+		}
+		entries[1], err = q.CreateEntry(context.Background(), CreateEntryParams{AccountID: account2.ID, Amount: 310})
+		return err
+	})
+	require.NoError(t, err)
+
+	_, ok := findTransferMismatch(t, transfer.ID)
+	require.True(t, ok)
+
+	execBackfills(t, store, "000027_backfill_entries_transfer_id.up.sql")
+	execBackfills(t, store, "000028_backfill_transfers_to_amount.up.sql")
+
+	for _, entry := range entries {
+		entry, err = testStore.GetEntry(context.Background(), entry.ID)
+		require.NoError(t, err)
+		require.Equal(t, transfer.ID, entry.TransferID.Int64)
+	}
+
+	transfer, err = testStore.GetTransfer(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(310), transfer.ToAmount.Int64)
+
+	_, ok = findTransferMismatch(t, transfer.ID)
+	require.False(t, ok)
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RelayOutboxParams contains the input parameters of the outbox relay
+type RelayOutboxParams struct {
+	Limit int32 `json:"limit"`
+	// Lease is how long the claimed events are hidden from other relays while they are published
+	Lease time.Duration `json:"lease"`
+	// MaxAttempts dead-letters an event once publishing it failed that many times
+	MaxAttempts int32 `json:"max_attempts"`
+	// Publish hands one event to the downstream publisher
+	Publish func(ctx context.Context, event OutboxEvent) error `json:"-"`
+}
+
+// RelayOutboxResult is the result of the outbox relay
+type RelayOutboxResult struct {
+	// Claimed is the size of the batch, a short batch means the outbox is drained
+	Claimed   int `json:"claimed"`
+	Published int `json:"published"`
+	// Failed is the event the publisher rejected, the relay stops there and releases the rest of the batch
+	Failed *OutboxEvent `json:"failed,omitempty"`
+}
+
+// RelayOutbox claims the oldest pending events, publishes them by id and marks them as published.
+// No transaction is open while the publisher is called, so a slow broker holds no row locks.
+// An event published right before its relay crashed is published again once the lease expires,
+// so consumers must deduplicate on the event id. The order across batches isn't kept: concurrent
+// relays publish their batches side by side, and a released or expired batch goes out after newer events.
+func (store *SQLStore) RelayOutbox(ctx context.Context, arg RelayOutboxParams) (RelayOutboxResult, error) {
+	var result RelayOutboxResult
+
+	events, err := store.ClaimOutboxEvents(ctx, ClaimOutboxEventsParams{
+		ClaimedUntil: time.Now().Add(arg.Lease),
+		Limit:        arg.Limit,
+	})
+	if err != nil {
+		return result, err
+	}
+	slices.SortFunc(events, func(a, b OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+	result.Claimed = len(events)
+
+	published := make([]int64, 0, len(events))
+	for i := range events {
+		event := events[i]
+		if err := arg.Publish(ctx, event); err != nil {
+			failed, err := store.RecordOutboxEventFailure(ctx, RecordOutboxEventFailureParams{
+				LastError:   pgtype.Text{String: err.Error(), Valid: true},
+				MaxAttempts: arg.MaxAttempts,
+				ID:          event.ID,
+			})
+			if err != nil {
+				return result, err
+			}
+			result.Failed = &failed
+
+			// The rest of the batch goes back to the queue behind the failed event
+			rest := make([]int64, 0, len(events)-i-1)
+			for _, event := range events[i+1:] {
+				rest = append(rest, event.ID)
+			}
+			if len(rest) > 0 {
+				if err := store.ReleaseOutboxEvents(ctx, rest); err != nil {
+					return result, err
+				}
+			}
+			break
+		}
+		published = append(published, event.ID)
+	}
+
+	result.Published = len(published)
+	if len(published) == 0 {
+		return result, nil
+	}
+	return result, store.MarkOutboxEventsPublished(ctx, published)
+}
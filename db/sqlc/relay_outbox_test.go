@@ -0,0 +1,186 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// relayAll drains the outbox and returns every event published
+func relayAll(t *testing.T) []OutboxEvent {
+	var events []OutboxEvent
+	for {
+		result, err := testStore.RelayOutbox(context.Background(), RelayOutboxParams{
+			Limit:       100,
+			Lease:       time.Minute,
+			MaxAttempts: 10,
+			Publish: func(ctx context.Context, event OutboxEvent) error {
+				events = append(events, event)
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		require.Nil(t, result.Failed)
+		if result.Claimed < 100 {
+			return events
+		}
+	}
+}
+
+func TestTransferTxOutboxEvents(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	relayAll(t)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.NoError(t, err)
+
+	published := make(map[string][]int64)
+	for _, event := range relayAll(t) {
+		published[event.EventType] = append(published[event.EventType], event.AggregateID)
+	}
+	require.Contains(t, published[EventTransferCreated], result.Transfer.ID)
+	require.Contains(t, published[EventEntryCreated], result.FromEntry.ID)
+	require.Contains(t, published[EventEntryCreated], result.ToEntry.ID)
+	require.Contains(t, published[EventAccountBalanceChanged], account1.ID)
+	require.Contains(t, published[EventAccountBalanceChanged], account2.ID)
+
+	// published events are never relayed again
+	require.Empty(t, relayAll(t))
+}
+
+func TestCreateAccountTxOutboxEvent(t *testing.T) {
+	relayAll(t)
+	account := createRandomAccount(t)
+
+	var created []int64
+	for _, event := range relayAll(t) {
+		if event.EventType == EventAccountCreated {
+			created = append(created, event.AggregateID)
+		}
+	}
+	require.Equal(t, []int64{account.ID}, created)
+}
+
+func TestRelayOutboxFailure(t *testing.T) {
+	relayAll(t)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.NoError(t, err)
+
+	publishErr := errors.New("broker unavailable")
+	var first OutboxEvent
+	result, err := testStore.RelayOutbox(context.Background(), RelayOutboxParams{
+		Limit:       100,
+		Lease:       time.Minute,
+		MaxAttempts: 10,
+		Publish: func(ctx context.Context, event OutboxEvent) error {
+			if first.ID == 0 {
+				first = event
+				return nil
+			}
+			return publishErr
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Published)
+	require.NotNil(t, result.Failed)
+	require.Greater(t, result.Failed.ID, first.ID)
+	require.False(t, result.Failed.DeadLetteredAt.Valid)
+
+	// the relay resumes at the failed event, keeping the order
+	events := relayAll(t)
+	require.NotEmpty(t, events)
+	require.Equal(t, result.Failed.ID, events[0].ID)
+	require.Equal(t, int32(1), events[0].Attempts)
+	require.Equal(t, publishErr.Error(), events[0].LastError.String)
+}
+
+func TestRelayOutboxDeadLetter(t *testing.T) {
+	relayAll(t)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.RelayOutbox(context.Background(), RelayOutboxParams{
+		Limit:       100,
+		Lease:       time.Minute,
+		MaxAttempts: 1,
+		Publish: func(ctx context.Context, event OutboxEvent) error {
+			return errors.New("payload rejected")
+		},
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Published)
+	require.NotNil(t, result.Failed)
+	require.True(t, result.Failed.DeadLetteredAt.Valid)
+
+	// the dead-lettered event no longer blocks the ones behind it
+	events := relayAll(t)
+	require.NotEmpty(t, events)
+	for _, event := range events {
+		require.NotEqual(t, result.Failed.ID, event.ID)
+	}
+}
+
+func TestClaimOutboxEventsSkipsClaimed(t *testing.T) {
+	relayAll(t)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.NoError(t, err)
+
+	claimed, err := testStore.ClaimOutboxEvents(context.Background(), ClaimOutboxEventsParams{
+		ClaimedUntil: time.Now().Add(time.Minute),
+		Limit:        100,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, claimed)
+
+	// a second relay doesn't get the leased events
+	again, err := testStore.ClaimOutboxEvents(context.Background(), ClaimOutboxEventsParams{
+		ClaimedUntil: time.Now().Add(time.Minute),
+		Limit:        100,
+	})
+	require.NoError(t, err)
+	for _, event := range again {
+		for _, other := range claimed {
+			require.NotEqual(t, other.ID, event.ID)
+		}
+	}
+
+	ids := make([]int64, 0, len(claimed)+len(again))
+	for _, event := range append(claimed, again...) {
+		ids = append(ids, event.ID)
+	}
+	require.NoError(t, testStore.ReleaseOutboxEvents(context.Background(), ids))
+	require.NotEmpty(t, relayAll(t))
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxRoundingRemainder(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	var remainder pgtype.Numeric
+	require.NoError(t, remainder.Scan("-0.428571428571"))
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:     account1.ID,
+		ToAccountID:       account2.ID,
+		FromAmount:        10,
+		ToAmount:          9,
+		RoundingRemainder: remainder,
+	})
+	require.NoError(t, err)
+
+	remainders, err := testStore.ListRoundingRemaindersByTransfer(context.Background(), result.Transfer.ID)
+	require.NoError(t, err)
+	require.Len(t, remainders, 1)
+	require.Equal(t, account2.Currency, remainders[0].Currency)
+
+	got, err := remainders[0].Amount.Value()
+	require.NoError(t, err)
+	require.Equal(t, "-0.428571428571", got)
+}
+
+func TestTransferTxWithoutRoundingRemainder(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.NoError(t, err)
+
+	remainders, err := testStore.ListRoundingRemaindersByTransfer(context.Background(), result.Transfer.ID)
+	require.NoError(t, err)
+	require.Empty(t, remainders)
+}
+
+func TestReverseTransferTxRoundingRemainder(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	var remainder pgtype.Numeric
+	require.NoError(t, remainder.Scan("-0.428571428571"))
+
+	transferResult, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:     account1.ID,
+		ToAccountID:       account2.ID,
+		FromAmount:        10,
+		ToAmount:          9,
+		RoundingRemainder: remainder,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+	})
+	require.NoError(t, err)
+
+	// The reversal cancels the remainder of the original transfer
+	remainders, err := testStore.ListRoundingRemaindersByTransfer(context.Background(), result.ReversalTransfer.ID)
+	require.NoError(t, err)
+	require.Len(t, remainders, 1)
+	require.Equal(t, account2.Currency, remainders[0].Currency)
+
+	got, err := remainders[0].Amount.Value()
+	require.NoError(t, err)
+	require.Equal(t, "0.428571428571", got)
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSanctionsCase(t *testing.T, screenedName string) SanctionsCase {
+	account := createRandomAccount(t)
+
+	arg := CreateSanctionsCaseParams{
+		Action:       util.SanctionsActionTransfer,
+		Username:     util.RandomOwner(),
+		AccountID:    pgtype.Int8{Int64: account.ID, Valid: true},
+		ScreenedName: screenedName,
+		ListEntryID:  util.RandomString(6),
+		MatchedName:  util.RandomOwner(),
+		Program:      "SDGT",
+		Score:        0.95,
+	}
+
+	sanctionsCase, err := testStore.CreateSanctionsCase(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, sanctionsCase.ID)
+	require.Equal(t, arg.Action, sanctionsCase.Action)
+	require.Equal(t, arg.AccountID, sanctionsCase.AccountID)
+	require.Equal(t, arg.ScreenedName, sanctionsCase.ScreenedName)
+	require.Equal(t, arg.ListEntryID, sanctionsCase.ListEntryID)
+	require.Equal(t, arg.Score, sanctionsCase.Score)
+	require.Equal(t, util.SanctionsCaseStatusOpen, sanctionsCase.Status)
+	require.False(t, sanctionsCase.ReviewedBy.Valid)
+	require.NotZero(t, sanctionsCase.CreatedAt)
+	return sanctionsCase
+}
+
+func TestReviewSanctionsCase(t *testing.T) {
+	reviewer := createRandomUser(t)
+	sanctionsCase := createRandomSanctionsCase(t, util.RandomOwner())
+
+	cleared := CountClearedSanctionsCasesParams{
+		ScreenedName: sanctionsCase.ScreenedName,
+		ListEntryID:  sanctionsCase.ListEntryID,
+	}
+	count, err := testStore.CountClearedSanctionsCases(context.Background(), cleared)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	open := GetOpenSanctionsCaseParams{
+		Username:    sanctionsCase.Username,
+		ListEntryID: sanctionsCase.ListEntryID,
+		Action:      sanctionsCase.Action,
+	}
+	openCase, err := testStore.GetOpenSanctionsCase(context.Background(), open)
+	require.NoError(t, err)
+	require.Equal(t, sanctionsCase.ID, openCase.ID)
+
+	arg := ReviewSanctionsCaseParams{
+		Status:     util.SanctionsCaseStatusCleared,
+		ReviewedBy: pgtype.Text{String: reviewer.Username, Valid: true},
+		Reason:     pgtype.Text{String: util.RandomString(12), Valid: true},
+		ID:         sanctionsCase.ID,
+	}
+	reviewed, err := testStore.ReviewSanctionsCase(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.SanctionsCaseStatusCleared, reviewed.Status)
+	require.Equal(t, arg.ReviewedBy, reviewed.ReviewedBy)
+	require.Equal(t, arg.Reason, reviewed.Reason)
+	require.True(t, reviewed.ReviewedAt.Valid)
+
+	count, err = testStore.CountClearedSanctionsCases(context.Background(), cleared)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	_, err = testStore.GetOpenSanctionsCase(context.Background(), open)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	// a reviewed case can't be reviewed again
+	arg.Status = util.SanctionsCaseStatusConfirmed
+	_, err = testStore.ReviewSanctionsCase(context.Background(), arg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	fetched, err := testStore.GetSanctionsCase(context.Background(), sanctionsCase.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.SanctionsCaseStatusCleared, fetched.Status)
+}
+
+func TestListSanctionsCases(t *testing.T) {
+	first := createRandomSanctionsCase(t, util.RandomOwner())
+	second := createRandomSanctionsCase(t, util.RandomOwner())
+
+	cases, err := testStore.ListSanctionsCases(context.Background(), ListSanctionsCasesParams{
+		Status:     pgtype.Text{String: util.SanctionsCaseStatusOpen, Valid: true},
+		PageLimit:  50,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(cases), 2)
+	require.Equal(t, second.ID, cases[0].ID)
+	require.Equal(t, first.ID, cases[1].ID)
+
+	for _, sanctionsCase := range cases {
+		require.Equal(t, util.SanctionsCaseStatusOpen, sanctionsCase.Status)
+	}
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, account1, account2 Account, nextRunAt time.Time) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        util.RandomMoney(),
+		Currency:      account1.Currency,
+		Frequency:     util.FrequencyMonthly,
+		NextRunAt:     nextRunAt,
+		AnchorAt:      nextRunAt,
+		RemainingRuns: pgtype.Int4{Int32: 3, Valid: true},
+	}
+	scheduledTransfer, err := testStore.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, scheduledTransfer)
+	require.Equal(t, arg.Owner, scheduledTransfer.Owner)
+	require.Equal(t, arg.FromAccountID, scheduledTransfer.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduledTransfer.ToAccountID)
+	require.Equal(t, arg.Amount, scheduledTransfer.Amount)
+	require.Equal(t, arg.Frequency, scheduledTransfer.Frequency)
+	require.WithinDuration(t, arg.NextRunAt, scheduledTransfer.NextRunAt, time.Second)
+	require.WithinDuration(t, arg.AnchorAt, scheduledTransfer.AnchorAt, time.Second)
+	require.False(t, scheduledTransfer.EndAt.Valid)
+	require.Equal(t, arg.RemainingRuns, scheduledTransfer.RemainingRuns)
+	require.Equal(t, util.ScheduleStatusActive, scheduledTransfer.Status)
+	require.NotZero(t, scheduledTransfer.CreatedAt)
+	return scheduledTransfer
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	createRandomScheduledTransfer(t, account1, account2, time.Now().Add(time.Hour))
+}
+
+func TestListDueScheduledTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	due := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+	notDue := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(time.Hour))
+
+	scheduledTransfers, err := testStore.ListDueScheduledTransfers(context.Background(), ListDueScheduledTransfersParams{
+		NextRunAt: time.Now(),
+		Limit:     1000,
+	})
+	require.NoError(t, err)
+
+	ids := make(map[int64]bool)
+	for _, scheduledTransfer := range scheduledTransfers {
+		require.Equal(t, util.ScheduleStatusActive, scheduledTransfer.Status)
+		ids[scheduledTransfer.ID] = true
+	}
+	require.True(t, ids[due.ID])
+	require.False(t, ids[notDue.ID])
+}
+
+func TestClaimScheduledTransfer(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	arg := ClaimScheduledTransferParams{
+		NewNextRunAt: util.NextRunAt(scheduledTransfer.Frequency, scheduledTransfer.AnchorAt, scheduledTransfer.NextRunAt),
+		Status:       util.ScheduleStatusActive,
+		ID:           scheduledTransfer.ID,
+		NextRunAt:    scheduledTransfer.NextRunAt,
+	}
+	claimed, err := testStore.ClaimScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.WithinDuration(t, arg.NewNextRunAt, claimed.NextRunAt, time.Second)
+	require.WithinDuration(t, scheduledTransfer.AnchorAt, claimed.AnchorAt, time.Second)
+	require.Equal(t, scheduledTransfer.RemainingRuns.Int32-1, claimed.RemainingRuns.Int32)
+
+	// The same occurrence cannot be claimed twice
+	_, err = testStore.ClaimScheduledTransfer(context.Background(), arg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestScheduledTransferRunTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	arg := ClaimScheduledTransferParams{
+		NewNextRunAt: util.NextRunAt(scheduledTransfer.Frequency, scheduledTransfer.AnchorAt, scheduledTransfer.NextRunAt),
+		Status:       util.ScheduleStatusActive,
+		ID:           scheduledTransfer.ID,
+		NextRunAt:    scheduledTransfer.NextRunAt,
+	}
+	run, err := testStore.ClaimScheduledTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.ScheduleRunStatusClaimed, run.Status)
+	require.False(t, run.TransferID.Valid)
+
+	// The claimed run is left behind for a later sweep until it is finished
+	stale, err := testStore.ListStaleScheduledTransferRuns(context.Background(), ListStaleScheduledTransferRunsParams{
+		CreatedAt: time.Now().Add(time.Minute),
+		Limit:     1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, stale, run)
+
+	transferArg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+		Username:      account1.Owner,
+	}
+	result, err := testStore.PostScheduledTransferRunTx(context.Background(), PostScheduledTransferRunTxParams{
+		RunID:    run.ID,
+		Transfer: transferArg,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ScheduleRunStatusCompleted, result.Run.Status)
+	require.Equal(t, result.Transfer.ID, result.Run.TransferID.Int64)
+
+	// A run is posted once, the second transfer is rolled back
+	_, err = testStore.PostScheduledTransferRunTx(context.Background(), PostScheduledTransferRunTxParams{
+		RunID:    run.ID,
+		Transfer: transferArg,
+	})
+	require.ErrorIs(t, err, ErrScheduledRunFinished)
+
+	updated, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, updated.Balance)
+}
+
+func TestUpdateScheduledTransferStatus(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(time.Hour))
+
+	updated, err := testStore.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     scheduledTransfer.ID,
+		Status: pgtype.Text{String: util.ScheduleStatusCancelled, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ScheduleStatusCancelled, updated.Status)
+	require.Equal(t, scheduledTransfer.Amount, updated.Amount)
+	require.WithinDuration(t, scheduledTransfer.NextRunAt, updated.NextRunAt, time.Second)
+	require.WithinDuration(t, scheduledTransfer.AnchorAt, updated.AnchorAt, time.Second)
+}
+
+func TestUpdateScheduledTransferNextRunAtMovesAnchor(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(time.Hour))
+
+	nextRunAt := scheduledTransfer.NextRunAt.Add(48 * time.Hour)
+	updated, err := testStore.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:        scheduledTransfer.ID,
+		NextRunAt: pgtype.Timestamptz{Time: nextRunAt, Valid: true},
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, nextRunAt, updated.NextRunAt, time.Second)
+	require.WithinDuration(t, nextRunAt, updated.AnchorAt, time.Second)
+}
+
+func TestListScheduledTransferRuns(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now())
+	transfer := createRandomTransfer(t, account1, account2)
+
+	for i := 0; i < 5; i++ {
+		run, err := testStore.CreateScheduledTransferRun(context.Background(), CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduledTransfer.ID,
+			TransferID:          pgtype.Int8{Int64: transfer.ID, Valid: true},
+			Status:              util.ScheduleRunStatusCompleted,
+			ScheduledFor:        scheduledTransfer.NextRunAt,
+		})
+		require.NoError(t, err)
+		require.NotZero(t, run.ID)
+	}
+
+	runs, err := testStore.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               5,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 5)
+
+	for _, run := range runs {
+		require.Equal(t, scheduledTransfer.ID, run.ScheduledTransferID)
+		require.Equal(t, transfer.ID, run.TransferID.Int64)
+	}
+}
@@ -3,14 +3,32 @@ package db
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
+	"time"
 
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Store provides all functions to execute db queries and transactions
 type Store interface {
 	Querier
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error)
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	SyncRatesTx(ctx context.Context, arg SyncRatesTxParams) (SyncRatesTxResult, error)
+	CashTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error)
+	AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (Hold, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	ChangeAccountStatusTx(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	RelayOutbox(ctx context.Context, arg RelayOutboxParams) (RelayOutboxResult, error)
+	ReviewTransferApprovalTx(ctx context.Context, arg ReviewTransferApprovalTxParams) (ReviewTransferApprovalTxResult, error)
+	ExpireTransferApprovalsTx(ctx context.Context) ([]TransferApproval, error)
+	ClaimScheduledTransferTx(ctx context.Context, arg ClaimScheduledTransferParams) (ScheduledTransferRun, error)
+	PostScheduledTransferRunTx(ctx context.Context, arg PostScheduledTransferRunTxParams) (PostScheduledTransferRunTxResult, error)
 }
 
 // Store provides all functions to execute db queries and transactions
@@ -27,9 +45,62 @@ func NewStore(connPool *pgxpool.Pool) Store {
 	}
 }
 
-// execTx executes a function within a database transaction
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.connPool.Begin(ctx)
+// Bounds of the retries of a transaction failing with a serialization failure or a deadlock
+const (
+	defaultTxAttempts = 3
+	txRetryBaseDelay  = 20 * time.Millisecond
+	txRetryMaxDelay   = 500 * time.Millisecond
+)
+
+// TxOption configures a transaction run by execTx
+type TxOption func(*txConfig)
+
+type txConfig struct {
+	options     pgx.TxOptions
+	maxAttempts int
+}
+
+// WithIsolation runs the transaction at the given isolation level instead of the database default
+func WithIsolation(level pgx.TxIsoLevel) TxOption {
+	return func(config *txConfig) {
+		config.options.IsoLevel = level
+	}
+}
+
+// WithMaxAttempts bounds how many times the transaction runs when it keeps failing with a retryable error
+func WithMaxAttempts(attempts int) TxOption {
+	return func(config *txConfig) {
+		config.maxAttempts = max(attempts, 1)
+	}
+}
+
+// execTx executes a function within a database transaction.
+// A run failing with a serialization failure or a deadlock is rolled back and retried with a bounded
+// exponential backoff, so fn must be safe to run again from scratch.
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error, opts ...TxOption) error {
+	config := txConfig{maxAttempts: defaultTxAttempts}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = store.runTx(ctx, config.options, fn)
+		if err == nil || !isRetryable(err) || attempt >= config.maxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(retryDelay(attempt)):
+		}
+	}
+}
+
+// runTx runs fn once within a database transaction
+func (store *SQLStore) runTx(ctx context.Context, options pgx.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.connPool.BeginTx(ctx, options)
 	if err != nil {
 		return err
 	}
@@ -38,7 +109,7 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(ctx); rbErr != nil {
-			return fmt.Errorf("tx error: %v, rb error: %v", err, rbErr)
+			return fmt.Errorf("tx error: %w, rb error: %v", err, rbErr)
 		}
 		return err
 	}
@@ -46,12 +117,30 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	return tx.Commit(ctx)
 }
 
+// isRetryable reports whether the transaction failed only because it raced with another one
+func isRetryable(err error) bool {
+	code := ErrorCode(err)
+	return code == SerializationFailure || code == DeadlockDetected
+}
+
+// retryDelay doubles from txRetryBaseDelay up to txRetryMaxDelay, with jitter so the racing transactions spread out
+func retryDelay(attempt int) time.Duration {
+	delay := min(txRetryBaseDelay<<(attempt-1), txRetryMaxDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
 // TransferTxParams provides the parameters for TransferTx
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	FromAmount    int64 `json:"from_amount"`
 	ToAmount      int64 `json:"to_amount"`
+	// ExchangeRate is the number of destination major units credited per source major unit
+	ExchangeRate pgtype.Numeric `json:"exchange_rate"`
+	// RoundingRemainder is the fraction of a minor unit of the destination currency dropped when ToAmount was rounded
+	RoundingRemainder pgtype.Numeric `json:"rounding_remainder"`
+	// Username is the user sending the transfer, whose limits it counts against. It defaults to the account holder.
+	Username string `json:"username"`
 }
 
 // TransferTxResult is the result of TransferTx
@@ -68,49 +157,145 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
-		if fromAccount, _ := q.GetAccount(ctx, arg.FromAccountID); fromAccount.Balance < arg.FromAmount {
-			return ErrInsufficientBalance
-		}
-
 		var err error
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.FromAmount,
-		})
-		if err != nil {
-			return err
-		}
+		result, err = transfer(ctx, q, arg, true)
+		return err
+	})
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.FromAmount,
-		})
-		if err != nil {
-			return err
+	return result, err
+}
+
+// transfer posts a transfer and its entries within the transaction of q, the sender's limits are enforced when checkLimits is set
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams, checkLimits bool) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	// Both rows are locked in id order up front, the same order addMoney updates them in, so the
+	// balance and the limit totals can't change before the entries are posted
+	fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+	if isCashAccount(fromAccount) || isCashAccount(toAccount) {
+		return result, ErrCashAccountTransfer
+	}
+	if !canTransact(fromAccount) || !canTransact(toAccount) {
+		return result, ErrAccountNotActive
+	}
+
+	// Funds reserved by holds can't be sent
+	available, err := availableBalance(ctx, q, fromAccount)
+	if err != nil {
+		return result, err
+	}
+	if available < arg.FromAmount {
+		return result, ErrInsufficientBalance
+	}
+	// The transfer is charged to the user sending it, the owner when the caller didn't name one
+	username := arg.Username
+	if username == "" {
+		username = fromAccount.Owner
+	}
+	if checkLimits {
+		if err := checkTransferLimit(ctx, q, username, fromAccount, arg.FromAmount); err != nil {
+			return result, err
 		}
+	}
+
+	// The currencies and the applied rate are kept on the transfer so past conversions can be audited
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.FromAmount,
+		Status:        util.TransferStatusCompleted,
+		FromCurrency:  fromAccount.Currency,
+		ToCurrency:    toAccount.Currency,
+		ToAmount:      pgtype.Int8{Int64: arg.ToAmount, Valid: true},
+		ExchangeRate:  arg.ExchangeRate,
+		InitiatedBy:   pgtype.Text{String: username, Valid: true},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	transferID := pgtype.Int8{Int64: result.Transfer.ID, Valid: true}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -arg.FromAmount,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     arg.ToAmount,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// Update account balances
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.FromAmount, arg.ToAccountID, arg.ToAmount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.ToAmount, arg.FromAccountID, -arg.FromAmount)
+	}
+	if err != nil {
+		return result, err
+	}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.ToAmount,
+	if arg.RoundingRemainder.Valid {
+		_, err = q.CreateRoundingRemainder(ctx, CreateRoundingRemainderParams{
+			TransferID: result.Transfer.ID,
+			Currency:   result.ToAccount.Currency,
+			Amount:     arg.RoundingRemainder,
 		})
 		if err != nil {
-			return err
-		}
-
-		// Update account balances
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.FromAmount, arg.ToAccountID, arg.ToAmount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.ToAmount, arg.FromAccountID, -arg.FromAmount)
+			return result, err
 		}
+	}
 
-		return err
-	})
+	err = recordEvent(ctx, q, "transfer", result.Transfer.ID, EventTransferCreated, result.Transfer)
+	if err != nil {
+		return result, err
+	}
 
+	err = recordPosting(ctx, q,
+		[]Entry{result.FromEntry, result.ToEntry},
+		[]Account{result.FromAccount, result.ToAccount},
+	)
 	return result, err
 }
 
+// canTransact reports whether money may move in or out of the account, frozen and closed accounts are blocked
+func canTransact(account Account) bool {
+	return account.Status == util.AccountStatusActive
+}
+
+// isCashAccount reports whether the account is one of the bank's cash accounts, which only cash transactions post to
+func isCashAccount(account Account) bool {
+	return account.Owner == util.CashAccountOwner
+}
+
+// lockAccounts locks the two accounts in id order and returns them in the order given
+func lockAccounts(ctx context.Context, q *Queries, accountID1 int64, accountID2 int64) (account1 Account, account2 Account, err error) {
+	if accountID1 > accountID2 {
+		account2, account1, err = lockAccounts(ctx, q, accountID2, accountID1)
+		return
+	}
+
+	account1, err = q.GetAccountForUpdate(ctx, accountID1)
+	if err != nil {
+		return
+	}
+
+	account2, err = q.GetAccountForUpdate(ctx, accountID2)
+	return
+}
+
 func addMoney(
 	ctx context.Context,
 	q *Queries,
@@ -4,6 +4,9 @@ import (
 	"context"
 	"testing"
 
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/require"
 )
 
@@ -45,6 +48,10 @@ func TestTransferTx(t *testing.T) {
 		require.Equal(t, account1.ID, transfer.FromAccountID)
 		require.Equal(t, account2.ID, transfer.ToAccountID)
 		require.Equal(t, amount, transfer.Amount)
+		require.Equal(t, util.TransferStatusCompleted, transfer.Status)
+		require.Equal(t, account1.Currency, transfer.FromCurrency)
+		require.Equal(t, account2.Currency, transfer.ToCurrency)
+		require.Equal(t, amount, transfer.ToAmount.Int64)
 		require.NotZero(t, transfer.ID)
 		require.NotZero(t, transfer.CreatedAt)
 
@@ -143,3 +150,90 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedFromAccount.Balance)
 	require.Equal(t, account2.Balance, updatedToAccount.Balance)
 }
+
+func TestTransferTxConcurrentOverdraft(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// Each transfer would empty the account, the balance is checked on the locked row so only one passes
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				FromAmount:    account1.Balance,
+				ToAmount:      account1.Balance,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrInsufficientBalance)
+	}
+	require.Equal(t, 1, succeeded)
+
+	updatedAccount, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, updatedAccount.Balance)
+}
+
+func TestExecTxRetry(t *testing.T) {
+	store := testStore.(*SQLStore)
+
+	attempts := 0
+	err := store.execTx(context.Background(), func(q *Queries) error {
+		attempts++
+		if attempts < 3 {
+			return &pgconn.PgError{Code: SerializationFailure}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = store.execTx(context.Background(), func(q *Queries) error {
+		attempts++
+		return &pgconn.PgError{Code: DeadlockDetected}
+	}, WithMaxAttempts(2))
+	require.Equal(t, DeadlockDetected, ErrorCode(err))
+	require.Equal(t, 2, attempts)
+
+	// other errors are never retried
+	attempts = 0
+	err = store.execTx(context.Background(), func(q *Queries) error {
+		attempts++
+		return ErrInsufficientBalance
+	})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+	require.Equal(t, 1, attempts)
+}
+
+func TestExecTxIsolation(t *testing.T) {
+	store := testStore.(*SQLStore)
+
+	var level string
+	err := store.execTx(context.Background(), func(q *Queries) error {
+		return q.db.QueryRow(context.Background(), "SHOW transaction_isolation").Scan(&level)
+	}, WithIsolation(pgx.Serializable))
+	require.NoError(t, err)
+	require.Equal(t, "serializable", level)
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		delay := retryDelay(attempt)
+		require.Positive(t, delay)
+		require.LessOrEqual(t, delay, txRetryMaxDelay)
+	}
+	require.LessOrEqual(t, retryDelay(1), txRetryBaseDelay)
+}
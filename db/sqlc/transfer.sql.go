@@ -7,26 +7,53 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  status,
+  reversal_of,
+  from_currency,
+  to_currency,
+  to_amount,
+  exchange_rate,
+  initiated_by
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING id, from_account_id, to_account_id, amount, created_at, status, reversal_of, from_currency, to_currency, to_amount, exchange_rate, initiated_by
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64       `json:"amount"`
+	FromAccountID int64          `json:"from_account_id"`
+	ToAccountID   int64          `json:"to_account_id"`
+	Amount        int64          `json:"amount"`
+	Status        string         `json:"status"`
+	ReversalOf    pgtype.Int8    `json:"reversal_of"`
+	FromCurrency  string         `json:"from_currency"`
+	ToCurrency    string         `json:"to_currency"`
+	ToAmount      pgtype.Int8    `json:"to_amount"`
+	ExchangeRate  pgtype.Numeric `json:"exchange_rate"`
+	InitiatedBy   pgtype.Text    `json:"initiated_by"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRow(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Status,
+		arg.ReversalOf,
+		arg.FromCurrency,
+		arg.ToCurrency,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.InitiatedBy,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +61,19 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ReversalOf,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.InitiatedBy,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, status, reversal_of, from_currency, to_currency, to_amount, exchange_rate, initiated_by FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,12 +86,80 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ReversalOf,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.InitiatedBy,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, status, reversal_of, from_currency, to_currency, to_amount, exchange_rate, initiated_by FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ReversalOf,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.InitiatedBy,
+	)
+	return i, err
+}
+
+const getUserOutboundTotals = `-- name: GetUserOutboundTotals :one
+SELECT
+  COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('day', now())), 0)::bigint AS daily_total,
+  COALESCE(SUM(amount), 0)::bigint AS monthly_total
+FROM transfers
+WHERE initiated_by = $1
+  AND from_currency = $2
+  AND reversal_of IS NULL
+  AND status <> 'reversed'
+  AND created_at >= date_trunc('month', now())
+`
+
+type GetUserOutboundTotalsParams struct {
+	Username string `json:"username"`
+	Currency string `json:"currency"`
+}
+
+type GetUserOutboundTotalsRow struct {
+	DailyTotal   int64 `json:"daily_total"`
+	MonthlyTotal int64 `json:"monthly_total"`
+}
+
+// Sums the transfers the user sent in the currency since the start of the current day and month,
+// whichever account they were sent from. Reversals and the transfers they reversed are excluded,
+// since neither left money out of the user's accounts.
+func (q *Queries) GetUserOutboundTotals(ctx context.Context, arg GetUserOutboundTotalsParams) (GetUserOutboundTotalsRow, error) {
+	row := q.db.QueryRow(ctx, getUserOutboundTotals, arg.Username, arg.Currency)
+	var i GetUserOutboundTotalsRow
+	err := row.Scan(
+		&i.DailyTotal,
+		&i.MonthlyTotal,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, status, reversal_of, from_currency, to_currency, to_amount, exchange_rate, initiated_by FROM transfers
 WHERE 
     from_account_id = $1 OR
     to_account_id = $2
@@ -69,8 +171,8 @@ OFFSET $4
 type ListTransfersParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32       `json:"limit"`
-	Offset        int32       `json:"offset"`
+	Limit         int32 `json:"limit"`
+	Offset        int32 `json:"offset"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
@@ -93,6 +195,13 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Status,
+			&i.ReversalOf,
+			&i.FromCurrency,
+			&i.ToCurrency,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.InitiatedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -103,3 +212,35 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = $2
+WHERE id = $1
+RETURNING id, from_account_id, to_account_id, amount, created_at, status, reversal_of, from_currency, to_currency, to_amount, exchange_rate, initiated_by
+`
+
+type UpdateTransferStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, updateTransferStatus, arg.ID, arg.Status)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ReversalOf,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.InitiatedBy,
+	)
+	return i, err
+}
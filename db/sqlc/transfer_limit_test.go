@@ -0,0 +1,186 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveTransferLimits(t *testing.T) {
+	limits := []TransferLimit{
+		{Role: pgtype.Text{String: util.DepositorRole, Valid: true}, Currency: util.EUR,
+			MaxSingle: pgtype.Int8{Int64: 100, Valid: true}},
+		{Role: pgtype.Text{String: util.DepositorRole, Valid: true}, Currency: util.USD,
+			MaxSingle: pgtype.Int8{Int64: 100, Valid: true}, MaxDaily: pgtype.Int8{Int64: 500, Valid: true}},
+		{Username: pgtype.Text{String: "alice", Valid: true}, Currency: util.USD,
+			MaxDaily: pgtype.Int8{Int64: 900, Valid: true}, MaxMonthly: pgtype.Int8{Int64: 5000, Valid: true}},
+		{Username: pgtype.Text{String: "alice", Valid: true}, Currency: util.EUR,
+			MaxSingle: pgtype.Int8{Int64: 0, Valid: true}},
+	}
+
+	require.Equal(t, []EffectiveTransferLimit{
+		{Currency: util.EUR},
+		{
+			Currency:   util.USD,
+			MaxSingle:  pgtype.Int8{Int64: 100, Valid: true},
+			MaxDaily:   pgtype.Int8{Int64: 900, Valid: true},
+			MaxMonthly: pgtype.Int8{Int64: 5000, Valid: true},
+		},
+	}, EffectiveTransferLimits(limits))
+	require.Empty(t, EffectiveTransferLimits(nil))
+}
+
+func TestUpsertUserTransferLimit(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := UpsertUserTransferLimitParams{
+		Username: pgtype.Text{String: user.Username, Valid: true},
+		Currency: util.USD,
+		MaxDaily: pgtype.Int8{Int64: 1000, Valid: true},
+	}
+	limit1, err := testStore.UpsertUserTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.MaxDaily, limit1.MaxDaily)
+	require.False(t, limit1.Role.Valid)
+
+	arg.MaxDaily = pgtype.Int8{Int64: 2000, Valid: true}
+	limit2, err := testStore.UpsertUserTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, limit1.ID, limit2.ID)
+	require.Equal(t, arg.MaxDaily, limit2.MaxDaily)
+
+	limits, err := testStore.ListTransferLimits(context.Background(), ListTransferLimitsParams{
+		Username: arg.Username,
+		Role:     pgtype.Text{String: "nobody", Valid: true},
+		Currency: pgtype.Text{String: util.USD, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []TransferLimit{limit2}, limits)
+}
+
+func TestTransferTxLimits(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.UpsertUserTransferLimit(context.Background(), UpsertUserTransferLimitParams{
+		Username:  pgtype.Text{String: account1.Owner, Valid: true},
+		Currency:  account1.Currency,
+		MaxSingle: pgtype.Int8{Int64: 8, Valid: true},
+		MaxDaily:  pgtype.Int8{Int64: 15, Valid: true},
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    9,
+		ToAmount:      9,
+	}
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	arg.FromAmount, arg.ToAmount = 8, 8
+	sent, err := testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// 8 + 8 breaks the daily limit of 15
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	totals, err := testStore.GetUserOutboundTotals(context.Background(), GetUserOutboundTotalsParams{
+		Currency: account1.Currency,
+		Username: account1.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(8), totals.DailyTotal)
+	require.Equal(t, int64(8), totals.MonthlyTotal)
+
+	// A reversed transfer gave the money back, so it no longer counts against the limits
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: sent.Transfer.ID})
+	require.NoError(t, err)
+
+	totals, err = testStore.GetUserOutboundTotals(context.Background(), GetUserOutboundTotalsParams{
+		Currency: account1.Currency,
+		Username: account1.Owner,
+	})
+	require.NoError(t, err)
+	require.Zero(t, totals.DailyTotal)
+	require.Zero(t, totals.MonthlyTotal)
+
+	// the receiver has no limits of its own
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		FromAmount:    20,
+		ToAmount:      20,
+	})
+	require.NoError(t, err)
+}
+
+func TestTransferTxLimitsAcrossJointAccounts(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// account1's owner may also debit a joint account of another user in the same currency
+	holder := createRandomUser(t)
+	joint, err := testStore.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    holder.Username,
+		Balance:  util.RandomMoney(),
+		Currency: account1.Currency,
+	})
+	require.NoError(t, err)
+	_, err = testStore.CreateAccountMember(context.Background(), CreateAccountMemberParams{
+		AccountID:  joint.ID,
+		Username:   account1.Owner,
+		Role:       util.MemberRoleCanTransfer,
+		Status:     util.MemberStatusActive,
+		InvitedBy:  holder.Username,
+		AcceptedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.UpsertUserTransferLimit(context.Background(), UpsertUserTransferLimitParams{
+		Username: pgtype.Text{String: account1.Owner, Valid: true},
+		Currency: account1.Currency,
+		MaxDaily: pgtype.Int8{Int64: 15, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    8,
+		ToAmount:      8,
+		Username:      account1.Owner,
+	})
+	require.NoError(t, err)
+
+	// Sending from the joint account counts against the same daily limit of the member who acts
+	arg := TransferTxParams{
+		FromAccountID: joint.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    8,
+		ToAmount:      8,
+		Username:      account1.Owner,
+	}
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// The holder has no limit of their own, so they can still send it
+	arg.Username = holder.Username
+	result, err := testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, holder.Username, result.Transfer.InitiatedBy.String)
+
+	// What the holder sent from the joint account isn't charged to the member
+	totals, err := testStore.GetUserOutboundTotals(context.Background(), GetUserOutboundTotalsParams{
+		Username: account1.Owner,
+		Currency: account1.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(8), totals.DailyTotal)
+}
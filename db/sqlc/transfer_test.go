@@ -13,11 +13,15 @@ func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Amount:        util.RandomMoney(),
+		Status:        util.TransferStatusCompleted,
+		FromCurrency:  account1.Currency,
+		ToCurrency:    account2.Currency,
 	}
 	transfer, err := testStore.CreateTransfer(context.Background(), arg)
 	require.NoError(t, err)
 	require.NotEmpty(t, transfer)
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
+	require.Equal(t, arg.Status, transfer.Status)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
 	require.NotZero(t, transfer.ID)
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+// ChangeAccountStatusTx updates the status of an account and records the change in the outbox.
+// The account must still be in one of arg.FromStatuses when the row is updated.
+func (store *SQLStore) ChangeAccountStatusTx(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.UpdateAccountStatus(ctx, arg)
+		if err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				return ErrAccountStatusChanged
+			}
+			return err
+		}
+
+		return recordEvent(ctx, q, "account", account.ID, EventAccountStatusChanged, account)
+	})
+
+	return account, err
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CashTxParams contains the input parameters of the cash transaction
+type CashTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Type      string `json:"type"`
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+	Reason    string `json:"reason"`
+	Banker    string `json:"banker"`
+}
+
+// CashTxResult is the result of the cash transaction
+type CashTxResult struct {
+	CashTransaction CashTransaction `json:"cash_transaction"`
+	Account         Account         `json:"account"`
+	CashAccount     Account         `json:"cash_account"`
+	Entry           Entry           `json:"entry"`
+	CashEntry       Entry           `json:"cash_entry"`
+}
+
+// CashTx deposits cash into or withdraws cash from an account.
+// The customer entry is offset by an entry on the bank's cash account of the same currency, so the ledger stays balanced.
+func (store *SQLStore) CashTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	var result CashTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// The cash account is resolved from the currency first, so both rows are locked in id order
+		// like transfer locks them and none of them can deadlock on the cash account
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		cashAccount, err := cashAccount(ctx, q, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		account, cashAccount, err = lockAccounts(ctx, q, account.ID, cashAccount.ID)
+		if err != nil {
+			return err
+		}
+		if !canTransact(account) {
+			return ErrAccountNotActive
+		}
+
+		amount := arg.Amount
+		if arg.Type == util.CashWithdrawal {
+			available, err := availableBalance(ctx, q, account)
+			if err != nil {
+				return err
+			}
+			if available < arg.Amount {
+				return ErrInsufficientBalance
+			}
+			amount = -arg.Amount
+		}
+
+		result.CashTransaction, err = q.CreateCashTransaction(ctx, CreateCashTransactionParams{
+			AccountID:     account.ID,
+			CashAccountID: cashAccount.ID,
+			Type:          arg.Type,
+			Amount:        arg.Amount,
+			Reference:     arg.Reference,
+			Reason:        arg.Reason,
+			Banker:        arg.Banker,
+		})
+		if err != nil {
+			return err
+		}
+
+		cashTransactionID := pgtype.Int8{Int64: result.CashTransaction.ID, Valid: true}
+
+		result.Entry, err = q.CreateCashEntry(ctx, CreateCashEntryParams{
+			AccountID:         account.ID,
+			Amount:            amount,
+			CashTransactionID: cashTransactionID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.CashEntry, err = q.CreateCashEntry(ctx, CreateCashEntryParams{
+			AccountID:         cashAccount.ID,
+			Amount:            -amount,
+			CashTransactionID: cashTransactionID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if account.ID < cashAccount.ID {
+			result.Account, result.CashAccount, err = addMoney(ctx, q, account.ID, amount, cashAccount.ID, -amount)
+		} else {
+			result.CashAccount, result.Account, err = addMoney(ctx, q, cashAccount.ID, -amount, account.ID, amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = recordEvent(ctx, q, "cash_transaction", result.CashTransaction.ID, EventCashTransactionCreated, result.CashTransaction)
+		if err != nil {
+			return err
+		}
+
+		return recordPosting(ctx, q,
+			[]Entry{result.Entry, result.CashEntry},
+			[]Account{result.Account, result.CashAccount},
+		)
+	})
+
+	return result, err
+}
+
+// cashAccount returns the bank's cash account of the currency, opening it on first use
+func cashAccount(ctx context.Context, q *Queries, currency string) (Account, error) {
+	arg := GetAccountByOwnerParams{
+		Owner:    util.CashAccountOwner,
+		Currency: currency,
+	}
+
+	account, err := q.GetAccountByOwner(ctx, arg)
+	if !errors.Is(err, ErrRecordNotFound) {
+		return account, err
+	}
+
+	err = q.CreateAccountIfNotExists(ctx, CreateAccountIfNotExistsParams{
+		Owner:    util.CashAccountOwner,
+		Currency: currency,
+	})
+	if err != nil {
+		return account, err
+	}
+
+	return q.GetAccountByOwner(ctx, arg)
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashTx(t *testing.T) {
+	account := createRandomAccount(t)
+	banker := createRandomUser(t)
+	amount := int64(500)
+
+	deposit, err := testStore.CashTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Type:      util.CashDeposit,
+		Amount:    amount,
+		Reference: util.RandomString(12),
+		Reason:    "branch deposit",
+		Banker:    banker.Username,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.Balance+amount, deposit.Account.Balance)
+	require.Equal(t, util.CashAccountOwner, deposit.CashAccount.Owner)
+	require.Equal(t, account.Currency, deposit.CashAccount.Currency)
+	require.Equal(t, amount, deposit.Entry.Amount)
+	require.Equal(t, -amount, deposit.CashEntry.Amount)
+	require.Equal(t, deposit.CashTransaction.ID, deposit.Entry.CashTransactionID.Int64)
+	require.Equal(t, deposit.CashTransaction.ID, deposit.CashEntry.CashTransactionID.Int64)
+	require.False(t, deposit.Entry.TransferID.Valid)
+
+	withdrawal, err := testStore.CashTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Type:      util.CashWithdrawal,
+		Amount:    amount,
+		Reference: util.RandomString(12),
+		Reason:    "branch withdrawal",
+		Banker:    banker.Username,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.Balance, withdrawal.Account.Balance)
+	require.Equal(t, deposit.CashAccount.ID, withdrawal.CashAccount.ID)
+	require.Equal(t, deposit.CashAccount.Balance+amount, withdrawal.CashAccount.Balance)
+	require.Equal(t, -amount, withdrawal.Entry.Amount)
+	require.Equal(t, amount, withdrawal.CashEntry.Amount)
+}
+
+func TestCashTxInsufficientBalance(t *testing.T) {
+	account := createRandomAccount(t)
+	banker := createRandomUser(t)
+
+	_, err := testStore.CashTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Type:      util.CashWithdrawal,
+		Amount:    account.Balance + 1,
+		Reference: util.RandomString(12),
+		Reason:    "branch withdrawal",
+		Banker:    banker.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+}
+
+func TestCashTxDuplicateReference(t *testing.T) {
+	account := createRandomAccount(t)
+	banker := createRandomUser(t)
+
+	arg := CashTxParams{
+		AccountID: account.ID,
+		Type:      util.CashDeposit,
+		Amount:    10,
+		Reference: util.RandomString(12),
+		Reason:    "branch deposit",
+		Banker:    banker.Username,
+	}
+	_, err := testStore.CashTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = testStore.CashTx(context.Background(), arg)
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+
+	// The failed attempt must not have moved any money
+	updated, err := testStore.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+arg.Amount, updated.Balance)
+}
+
+func TestTransferTxCashAccount(t *testing.T) {
+	account := createRandomAccount(t)
+	banker := createRandomUser(t)
+
+	deposit, err := testStore.CashTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Type:      util.CashDeposit,
+		Amount:    10,
+		Reference: util.RandomString(12),
+		Reason:    "branch deposit",
+		Banker:    banker.Username,
+	})
+	require.NoError(t, err)
+
+	// the cash account only moves money through cash transactions, in either direction
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   deposit.CashAccount.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.ErrorIs(t, err, ErrCashAccountTransfer)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: deposit.CashAccount.ID,
+		ToAccountID:   account.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.ErrorIs(t, err, ErrCashAccountTransfer)
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/RobertChienShiba/simplebank/util"
+)
+
+// CloseAccountTxParams contains the input parameters of the close account transaction
+type CloseAccountTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Sweep moves the remaining balance to another account of the owner, it is nil when the account is empty
+	Sweep *TransferTxParams `json:"sweep"`
+}
+
+// CloseAccountTxResult is the result of the close account transaction
+type CloseAccountTxResult struct {
+	Account Account           `json:"account"`
+	Sweep   *TransferTxResult `json:"sweep,omitempty"`
+}
+
+// CloseAccountTx sweeps the remaining balance out of an account and closes it.
+// The sweep amount is worked out by the caller, so the account must end up empty or nothing is committed.
+func (store *SQLStore) CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error) {
+	var result CloseAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		// A frozen account stays open until a banker unfreezes it
+		if account.Status == util.AccountStatusClosed || account.Status == util.AccountStatusFrozen {
+			return ErrAccountNotActive
+		}
+
+		held, err := q.GetAccountHeldAmount(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		if held > 0 {
+			return ErrAccountHasHolds
+		}
+
+		if arg.Sweep != nil {
+			// The balance stays with the owner, so closing an account is never held back by their limits
+			sweep, err := transfer(ctx, q, *arg.Sweep, false)
+			if err != nil {
+				return err
+			}
+			result.Sweep = &sweep
+			account = sweep.FromAccount
+		} else {
+			// Lock the row so no transfer can credit the account while it is being closed
+			account, err = q.GetAccountForUpdate(ctx, arg.AccountID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if account.Balance != 0 {
+			return ErrAccountBalanceChanged
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			Status:       util.AccountStatusClosed,
+			ID:           arg.AccountID,
+			FromStatuses: []string{util.AccountStatusActive},
+		})
+		if err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				return ErrAccountStatusChanged
+			}
+			return err
+		}
+
+		return recordEvent(ctx, q, "account", result.Account.ID, EventAccountStatusChanged, result.Account)
+	})
+
+	return result, err
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseAccountTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := testStore.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID: account1.ID,
+		Sweep: &TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			FromAmount:    account1.Balance,
+			ToAmount:      account1.Balance,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, util.AccountStatusClosed, result.Account.Status)
+	require.Zero(t, result.Account.Balance)
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, account2.Balance+account1.Balance, result.Sweep.ToAccount.Balance)
+
+	// a closed account can neither send nor receive
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		FromAmount:    1,
+		ToAmount:      1,
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	_, err = testStore.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account1.ID})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+}
+
+func TestCloseAccountTxReopenCurrency(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID: account1.ID,
+		Sweep: &TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			FromAmount:    account1.Balance,
+			ToAmount:      account1.Balance,
+		},
+	})
+	require.NoError(t, err)
+
+	// The closed account no longer holds its owner's currency
+	arg := CreateAccountParams{
+		Owner:    account1.Owner,
+		Balance:  0,
+		Currency: account1.Currency,
+	}
+	reopened, err := testStore.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEqual(t, account1.ID, reopened.ID)
+	require.Equal(t, util.AccountStatusActive, reopened.Status)
+
+	// while an open one still does
+	_, err = testStore.CreateAccountTx(context.Background(), arg)
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+}
+
+func TestCloseAccountTxBalanceChanged(t *testing.T) {
+	account := createRandomAccount(t)
+
+	_, err := testStore.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrAccountBalanceChanged)
+
+	got, err := testStore.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.AccountStatusActive, got.Status)
+}
+
+func TestTransferTxFrozenAccount(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status:       util.AccountStatusFrozen,
+		ID:           account2.ID,
+		FromStatuses: []string{util.AccountStatusActive},
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	got, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, got.Balance)
+}
+
+func TestCloseAccountTxFrozen(t *testing.T) {
+	account := createRandomAccount(t)
+
+	_, err := testStore.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status:       util.AccountStatusFrozen,
+		ID:           account.ID,
+		FromStatuses: []string{util.AccountStatusActive},
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	got, err := testStore.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.AccountStatusFrozen, got.Status)
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateAccountTx opens an account with its holder as the first owner member and records its account.created event
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateAccountMember(ctx, CreateAccountMemberParams{
+			AccountID:  account.ID,
+			Username:   account.Owner,
+			Role:       util.MemberRoleOwner,
+			Status:     util.MemberStatusActive,
+			InvitedBy:  account.Owner,
+			AcceptedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordEvent(ctx, q, "account", account.ID, EventAccountCreated, account)
+	})
+
+	return account, err
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AuthorizeHoldTxParams contains the input parameters of the authorize hold transaction
+type AuthorizeHoldTxParams struct {
+	AccountID   int64     `json:"account_id"`
+	ToAccountID int64     `json:"to_account_id"`
+	Amount      int64     `json:"amount"`
+	Description string    `json:"description"`
+	CreatedBy   string    `json:"created_by"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// AuthorizeHoldTx reserves an amount of the account's available balance until it is captured, released or expires
+func (store *SQLStore) AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Holds of an account are only placed while its row is locked, like transfers out of it
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		if !canTransact(account) {
+			return ErrAccountNotActive
+		}
+
+		available, err := availableBalance(ctx, q, account)
+		if err != nil {
+			return err
+		}
+		if available < arg.Amount {
+			return ErrInsufficientBalance
+		}
+
+		hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID:   arg.AccountID,
+			ToAccountID: arg.ToAccountID,
+			Amount:      arg.Amount,
+			Description: arg.Description,
+			CreatedBy:   arg.CreatedBy,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		return err
+	})
+
+	return hold, err
+}
+
+// CaptureHoldTxParams contains the input parameters of the capture hold transaction
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Transfer moves the captured amount from the held account to the payee, it is worked out by the caller
+	Transfer TransferTxParams `json:"transfer"`
+}
+
+// CaptureHoldTxResult is the result of the capture hold transaction
+type CaptureHoldTxResult struct {
+	Hold Hold `json:"hold"`
+	TransferTxResult
+}
+
+// CaptureHoldTx turns an active hold into a real transfer, capturing less than the hold releases the rest
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = captureHold(ctx, q, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// captureHold posts the transfer of a hold and marks it captured, it runs within the transaction of the caller
+func captureHold(ctx context.Context, q *Queries, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+	if err != nil {
+		return result, err
+	}
+	if hold.Status != util.HoldStatusActive || !hold.ExpiresAt.After(time.Now()) {
+		return result, ErrHoldNotActive
+	}
+	if hold.AccountID != arg.Transfer.FromAccountID || hold.ToAccountID != arg.Transfer.ToAccountID {
+		return result, errors.New("capture must transfer between the accounts of the hold")
+	}
+	if arg.Transfer.FromAmount > hold.Amount {
+		return result, ErrHoldAmountExceeded
+	}
+
+	// The hold stops reserving funds first, otherwise the transfer would not see them as available
+	_, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+		Status:         util.HoldStatusCaptured,
+		CapturedAmount: pgtype.Int8{Int64: arg.Transfer.FromAmount, Valid: true},
+		ID:             hold.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.TransferTxResult, err = transfer(ctx, q, arg.Transfer, true)
+	if err != nil {
+		return result, err
+	}
+
+	result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+		Status:     util.HoldStatusCaptured,
+		TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+		ID:         hold.ID,
+	})
+	return result, err
+}
+
+// availableBalance is the balance of account less its active holds, the account row should be locked
+func availableBalance(ctx context.Context, q *Queries, account Account) (int64, error) {
+	held, err := q.GetAccountHeldAmount(ctx, account.ID)
+	if err != nil {
+		return 0, err
+	}
+	return account.Balance - held, nil
+}
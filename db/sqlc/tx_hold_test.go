@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func authorizeRandomHold(t *testing.T, account1, account2 Account, amount int64) Hold {
+	hold, err := testStore.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      amount,
+		Description: util.RandomString(12),
+		CreatedBy:   account1.Owner,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.HoldStatusActive, hold.Status)
+	require.Equal(t, amount, hold.Amount)
+	return hold
+}
+
+func TestAuthorizeHoldTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	authorizeRandomHold(t, account1, account2, account1.Balance-10)
+
+	held, err := testStore.GetAccountHeldAmount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, held)
+
+	// only 10 is still available, for both holds and transfers
+	_, err = testStore.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      11,
+		Description: util.RandomString(12),
+		CreatedBy:   account1.Owner,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    11,
+		ToAmount:      11,
+	})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.NoError(t, err)
+}
+
+func TestCaptureHoldTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	hold := authorizeRandomHold(t, account1, account2, account1.Balance)
+
+	result, err := testStore.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Transfer: TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			FromAmount:    account1.Balance - 5,
+			ToAmount:      account1.Balance - 5,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, util.HoldStatusCaptured, result.Hold.Status)
+	require.Equal(t, account1.Balance-5, result.Hold.CapturedAmount.Int64)
+	require.Equal(t, result.Transfer.ID, result.Hold.TransferID.Int64)
+	require.Equal(t, int64(5), result.FromAccount.Balance)
+
+	// the uncaptured rest is released with the hold
+	held, err := testStore.GetAccountHeldAmount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = testStore.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Transfer: TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			FromAmount:    5,
+			ToAmount:      5,
+		},
+	})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestCaptureHoldTxExceedsHold(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	hold := authorizeRandomHold(t, account1, account2, 10)
+
+	_, err := testStore.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Transfer: TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			FromAmount:    11,
+			ToAmount:      11,
+		},
+	})
+	require.ErrorIs(t, err, ErrHoldAmountExceeded)
+
+	got, err := testStore.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.HoldStatusActive, got.Status)
+}
+
+func TestReleaseHold(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	hold := authorizeRandomHold(t, account1, account2, 10)
+
+	released, err := testStore.ReleaseHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.HoldStatusReleased, released.Status)
+
+	_, err = testStore.ReleaseHold(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestExpireHolds(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	hold, err := testStore.CreateHold(context.Background(), CreateHoldParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      10,
+		Description: util.RandomString(12),
+		CreatedBy:   account1.Owner,
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	// an expired hold reserves nothing even before it is marked
+	held, err := testStore.GetAccountHeldAmount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	expired, err := testStore.ExpireHolds(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, expired, int64(1))
+
+	got, err := testStore.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.HoldStatusExpired, got.Status)
+}
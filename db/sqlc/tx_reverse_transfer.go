@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ReverseTransferTxParams contains the input parameters of the reverse transfer transaction
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+}
+
+// ReverseTransferTxResult is the result of the reverse transfer transaction
+type ReverseTransferTxResult struct {
+	OriginalTransfer Transfer `json:"original_transfer"`
+	ReversalTransfer Transfer `json:"reversal_transfer"`
+	FromAccount      Account  `json:"from_account"`
+	ToAccount        Account  `json:"to_account"`
+	FromEntry        Entry    `json:"from_entry"`
+	ToEntry          Entry    `json:"to_entry"`
+}
+
+// ReverseTransferTx posts compensating entries for a completed transfer and marks it as reversed.
+// The reversal is recorded as a new transfer in the opposite direction which links back to the original one.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if original.Status != util.TransferStatusCompleted || original.ReversalOf.Valid {
+			return ErrTransferNotReversible
+		}
+
+		entries, err := q.ListEntriesByTransfer(ctx, pgtype.Int8{Int64: original.ID, Valid: true})
+		if err != nil {
+			return err
+		}
+
+		// The amounts credited and debited may differ for cross-currency transfers,
+		// so the compensating entries mirror the original entries rather than the transfer amount.
+		var debited, credited int64
+		var hasDebit, hasCredit bool
+		for _, entry := range entries {
+			switch {
+			case entry.AccountID == original.FromAccountID && entry.Amount < 0:
+				debited, hasDebit = -entry.Amount, true
+			case entry.AccountID == original.ToAccountID && entry.Amount > 0:
+				credited, hasCredit = entry.Amount, true
+			}
+		}
+		if !hasDebit || !hasCredit {
+			return ErrTransferEntriesNotFound
+		}
+
+		// The money flows back from the recipient, so its row is checked like the sender of transfer()
+		toAccount, fromAccount, err := lockAccounts(ctx, q, original.ToAccountID, original.FromAccountID)
+		if err != nil {
+			return err
+		}
+		if !canTransact(toAccount) || !canTransact(fromAccount) {
+			return ErrAccountNotActive
+		}
+
+		available, err := availableBalance(ctx, q, toAccount)
+		if err != nil {
+			return err
+		}
+		if available < credited {
+			return ErrInsufficientBalance
+		}
+
+		result.ReversalTransfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        credited,
+			Status:        util.TransferStatusCompleted,
+			ReversalOf:    pgtype.Int8{Int64: original.ID, Valid: true},
+			FromCurrency:  original.ToCurrency,
+			ToCurrency:    original.FromCurrency,
+			ToAmount:      pgtype.Int8{Int64: debited, Valid: true},
+			ExchangeRate:  inverseRate(original.ExchangeRate),
+		})
+		if err != nil {
+			return err
+		}
+
+		reversalID := pgtype.Int8{Int64: result.ReversalTransfer.ID, Valid: true}
+
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  original.ToAccountID,
+			Amount:     -credited,
+			TransferID: reversalID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  original.FromAccountID,
+			Amount:     debited,
+			TransferID: reversalID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The bank gives back the fractions it kept by rounding the original conversion
+		remainders, err := q.ListRoundingRemaindersByTransfer(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+		for _, remainder := range remainders {
+			amount, err := money.RatFromNumeric(remainder.Amount)
+			if err != nil {
+				return err
+			}
+			_, err = q.CreateRoundingRemainder(ctx, CreateRoundingRemainderParams{
+				TransferID: result.ReversalTransfer.ID,
+				Currency:   remainder.Currency,
+				Amount:     money.NumericFromRat(amount.Neg(amount)),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if original.ToAccountID < original.FromAccountID {
+			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, original.ToAccountID, -credited, original.FromAccountID, debited)
+		} else {
+			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, original.FromAccountID, debited, original.ToAccountID, -credited)
+		}
+		if err != nil {
+			return err
+		}
+
+		result.OriginalTransfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+			ID:     original.ID,
+			Status: util.TransferStatusReversed,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordEvent(ctx, q, "transfer", result.ReversalTransfer.ID, EventTransferCreated, result.ReversalTransfer)
+		if err != nil {
+			return err
+		}
+
+		err = recordEvent(ctx, q, "transfer", result.OriginalTransfer.ID, EventTransferReversed, result.OriginalTransfer)
+		if err != nil {
+			return err
+		}
+
+		return recordPosting(ctx, q,
+			[]Entry{result.FromEntry, result.ToEntry},
+			[]Account{result.FromAccount, result.ToAccount},
+		)
+	})
+
+	return result, err
+}
+
+// inverseRate returns the rate of the opposite direction, or null when the original rate was not recorded
+func inverseRate(rate pgtype.Numeric) pgtype.Numeric {
+	original, err := money.RatFromNumeric(rate)
+	if err != nil || original.Sign() == 0 {
+		return pgtype.Numeric{}
+	}
+	return money.NumericFromRat(original.Inv(original))
+}
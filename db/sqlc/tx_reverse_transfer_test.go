@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransferTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	amount := int64(10)
+	transferResult, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    amount,
+		ToAmount:      amount,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+	})
+	require.NoError(t, err)
+
+	// Check the original transfer
+	require.Equal(t, transferResult.Transfer.ID, result.OriginalTransfer.ID)
+	require.Equal(t, util.TransferStatusReversed, result.OriginalTransfer.Status)
+
+	// Check the reversal transfer
+	reversal := result.ReversalTransfer
+	require.Equal(t, account2.ID, reversal.FromAccountID)
+	require.Equal(t, account1.ID, reversal.ToAccountID)
+	require.Equal(t, amount, reversal.Amount)
+	require.Equal(t, account2.Currency, reversal.FromCurrency)
+	require.Equal(t, account1.Currency, reversal.ToCurrency)
+	require.Equal(t, util.TransferStatusCompleted, reversal.Status)
+	require.True(t, reversal.ReversalOf.Valid)
+	require.Equal(t, transferResult.Transfer.ID, reversal.ReversalOf.Int64)
+
+	// Check the compensating entries
+	require.Equal(t, account2.ID, result.FromEntry.AccountID)
+	require.Equal(t, -amount, result.FromEntry.Amount)
+	require.Equal(t, account1.ID, result.ToEntry.AccountID)
+	require.Equal(t, amount, result.ToEntry.Amount)
+
+	// Check the balances are restored
+	updatedAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	updatedAccount2, err := testStore.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+
+	// A transfer can only be reversed once
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+
+	// A reversal cannot be reversed itself
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: reversal.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+}
+
+func TestReverseTransferTxNotFound(t *testing.T) {
+	_, err := testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: -1,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestReverseTransferTxHeldFunds(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	amount := int64(10)
+	transferResult, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    amount,
+		ToAmount:      amount,
+	})
+	require.NoError(t, err)
+
+	// Everything but a few units of the recipient's balance is reserved
+	authorizeRandomHold(t, account2, account3, transferResult.ToAccount.Balance-amount+1)
+
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+}
+
+func TestReverseTransferTxAccountNotActive(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	amount := int64(10)
+	transferResult, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    amount,
+		ToAmount:      amount,
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:           account1.ID,
+		Status:       util.AccountStatusFrozen,
+		FromStatuses: []string{util.AccountStatusActive},
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+}
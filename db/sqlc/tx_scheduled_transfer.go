@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ClaimScheduledTransferTx claims the occurrence of a scheduled transfer due at arg.NextRunAt and records it
+// as a claimed run, so an occurrence whose worker stops before posting it is left behind as a run to resume.
+func (store *SQLStore) ClaimScheduledTransferTx(ctx context.Context, arg ClaimScheduledTransferParams) (ScheduledTransferRun, error) {
+	var run ScheduledTransferRun
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.ClaimScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		run, err = q.CreateScheduledTransferRun(ctx, CreateScheduledTransferRunParams{
+			ScheduledTransferID: arg.ID,
+			Status:              util.ScheduleRunStatusClaimed,
+			ScheduledFor:        arg.NextRunAt,
+		})
+		return err
+	})
+
+	return run, err
+}
+
+// PostScheduledTransferRunTxParams contains the input parameters of the post scheduled transfer run transaction
+type PostScheduledTransferRunTxParams struct {
+	RunID    int64            `json:"run_id"`
+	Transfer TransferTxParams `json:"transfer"`
+}
+
+// PostScheduledTransferRunTxResult is the result of the post scheduled transfer run transaction
+type PostScheduledTransferRunTxResult struct {
+	TransferTxResult
+	Run ScheduledTransferRun `json:"run"`
+}
+
+// PostScheduledTransferRunTx posts the transfer of a claimed run and completes the run in the same transaction,
+// so a run is either still claimed or completed with its transfer. A run finished by another worker posts nothing.
+func (store *SQLStore) PostScheduledTransferRunTx(ctx context.Context, arg PostScheduledTransferRunTxParams) (PostScheduledTransferRunTxResult, error) {
+	var result PostScheduledTransferRunTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.TransferTxResult, err = transfer(ctx, q, arg.Transfer, true)
+		if err != nil {
+			return err
+		}
+
+		result.Run, err = q.FinishScheduledTransferRun(ctx, FinishScheduledTransferRunParams{
+			ID:         arg.RunID,
+			Status:     util.ScheduleRunStatusCompleted,
+			TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+		})
+		if errors.Is(err, ErrRecordNotFound) {
+			return ErrScheduledRunFinished
+		}
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// SyncRatesTxParams contains the input parameters of the sync rates transaction
+type SyncRatesTxParams struct {
+	Rates       []UpsertCurrencyParams `json:"rates"`
+	EffectiveAt time.Time              `json:"effective_at"`
+}
+
+// SyncRatesTxResult is the result of the sync rates transaction
+type SyncRatesTxResult struct {
+	Currencies []Currency     `json:"currencies"`
+	History    []CurrencyRate `json:"history"`
+}
+
+// SyncRatesTx upserts the latest rates in a single transaction, so readers never observe a missing currency.
+// A history row is only appended when the rate of a currency actually changed.
+func (store *SQLStore) SyncRatesTx(ctx context.Context, arg SyncRatesTxParams) (SyncRatesTxResult, error) {
+	var result SyncRatesTxResult
+
+	// Concurrent syncs compare against the rates they read, so they must not interleave
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = SyncRatesTxResult{}
+		for _, rate := range arg.Rates {
+			previous, err := q.GetExchangeRate(ctx, rate.Currency)
+			if err != nil && !errors.Is(err, ErrRecordNotFound) {
+				return err
+			}
+			changed := err != nil || !sameRate(previous, rate.Rate)
+
+			currency, err := q.UpsertCurrency(ctx, rate)
+			if err != nil {
+				return err
+			}
+			result.Currencies = append(result.Currencies, currency)
+
+			if !changed {
+				continue
+			}
+
+			history, err := q.CreateCurrencyRate(ctx, CreateCurrencyRateParams{
+				Currency:    rate.Currency,
+				Rate:        rate.Rate,
+				EffectiveAt: arg.EffectiveAt,
+			})
+			if err != nil {
+				return err
+			}
+			result.History = append(result.History, history)
+		}
+
+		return nil
+	}, WithIsolation(pgx.Serializable))
+
+	return result, err
+}
+
+// sameRate compares two rates by value, since 32.5 and 32.50 are stored with different scales
+func sameRate(previous pgtype.Numeric, current pgtype.Numeric) bool {
+	previousRate, err := money.RatFromNumeric(previous)
+	if err != nil {
+		return false
+	}
+
+	currentRate, err := money.RatFromNumeric(current)
+	if err != nil {
+		return false
+	}
+
+	return previousRate.Cmp(currentRate) == 0
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func newNumeric(t *testing.T, value string) pgtype.Numeric {
+	var numeric pgtype.Numeric
+	require.NoError(t, numeric.Scan(value))
+	return numeric
+}
+
+func TestSyncRatesTx(t *testing.T) {
+	currency1 := util.RandomString(3)
+	currency2 := util.RandomString(3)
+
+	result, err := testStore.SyncRatesTx(context.Background(), SyncRatesTxParams{
+		Rates: []UpsertCurrencyParams{
+			{Currency: currency1, Rate: newNumeric(t, "32.5")},
+			{Currency: currency2, Rate: newNumeric(t, "0.21")},
+		},
+		EffectiveAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Currencies, 2)
+	require.Len(t, result.History, 2)
+
+	// An unchanged rate, even with a different scale, does not add history
+	result, err = testStore.SyncRatesTx(context.Background(), SyncRatesTxParams{
+		Rates: []UpsertCurrencyParams{
+			{Currency: currency1, Rate: newNumeric(t, "32.50000")},
+			{Currency: currency2, Rate: newNumeric(t, "0.22")},
+		},
+		EffectiveAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Currencies, 2)
+	require.Len(t, result.History, 1)
+	require.Equal(t, currency2, result.History[0].Currency)
+
+	rate, err := testStore.GetExchangeRate(context.Background(), currency2)
+	require.NoError(t, err)
+	value, err := rate.Value()
+	require.NoError(t, err)
+	require.Equal(t, "0.22", value)
+
+	history, err := testStore.ListCurrencyRateHistory(context.Background(), ListCurrencyRateHistoryParams{
+		Currency: currency1,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ReviewTransferApprovalTxParams contains the input parameters of the review transfer approval transaction
+type ReviewTransferApprovalTxParams struct {
+	ID       int64  `json:"id"`
+	Reviewer string `json:"reviewer"`
+	Approve  bool   `json:"approve"`
+	Reason   string `json:"reason"`
+}
+
+// ReviewTransferApprovalTxResult is the result of the review transfer approval transaction
+type ReviewTransferApprovalTxResult struct {
+	Approval TransferApproval `json:"approval"`
+	// Transfer is only set when the approval was approved
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+}
+
+// ReviewTransferApprovalTx approves or rejects a pending transfer approval. Approving it posts the transfer
+// with the amounts and rate locked when it was requested, capturing its hold if it has one,
+// and the approval stays pending if the transfer fails. A scheduled run waiting for the approval is settled with it.
+func (store *SQLStore) ReviewTransferApprovalTx(ctx context.Context, arg ReviewTransferApprovalTxParams) (ReviewTransferApprovalTxResult, error) {
+	var result ReviewTransferApprovalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		approval, err := q.GetTransferApprovalForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if approval.Status != util.ApprovalStatusPending || !approval.ExpiresAt.After(time.Now()) {
+			return ErrApprovalNotPending
+		}
+		if approval.RequestedBy == arg.Reviewer {
+			return ErrSelfApproval
+		}
+
+		review := ReviewTransferApprovalParams{
+			Status:     util.ApprovalStatusRejected,
+			ReviewedBy: pgtype.Text{String: arg.Reviewer, Valid: true},
+			Reason:     pgtype.Text{String: arg.Reason, Valid: true},
+			ID:         approval.ID,
+		}
+
+		if arg.Approve {
+			transferArg := TransferTxParams{
+				FromAccountID:     approval.FromAccountID,
+				ToAccountID:       approval.ToAccountID,
+				FromAmount:        approval.Amount,
+				ToAmount:          approval.ToAmount,
+				ExchangeRate:      approval.ExchangeRate,
+				RoundingRemainder: approval.RoundingRemainder,
+				Username:          approval.RequestedBy,
+			}
+
+			var transferResult TransferTxResult
+			if approval.HoldID.Valid {
+				// An approved capture settles its hold like a capture that needed no approval
+				captured, err := captureHold(ctx, q, CaptureHoldTxParams{HoldID: approval.HoldID.Int64, Transfer: transferArg})
+				if err != nil {
+					return err
+				}
+				transferResult = captured.TransferTxResult
+			} else {
+				transferResult, err = transfer(ctx, q, transferArg, true)
+				if err != nil {
+					return err
+				}
+			}
+
+			result.Transfer = &transferResult
+			review.Status = util.ApprovalStatusApproved
+			review.TransferID = pgtype.Int8{Int64: transferResult.Transfer.ID, Valid: true}
+		}
+
+		result.Approval, err = q.ReviewTransferApproval(ctx, review)
+		if err != nil {
+			return err
+		}
+
+		run := SettleScheduledTransferRunParams{
+			ApprovalID: pgtype.Int8{Int64: approval.ID, Valid: true},
+			Status:     util.ScheduleRunStatusFailed,
+			Error:      pgtype.Text{String: fmt.Sprintf("transfer approval rejected: %s", arg.Reason), Valid: true},
+		}
+		if arg.Approve {
+			run.Status = util.ScheduleRunStatusCompleted
+			run.TransferID = review.TransferID
+			run.Error = pgtype.Text{}
+		}
+
+		_, err = q.SettleScheduledTransferRun(ctx, run)
+		return err
+	})
+
+	return result, err
+}
+
+// ExpireTransferApprovalsTx expires the pending approvals past their expiry and fails the scheduled runs waiting for them
+func (store *SQLStore) ExpireTransferApprovalsTx(ctx context.Context) ([]TransferApproval, error) {
+	var approvals []TransferApproval
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		approvals, err = q.ExpireTransferApprovals(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, approval := range approvals {
+			_, err = q.SettleScheduledTransferRun(ctx, SettleScheduledTransferRunParams{
+				ApprovalID: pgtype.Int8{Int64: approval.ID, Valid: true},
+				Status:     util.ScheduleRunStatusFailed,
+				Error:      pgtype.Text{String: "transfer approval expired", Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return approvals, err
+}
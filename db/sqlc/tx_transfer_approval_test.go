@@ -0,0 +1,240 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTransferApproval(t *testing.T, account1, account2 Account, amount int64, expiresAt time.Time) TransferApproval {
+	var rate pgtype.Numeric
+	require.NoError(t, rate.Scan("1"))
+
+	approval, err := testStore.CreateTransferApproval(context.Background(), CreateTransferApprovalParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  rate,
+		RequestedBy:   account1.Owner,
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ApprovalStatusPending, approval.Status)
+	require.Equal(t, amount, approval.Amount)
+	require.False(t, approval.ReviewedBy.Valid)
+	return approval
+}
+
+func createPendingScheduledTransferRun(t *testing.T, approval TransferApproval, account1, account2 Account) ScheduledTransferRun {
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	run, err := testStore.CreateScheduledTransferRun(context.Background(), CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Status:              util.ScheduleRunStatusPending,
+		ScheduledFor:        scheduledTransfer.NextRunAt,
+		ApprovalID:          pgtype.Int8{Int64: approval.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	return run
+}
+
+func getScheduledTransferRun(t *testing.T, run ScheduledTransferRun) ScheduledTransferRun {
+	runs, err := testStore.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: run.ScheduledTransferID,
+		Limit:               1,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	return runs[0]
+}
+
+func TestApproveTransferApprovalTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	reviewer := createRandomUser(t)
+	approval := createRandomTransferApproval(t, account1, account2, 10, time.Now().Add(time.Hour))
+	run := createPendingScheduledTransferRun(t, approval, account1, account2)
+
+	// the requester can't review their own transfer
+	_, err := testStore.ReviewTransferApprovalTx(context.Background(), ReviewTransferApprovalTxParams{
+		ID:       approval.ID,
+		Reviewer: account1.Owner,
+		Approve:  true,
+		Reason:   util.RandomString(12),
+	})
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	reason := util.RandomString(12)
+	result, err := testStore.ReviewTransferApprovalTx(context.Background(), ReviewTransferApprovalTxParams{
+		ID:       approval.ID,
+		Reviewer: reviewer.Username,
+		Approve:  true,
+		Reason:   reason,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ApprovalStatusApproved, result.Approval.Status)
+	require.Equal(t, reviewer.Username, result.Approval.ReviewedBy.String)
+	require.Equal(t, reason, result.Approval.Reason.String)
+	require.True(t, result.Approval.ReviewedAt.Valid)
+
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Approval.TransferID.Int64)
+	require.Equal(t, account1.Balance-10, result.Transfer.FromAccount.Balance)
+	require.Equal(t, account2.Balance+10, result.Transfer.ToAccount.Balance)
+
+	// the scheduled run waiting for the approval is completed with its transfer
+	run = getScheduledTransferRun(t, run)
+	require.Equal(t, util.ScheduleRunStatusCompleted, run.Status)
+	require.Equal(t, result.Transfer.Transfer.ID, run.TransferID.Int64)
+	require.False(t, run.Error.Valid)
+
+	_, err = testStore.ReviewTransferApprovalTx(context.Background(), ReviewTransferApprovalTxParams{
+		ID:       approval.ID,
+		Reviewer: reviewer.Username,
+		Approve:  false,
+		Reason:   reason,
+	})
+	require.ErrorIs(t, err, ErrApprovalNotPending)
+}
+
+func TestRejectTransferApprovalTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	reviewer := createRandomUser(t)
+	approval := createRandomTransferApproval(t, account1, account2, 10, time.Now().Add(time.Hour))
+	run := createPendingScheduledTransferRun(t, approval, account1, account2)
+
+	result, err := testStore.ReviewTransferApprovalTx(context.Background(), ReviewTransferApprovalTxParams{
+		ID:       approval.ID,
+		Reviewer: reviewer.Username,
+		Approve:  false,
+		Reason:   util.RandomString(12),
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ApprovalStatusRejected, result.Approval.Status)
+	require.False(t, result.Approval.TransferID.Valid)
+	require.Nil(t, result.Transfer)
+
+	account, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+
+	run = getScheduledTransferRun(t, run)
+	require.Equal(t, util.ScheduleRunStatusFailed, run.Status)
+	require.False(t, run.TransferID.Valid)
+	require.Contains(t, run.Error.String, result.Approval.Reason.String)
+}
+
+func TestApproveTransferApprovalTxInsufficientBalance(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	reviewer := createRandomUser(t)
+	approval := createRandomTransferApproval(t, account1, account2, account1.Balance+1, time.Now().Add(time.Hour))
+
+	_, err := testStore.ReviewTransferApprovalTx(context.Background(), ReviewTransferApprovalTxParams{
+		ID:       approval.ID,
+		Reviewer: reviewer.Username,
+		Approve:  true,
+		Reason:   util.RandomString(12),
+	})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+
+	// the approval stays pending so it can still be rejected
+	approval, err = testStore.GetTransferApproval(context.Background(), approval.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.ApprovalStatusPending, approval.Status)
+}
+
+func TestExpireTransferApprovals(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	reviewer := createRandomUser(t)
+	expired := createRandomTransferApproval(t, account1, account2, 10, time.Now().Add(-time.Minute))
+	pending := createRandomTransferApproval(t, account1, account2, 10, time.Now().Add(time.Hour))
+	expiredRun := createPendingScheduledTransferRun(t, expired, account1, account2)
+	pendingRun := createPendingScheduledTransferRun(t, pending, account1, account2)
+
+	// an approval past its expiry can't be approved even before the sweep marks it
+	_, err := testStore.ReviewTransferApprovalTx(context.Background(), ReviewTransferApprovalTxParams{
+		ID:       expired.ID,
+		Reviewer: reviewer.Username,
+		Approve:  true,
+		Reason:   util.RandomString(12),
+	})
+	require.ErrorIs(t, err, ErrApprovalNotPending)
+
+	approvals, err := testStore.ExpireTransferApprovalsTx(context.Background())
+	require.NoError(t, err)
+
+	ids := make([]int64, len(approvals))
+	for i, approval := range approvals {
+		require.Equal(t, util.ApprovalStatusExpired, approval.Status)
+		ids[i] = approval.ID
+	}
+	require.Contains(t, ids, expired.ID)
+	require.NotContains(t, ids, pending.ID)
+
+	// only the runs waiting for an expired approval fail
+	expiredRun = getScheduledTransferRun(t, expiredRun)
+	require.Equal(t, util.ScheduleRunStatusFailed, expiredRun.Status)
+	require.True(t, expiredRun.Error.Valid)
+	pendingRun = getScheduledTransferRun(t, pendingRun)
+	require.Equal(t, util.ScheduleRunStatusPending, pendingRun.Status)
+
+	listed, err := testStore.ListTransferApprovals(context.Background(), ListTransferApprovalsParams{
+		Status:     pgtype.Text{String: util.ApprovalStatusPending, Valid: true},
+		PageLimit:  1000,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+	for _, approval := range listed {
+		require.Equal(t, util.ApprovalStatusPending, approval.Status)
+	}
+}
+
+func TestApproveTransferApprovalTxCapturesHold(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	reviewer := createRandomUser(t)
+	hold := authorizeRandomHold(t, account1, account2, 10)
+
+	var rate pgtype.Numeric
+	require.NoError(t, rate.Scan("1"))
+
+	approval, err := testStore.CreateTransferApproval(context.Background(), CreateTransferApprovalParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        8,
+		ToAmount:      8,
+		ExchangeRate:  rate,
+		RequestedBy:   account1.Owner,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		HoldID:        pgtype.Int8{Int64: hold.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, hold.ID, approval.HoldID.Int64)
+
+	result, err := testStore.ReviewTransferApprovalTx(context.Background(), ReviewTransferApprovalTxParams{
+		ID:       approval.ID,
+		Reviewer: reviewer.Username,
+		Approve:  true,
+		Reason:   util.RandomString(12),
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ApprovalStatusApproved, result.Approval.Status)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, account1.Balance-8, result.Transfer.FromAccount.Balance)
+
+	// the approved transfer captured the hold, which no longer reserves the rest of the funds
+	captured, err := testStore.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.HoldStatusCaptured, captured.Status)
+	require.Equal(t, int64(8), captured.CapturedAmount.Int64)
+	require.Equal(t, result.Transfer.Transfer.ID, captured.TransferID.Int64)
+}
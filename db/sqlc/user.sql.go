@@ -19,7 +19,7 @@ INSERT INTO users (
   email
 ) VALUES (
   $1, $2, $3, $4
-) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, provider
+) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, provider, is_locked
 `
 
 type CreateUserParams struct {
@@ -46,12 +46,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.Role,
 		&i.Provider,
+		&i.IsLocked,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, provider FROM users
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, provider, is_locked FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -67,10 +68,124 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.CreatedAt,
 		&i.Role,
 		&i.Provider,
+		&i.IsLocked,
 	)
 	return i, err
 }
 
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, provider, is_locked FROM users
+WHERE username = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetUserForUpdate(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserForUpdate, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Provider,
+		&i.IsLocked,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, provider, is_locked FROM users
+WHERE ($1::varchar IS NULL OR role = $1)
+  AND ($2::varchar IS NULL
+    OR username ILIKE '%' || $2 || '%'
+    OR full_name ILIKE '%' || $2 || '%'
+    OR email ILIKE '%' || $2 || '%')
+ORDER BY username
+LIMIT $3
+OFFSET $4
+`
+
+type ListUsersParams struct {
+	Role       pgtype.Text `json:"role"`
+	Search     pgtype.Text `json:"search"`
+	PageLimit  int32       `json:"page_limit"`
+	PageOffset int32       `json:"page_offset"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Role,
+		arg.Search,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FullName,
+			&i.Email,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.Role,
+			&i.Provider,
+			&i.IsLocked,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByRole = `-- name: ListUsersByRole :many
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, provider, is_locked FROM users
+WHERE role = $1
+ORDER BY username
+`
+
+func (q *Queries) ListUsersByRole(ctx context.Context, role string) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsersByRole, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FullName,
+			&i.Email,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.Role,
+			&i.Provider,
+			&i.IsLocked,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET
@@ -80,7 +195,7 @@ SET
   email = COALESCE($4, email)
 WHERE
   username = $5
-RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, provider
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, provider, is_locked
 `
 
 type UpdateUserParams struct {
@@ -109,6 +224,65 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.CreatedAt,
 		&i.Role,
 		&i.Provider,
+		&i.IsLocked,
+	)
+	return i, err
+}
+
+const updateUserLock = `-- name: UpdateUserLock :one
+UPDATE users
+SET is_locked = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, provider, is_locked
+`
+
+type UpdateUserLockParams struct {
+	IsLocked bool   `json:"is_locked"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) UpdateUserLock(ctx context.Context, arg UpdateUserLockParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserLock, arg.IsLocked, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Provider,
+		&i.IsLocked,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, provider, is_locked
+`
+
+type UpdateUserRoleParams struct {
+	Role     string `json:"role"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.Role, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Provider,
+		&i.IsLocked,
 	)
 	return i, err
 }
@@ -128,7 +302,7 @@ SET
   email = EXCLUDED.email, 
   full_name = EXCLUDED.full_name,
   hashed_password = EXCLUDED.hashed_password
-RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, provider
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, provider, is_locked
 `
 
 type UpsertUserParams struct {
@@ -157,6 +331,7 @@ func (q *Queries) UpsertUser(ctx context.Context, arg UpsertUserParams) (User, e
 		&i.CreatedAt,
 		&i.Role,
 		&i.Provider,
+		&i.IsLocked,
 	)
 	return i, err
 }
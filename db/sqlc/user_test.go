@@ -105,3 +105,57 @@ func TestUpdateUserAllFields(t *testing.T) {
 	require.NotEqual(t, oldUser.FullName, updatedUser.FullName)
 	require.Equal(t, newFullName, updatedUser.FullName)
 }
+
+func TestListUsers(t *testing.T) {
+	user := createRandomUser(t)
+
+	users, err := testStore.ListUsers(context.Background(), ListUsersParams{
+		Role:       pgtype.Text{String: util.DepositorRole, Valid: true},
+		Search:     pgtype.Text{String: user.Email, Valid: true},
+		PageLimit:  5,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, user.Username, users[0].Username)
+
+	users, err = testStore.ListUsers(context.Background(), ListUsersParams{
+		Role:       pgtype.Text{String: util.BankerRole, Valid: true},
+		Search:     pgtype.Text{String: user.Username, Valid: true},
+		PageLimit:  5,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, users)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	user := createRandomUser(t)
+	require.Equal(t, util.DepositorRole, user.Role)
+
+	updatedUser, err := testStore.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Role:     util.BankerRole,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.BankerRole, updatedUser.Role)
+}
+
+func TestUpdateUserLock(t *testing.T) {
+	user := createRandomUser(t)
+	require.False(t, user.IsLocked)
+
+	lockedUser, err := testStore.UpdateUserLock(context.Background(), UpdateUserLockParams{
+		IsLocked: true,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, lockedUser.IsLocked)
+
+	unlockedUser, err := testStore.UpdateUserLock(context.Background(), UpdateUserLockParams{
+		IsLocked: false,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.False(t, unlockedUser.IsLocked)
+}
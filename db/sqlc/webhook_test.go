@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhook(t *testing.T, owner string, eventTypes ...string) WebhookSubscription {
+	arg := CreateWebhookSubscriptionParams{
+		Owner:      owner,
+		Url:        "https://example.com/" + util.RandomString(6),
+		Secret:     util.RandomString(32),
+		EventTypes: eventTypes,
+	}
+	subscription, err := testStore.CreateWebhookSubscription(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.EventTypes, subscription.EventTypes)
+	require.True(t, subscription.IsActive)
+	return subscription
+}
+
+func TestListWebhookSubscriptionsForEvent(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	subscription1 := createRandomWebhook(t, account1.Owner, EventTransferCreated)
+	subscription2 := createRandomWebhook(t, account2.Owner, EventTransferCreated, EventEntryCreated)
+	createRandomWebhook(t, account2.Owner, EventAccountStatusChanged)
+
+	subscriptions, err := testStore.ListWebhookSubscriptionsForEvent(context.Background(), ListWebhookSubscriptionsForEventParams{
+		EventType:  EventTransferCreated,
+		AccountIds: []int64{account1.ID, account2.ID},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []WebhookSubscription{subscription1, subscription2}, subscriptions)
+
+	subscriptions, err = testStore.ListWebhookSubscriptionsForEvent(context.Background(), ListWebhookSubscriptionsForEventParams{
+		EventType:  EventEntryCreated,
+		AccountIds: []int64{account1.ID},
+	})
+	require.NoError(t, err)
+	require.Empty(t, subscriptions)
+}
+
+func TestRecordWebhookSubscriptionFailure(t *testing.T) {
+	user := createRandomUser(t)
+	subscription := createRandomWebhook(t, user.Username, EventTransferCreated)
+
+	arg := RecordWebhookSubscriptionFailureParams{ID: subscription.ID, MaxFailures: 2}
+	subscription, err := testStore.RecordWebhookSubscriptionFailure(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, subscription.IsActive)
+	require.Equal(t, int32(1), subscription.ConsecutiveFailures)
+
+	subscription, err = testStore.RecordWebhookSubscriptionFailure(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, subscription.IsActive)
+	require.True(t, subscription.DisabledAt.Valid)
+
+	subscription, err = testStore.EnableWebhookSubscription(context.Background(), subscription.ID)
+	require.NoError(t, err)
+	require.True(t, subscription.IsActive)
+	require.Zero(t, subscription.ConsecutiveFailures)
+	require.False(t, subscription.DisabledAt.Valid)
+}
+
+func TestCreateWebhookDelivery(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	subscription := createRandomWebhook(t, account1.Owner, EventTransferCreated)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.NoError(t, err)
+	events := relayAll(t)
+	require.NotEmpty(t, events)
+
+	arg := CreateWebhookDeliveryParams{
+		SubscriptionID: subscription.ID,
+		EventID:        events[0].ID,
+		EventType:      events[0].EventType,
+	}
+	delivery1, err := testStore.CreateWebhookDelivery(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.DeliveryStatusPending, delivery1.Status)
+
+	delivery1, err = testStore.RecordWebhookDeliveryAttempt(context.Background(), RecordWebhookDeliveryAttemptParams{
+		ID:     delivery1.ID,
+		Status: util.DeliveryStatusSucceeded,
+	})
+	require.NoError(t, err)
+	require.True(t, delivery1.DeliveredAt.Valid)
+	require.Equal(t, int32(1), delivery1.Attempts)
+
+	// an event relayed twice doesn't make a second delivery
+	delivery2, err := testStore.CreateWebhookDelivery(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, delivery1.ID, delivery2.ID)
+	require.Equal(t, util.DeliveryStatusSucceeded, delivery2.Status)
+
+	// a settled delivery can be sent again, but not while it is still pending
+	delivery2, err = testStore.ResetWebhookDelivery(context.Background(), delivery1.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.DeliveryStatusPending, delivery2.Status)
+	require.Equal(t, int32(1), delivery2.Redeliveries)
+
+	_, err = testStore.ResetWebhookDelivery(context.Background(), delivery1.ID)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestListStaleWebhookDeliveries(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	subscription := createRandomWebhook(t, account1.Owner, EventTransferCreated)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		FromAmount:    10,
+		ToAmount:      10,
+	})
+	require.NoError(t, err)
+	events := relayAll(t)
+	require.NotEmpty(t, events)
+
+	delivery, err := testStore.CreateWebhookDelivery(context.Background(), CreateWebhookDeliveryParams{
+		SubscriptionID: subscription.ID,
+		EventID:        events[0].ID,
+		EventType:      events[0].EventType,
+	})
+	require.NoError(t, err)
+
+	// a delivery only just recorded isn't stale yet
+	stale, err := testStore.ListStaleWebhookDeliveries(context.Background(), ListStaleWebhookDeliveriesParams{
+		UpdatedAt: delivery.UpdatedAt,
+		Limit:     1000,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, stale, delivery.ID)
+
+	stale, err = testStore.ListStaleWebhookDeliveries(context.Background(), ListStaleWebhookDeliveriesParams{
+		UpdatedAt: time.Now().Add(time.Minute),
+		Limit:     1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, stale, delivery.ID)
+
+	// a settled delivery is never queued again
+	_, err = testStore.RecordWebhookDeliveryAttempt(context.Background(), RecordWebhookDeliveryAttemptParams{
+		ID:     delivery.ID,
+		Status: util.DeliveryStatusSucceeded,
+	})
+	require.NoError(t, err)
+
+	stale, err = testStore.ListStaleWebhookDeliveries(context.Background(), ListStaleWebhookDeliveriesParams{
+		UpdatedAt: time.Now().Add(time.Minute),
+		Limit:     1000,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, stale, delivery.ID)
+}
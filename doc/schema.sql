@@ -11,6 +11,7 @@ CREATE TABLE "users" (
   "email" varchar UNIQUE NOT NULL,
   "is_email_verified" bool NOT NULL DEFAULT false,
   "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01',
+  "is_locked" bool NOT NULL DEFAULT false,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -19,13 +20,17 @@ CREATE TABLE "accounts" (
   "owner" varchar NOT NULL,
   "balance" bigint NOT NULL,
   "currency" varchar NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "status" varchar NOT NULL DEFAULT 'active',
+  CHECK ("status" IN ('active', 'frozen', 'closed'))
 );
 
 CREATE TABLE "entries" (
   "id" bigserial PRIMARY KEY,
   "account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
+  "transfer_id" bigint,
+  "cash_transaction_id" bigint,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -34,31 +39,433 @@ CREATE TABLE "transfers" (
   "from_account_id" bigint NOT NULL,
   "to_account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "status" varchar NOT NULL DEFAULT 'completed',
+  "reversal_of" bigint UNIQUE,
+  "from_currency" varchar NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "to_amount" bigint,
+  "exchange_rate" numeric,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "initiated_by" varchar,
+  CHECK ("status" IN ('completed', 'reversed'))
 );
 
 CREATE TABLE "currencies" (
   "currency" varchar PRIMARY KEY,
-  "rate" float NOT NULL,
+  "rate" numeric NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "currency_rates" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "rate" numeric NOT NULL,
+  "effective_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "idempotency_keys" (
+  "username" varchar NOT NULL,
+  "idempotency_key" varchar NOT NULL,
+  "request_path" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_code" int,
+  "response_body" bytea,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  PRIMARY KEY ("username", "idempotency_key")
+);
+
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "frequency" varchar NOT NULL,
+  "next_run_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "remaining_runs" int,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "anchor_at" timestamptz NOT NULL
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "transfer_id" bigint,
+  "status" varchar NOT NULL,
+  "error" varchar,
+  "scheduled_for" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "approval_id" bigint,
+  CHECK ("status" IN ('claimed', 'completed', 'failed', 'pending'))
+);
+
+CREATE TABLE "rounding_remainders" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "amount" numeric NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "cash_transactions" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "cash_account_id" bigint NOT NULL,
+  "type" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "reference" varchar UNIQUE NOT NULL,
+  "reason" varchar NOT NULL,
+  "banker" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "role" varchar,
+  "username" varchar,
+  "currency" varchar NOT NULL,
+  "max_single" bigint,
+  "max_daily" bigint,
+  "max_monthly" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK (("role" IS NULL) <> ("username" IS NULL)),
+  CHECK ("max_single" >= 0 AND "max_daily" >= 0 AND "max_monthly" >= 0),
+  CHECK ("username" IS NOT NULL OR ("max_single" > 0 AND "max_daily" > 0 AND "max_monthly" > 0))
+);
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "captured_amount" bigint,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "description" varchar NOT NULL,
+  "created_by" varchar NOT NULL,
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "outbox_events" (
+  "id" bigserial PRIMARY KEY,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "published_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "claimed_until" timestamptz,
+  "dead_lettered_at" timestamptz
+);
+
+CREATE TABLE "webhook_subscriptions" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "event_types" varchar[] NOT NULL,
+  "is_active" boolean NOT NULL DEFAULT true,
+  "consecutive_failures" int NOT NULL DEFAULT 0,
+  "disabled_at" timestamptz,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "subscription_id" bigint NOT NULL,
+  "event_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "redeliveries" int NOT NULL DEFAULT 0,
+  "response_status" int,
+  "last_error" varchar,
+  "delivered_at" timestamptz,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "actor_role" varchar NOT NULL,
+  "ip" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "method" varchar NOT NULL,
+  "route" varchar NOT NULL,
+  "resource_type" varchar NOT NULL,
+  "resource_id" varchar,
+  "status_code" int NOT NULL,
+  "request" jsonb,
+  "changes" jsonb,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "account_members" (
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'invited',
+  "invited_by" varchar NOT NULL,
+  "accepted_at" timestamptz,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "username")
+);
+
+CREATE TABLE "permissions" (
+  "name" varchar PRIMARY KEY,
+  "description" varchar NOT NULL
+);
+
+CREATE TABLE "role_permissions" (
+  "role" varchar NOT NULL,
+  "permission" varchar NOT NULL,
+  "scope" varchar NOT NULL DEFAULT 'own',
+  PRIMARY KEY ("role", "permission")
+);
+
+CREATE TABLE "transfer_approvals" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "exchange_rate" numeric NOT NULL,
+  "rounding_remainder" numeric,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "requested_by" varchar NOT NULL,
+  "reviewed_by" varchar,
+  "reason" varchar,
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "reviewed_at" timestamptz,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "hold_id" bigint,
+  "fraud_decision_id" bigint
+);
+
+CREATE TABLE "fraud_decisions" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "decision" varchar NOT NULL,
+  "rules" jsonb NOT NULL DEFAULT '[]',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "sanctions_cases" (
+  "id" bigserial PRIMARY KEY,
+  "action" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "account_id" bigint,
+  "screened_name" varchar NOT NULL,
+  "list_entry_id" varchar NOT NULL,
+  "matched_name" varchar NOT NULL,
+  "program" varchar NOT NULL,
+  "score" double precision NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'open',
+  "reviewed_by" varchar,
+  "reason" varchar,
+  "reviewed_at" timestamptz,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
 CREATE INDEX ON "accounts" ("owner");
 
-CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
+CREATE UNIQUE INDEX ON "accounts" ("owner", "currency") WHERE "status" <> 'closed';
 
 CREATE INDEX ON "entries" ("account_id");
 
+CREATE INDEX ON "entries" ("transfer_id");
+
 CREATE INDEX ON "transfers" ("from_account_id");
 
 CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX ON "transfers" ("initiated_by", "created_at");
+
+CREATE INDEX ON "currency_rates" ("currency", "effective_at");
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("created_at") WHERE "status" = 'claimed';
+
+CREATE INDEX ON "scheduled_transfer_runs" ("approval_id");
+
+CREATE INDEX ON "rounding_remainders" ("transfer_id");
+
+CREATE INDEX ON "cash_transactions" ("account_id");
+
+CREATE INDEX ON "entries" ("cash_transaction_id");
+
+CREATE INDEX ON "entries" ("account_id", "id");
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("role", "currency") WHERE "username" IS NULL;
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("username", "currency") WHERE "role" IS NULL;
+
+CREATE INDEX ON "holds" ("account_id", "status");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+CREATE INDEX ON "outbox_events" ("id") WHERE "published_at" IS NULL AND "dead_lettered_at" IS NULL;
+
+CREATE INDEX ON "webhook_subscriptions" ("owner");
+
+CREATE UNIQUE INDEX ON "webhook_deliveries" ("subscription_id", "event_id");
+
+CREATE INDEX ON "webhook_deliveries" ("updated_at") WHERE "status" = 'pending';
+
+CREATE INDEX ON "audit_log" ("actor", "id");
+
+CREATE INDEX ON "audit_log" ("resource_type", "resource_id", "id");
+
+CREATE INDEX ON "audit_log" ("created_at");
+
+CREATE INDEX ON "account_members" ("username");
+
+CREATE INDEX ON "transfer_approvals" ("status", "expires_at");
+
+CREATE INDEX ON "transfer_approvals" ("requested_by");
+
+CREATE INDEX ON "fraud_decisions" ("decision", "created_at");
+
+CREATE INDEX ON "fraud_decisions" ("username");
+
+CREATE INDEX ON "sanctions_cases" ("status", "created_at");
+
+CREATE INDEX ON "sanctions_cases" ("screened_name", "list_entry_id");
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed';
+
 COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
 
 COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
 
+COMMENT ON COLUMN "transfers"."status" IS 'pending until it settles, then completed or failed, and reversed once a banker reversed it';
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in to_currency';
+
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'to_currency major units per from_currency major unit';
+
+COMMENT ON COLUMN "transfers"."initiated_by" IS 'the user the transfer is charged to against the limits, null for reversals';
+
+COMMENT ON COLUMN "currency_rates"."rate" IS 'base currency units per major unit';
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'sha256 of method, route and body';
+
+COMMENT ON COLUMN "scheduled_transfers"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "scheduled_transfers"."frequency" IS 'once, daily, weekly or monthly';
+
+COMMENT ON COLUMN "scheduled_transfers"."remaining_runs" IS 'null means unlimited';
+
+COMMENT ON COLUMN "scheduled_transfers"."anchor_at" IS 'first run of the schedule, monthly runs keep its day of month instead of drifting to a clamped one';
+
+COMMENT ON COLUMN "rounding_remainders"."amount" IS 'exact minus rounded amount, in minor units';
+
+COMMENT ON COLUMN "cash_transactions"."type" IS 'deposit or withdrawal';
+
+COMMENT ON COLUMN "cash_transactions"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "transfer_limits"."role" IS 'set for the default limits of a role';
+
+COMMENT ON COLUMN "transfer_limits"."username" IS 'set for the overrides of a single user';
+
+COMMENT ON COLUMN "transfer_limits"."max_single" IS 'null inherits the role default or means unlimited, 0 in an override means unlimited';
+
+COMMENT ON COLUMN "holds"."amount" IS 'reserved in the currency of account_id, must be positive';
+
+COMMENT ON COLUMN "holds"."captured_amount" IS 'set on capture, the rest of the hold is released';
+
+COMMENT ON COLUMN "holds"."status" IS 'active, captured, released or expired';
+
+COMMENT ON COLUMN "outbox_events"."aggregate_type" IS 'transfer, entry, account or cash_transaction';
+
+COMMENT ON COLUMN "outbox_events"."event_type" IS 'e.g. transfer.created or account.balance_changed';
+
+COMMENT ON COLUMN "outbox_events"."published_at" IS 'null until a relay published the event';
+
+COMMENT ON COLUMN "outbox_events"."claimed_until" IS 'a relay is publishing the event, other relays skip it until then';
+
+COMMENT ON COLUMN "outbox_events"."dead_lettered_at" IS 'the event failed too many times and is no longer relayed';
+
+COMMENT ON COLUMN "webhook_subscriptions"."secret" IS 'HMAC-SHA256 key of the X-Go2Bank-Signature header';
+
+COMMENT ON COLUMN "webhook_subscriptions"."consecutive_failures" IS 'deliveries that exhausted their retries in a row, reset by a success';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded or failed';
+
+COMMENT ON COLUMN "webhook_deliveries"."redeliveries" IS 'times the owner sent the delivery again, each one is queued as a new task';
+
+COMMENT ON COLUMN "audit_log"."route" IS 'route pattern, e.g. /api/auth/accounts/:id/freeze';
+
+COMMENT ON COLUMN "audit_log"."request" IS 'request body with secrets redacted';
+
+COMMENT ON COLUMN "audit_log"."changes" IS 'changed fields as {"field": {"before": ..., "after": ...}}, secrets redacted';
+
+COMMENT ON COLUMN "account_members"."role" IS 'owner, can_transfer or view_only';
+
+COMMENT ON COLUMN "account_members"."status" IS 'invited until the user accepts, then active';
+
+COMMENT ON COLUMN "role_permissions"."scope" IS 'own for resources of the user only, any for every resource';
+
+COMMENT ON COLUMN "transfer_approvals"."amount" IS 'debited from from_account_id, the amounts and rate are locked when the transfer is requested';
+
+COMMENT ON COLUMN "transfer_approvals"."status" IS 'pending, approved, rejected or expired';
+
+COMMENT ON COLUMN "transfer_approvals"."reviewed_by" IS 'a banker other than requested_by';
+
+COMMENT ON COLUMN "fraud_decisions"."amount" IS 'debited from from_account_id, in currency';
+
+COMMENT ON COLUMN "fraud_decisions"."decision" IS 'allow, hold or block, the strictest of the triggered rules';
+
+COMMENT ON COLUMN "fraud_decisions"."rules" IS 'the triggered rules with their decision and detail';
+
+COMMENT ON COLUMN "transfer_approvals"."fraud_decision_id" IS 'the screening of the transfer, which may be why it is held';
+
+COMMENT ON COLUMN "sanctions_cases"."action" IS 'registration, transfer or profile_update, the action that was blocked';
+
+COMMENT ON COLUMN "sanctions_cases"."username" IS 'the user registering, changing the full name or sending the transfer, not a user yet for a registration';
+
+COMMENT ON COLUMN "sanctions_cases"."account_id" IS 'the recipient account of a transfer';
+
+COMMENT ON COLUMN "sanctions_cases"."score" IS 'Jaro-Winkler similarity of the screened and matched names, from 0 to 1';
+
+COMMENT ON COLUMN "sanctions_cases"."status" IS 'open, cleared as a false positive or confirmed';
+
+COMMENT ON COLUMN "idempotency_keys"."expires_at" IS 'the key can be reused and is swept after this time, even while still in progress';
+
+COMMENT ON COLUMN "transfer_approvals"."hold_id" IS 'the hold an approved transfer captures';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."status" IS 'claimed until the run is posted or fails, then completed, failed or pending';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."approval_id" IS 'the approval a pending run waits for';
+
 ALTER TABLE "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
 
 ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -66,3 +473,83 @@ ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id"
 ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("initiated_by") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "rounding_remainders" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "cash_transactions" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_transactions" ADD FOREIGN KEY ("cash_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_transactions" ADD FOREIGN KEY ("banker") REFERENCES "users" ("username");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("cash_transaction_id") REFERENCES "cash_transactions" ("id");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("currency");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("event_id") REFERENCES "outbox_events" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username");
+
+ALTER TABLE "role_permissions" ADD FOREIGN KEY ("permission") REFERENCES "permissions" ("name");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("fraud_decision_id") REFERENCES "fraud_decisions" ("id");
+
+ALTER TABLE "sanctions_cases" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "sanctions_cases" ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("hold_id") REFERENCES "holds" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("approval_id") REFERENCES "transfer_approvals" ("id");
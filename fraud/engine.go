@@ -0,0 +1,126 @@
+// Package fraud screens transfers with a set of rules before any money moves.
+package fraud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+)
+
+// Decision is what happens to a screened transfer
+type Decision string
+
+const (
+	// Allow lets the transfer through, a triggered rule that allows is only recorded
+	Allow Decision = "allow"
+	// Hold queues the transfer for a banker to approve or reject
+	Hold Decision = "hold"
+	// Block rejects the transfer
+	Block Decision = "block"
+)
+
+// decisionRanks orders the decisions, the strictest triggered rule decides the transfer
+var decisionRanks = map[Decision]int{
+	Allow: 0,
+	Hold:  1,
+	Block: 2,
+}
+
+// Transfer is what the rules look at, Amount is debited from FromAccount in its currency
+type Transfer struct {
+	Username    string
+	FromAccount db.Account
+	ToAccount   db.Account
+	Amount      int64
+}
+
+// Hit is a rule triggered by a transfer
+type Hit struct {
+	Rule     string   `json:"rule"`
+	Decision Decision `json:"decision"`
+	Detail   string   `json:"detail"`
+}
+
+// Rule checks a transfer, it returns false when it isn't triggered
+type Rule interface {
+	Name() string
+	Check(ctx context.Context, store db.Querier, transfer Transfer) (Hit, bool, error)
+}
+
+// Screening is the recorded outcome of the rules for a transfer
+type Screening struct {
+	DecisionID int64
+	Decision   Decision
+	Hits       []Hit
+}
+
+// Engine runs every rule on a transfer and records the decision
+type Engine struct {
+	store db.Querier
+	rules []Rule
+}
+
+// NewEngine creates an engine running rules in order
+func NewEngine(store db.Querier, rules ...Rule) *Engine {
+	return &Engine{
+		store: store,
+		rules: rules,
+	}
+}
+
+// Evaluate runs the rules without recording anything, a transfer no rule triggers is allowed
+func (engine *Engine) Evaluate(ctx context.Context, transfer Transfer) (Decision, []Hit, error) {
+	decision := Allow
+	hits := []Hit{}
+
+	for _, rule := range engine.rules {
+		hit, triggered, err := rule.Check(ctx, engine.store, transfer)
+		if err != nil {
+			return decision, nil, fmt.Errorf("failed to check fraud rule %s: %w", rule.Name(), err)
+		}
+		if !triggered {
+			continue
+		}
+
+		hits = append(hits, hit)
+		if decisionRanks[hit.Decision] > decisionRanks[decision] {
+			decision = hit.Decision
+		}
+	}
+
+	return decision, hits, nil
+}
+
+// Screen evaluates the rules and records the decision with the triggered rules for bankers to review
+func (engine *Engine) Screen(ctx context.Context, transfer Transfer) (Screening, error) {
+	decision, hits, err := engine.Evaluate(ctx, transfer)
+	if err != nil {
+		return Screening{}, err
+	}
+
+	rules, err := json.Marshal(hits)
+	if err != nil {
+		return Screening{}, fmt.Errorf("failed to marshal triggered fraud rules: %w", err)
+	}
+
+	record, err := engine.store.CreateFraudDecision(ctx, db.CreateFraudDecisionParams{
+		Username:      transfer.Username,
+		FromAccountID: transfer.FromAccount.ID,
+		ToAccountID:   transfer.ToAccount.ID,
+		Amount:        transfer.Amount,
+		Currency:      transfer.FromAccount.Currency,
+		Decision:      string(decision),
+		Rules:         rules,
+	})
+	if err != nil {
+		return Screening{}, fmt.Errorf("failed to record fraud decision: %w", err)
+	}
+
+	return Screening{
+		DecisionID: record.ID,
+		Decision:   decision,
+		Hits:       hits,
+	}, nil
+}
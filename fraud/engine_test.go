@@ -0,0 +1,284 @@
+package fraud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomTransfer(fromCurrency, toCurrency string) Transfer {
+	return Transfer{
+		Username:    util.RandomOwner(),
+		FromAccount: db.Account{ID: util.RandomInt(1, 1000), Currency: fromCurrency},
+		ToAccount:   db.Account{ID: util.RandomInt(1001, 2000), Currency: toCurrency},
+		Amount:      10000,
+	}
+}
+
+func TestVelocity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	transfer := randomTransfer(util.USD, util.USD)
+	rule := Velocity{Limit: 5, Window: 10 * time.Minute, Decision: Block}
+
+	checkArg := func(_ context.Context, arg db.CountTransfersFromParams) {
+		require.Equal(t, transfer.FromAccount.ID, arg.FromAccountID)
+		require.WithinDuration(t, time.Now().Add(-10*time.Minute), arg.Since, time.Second)
+	}
+	gomock.InOrder(
+		store.EXPECT().CountTransfersFrom(gomock.Any(), gomock.Any()).Times(1).Do(checkArg).Return(int64(4), nil),
+		store.EXPECT().CountTransfersFrom(gomock.Any(), gomock.Any()).Times(1).Do(checkArg).Return(int64(5), nil),
+	)
+
+	_, triggered, err := rule.Check(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.False(t, triggered)
+
+	hit, triggered, err := rule.Check(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.True(t, triggered)
+	require.Equal(t, "velocity", hit.Rule)
+	require.Equal(t, Block, hit.Decision)
+}
+
+func TestNewPayee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	transfer := randomTransfer(util.USD, util.USD)
+	rule := NewPayee{Decision: Allow}
+
+	arg := db.CountTransfersBetweenParams{
+		FromAccountID: transfer.FromAccount.ID,
+		ToAccountID:   transfer.ToAccount.ID,
+	}
+	gomock.InOrder(
+		store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(0), nil),
+		store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(3), nil),
+	)
+
+	hit, triggered, err := rule.Check(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.True(t, triggered)
+	require.Equal(t, "new_payee", hit.Rule)
+	require.Equal(t, Allow, hit.Decision)
+
+	_, triggered, err = rule.Check(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.False(t, triggered)
+}
+
+func TestAmountSpike(t *testing.T) {
+	transfer := randomTransfer(util.USD, util.USD)
+	rule := AmountSpike{Factor: 10, History: 20, MinHistory: 3, Decision: Hold}
+
+	testCases := []struct {
+		name      string
+		stats     db.GetTransferAmountStatsRow
+		triggered bool
+	}{
+		{
+			name:      "OverFactor",
+			stats:     db.GetTransferAmountStatsRow{TransferCount: 5, AverageAmount: 999},
+			triggered: true,
+		},
+		{
+			name:      "AtFactor",
+			stats:     db.GetTransferAmountStatsRow{TransferCount: 5, AverageAmount: 1000},
+			triggered: false,
+		},
+		{
+			name:      "NotEnoughHistory",
+			stats:     db.GetTransferAmountStatsRow{TransferCount: 2, AverageAmount: 1},
+			triggered: false,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			arg := db.GetTransferAmountStatsParams{FromAccountID: transfer.FromAccount.ID, History: 20}
+			store.EXPECT().GetTransferAmountStats(gomock.Any(), gomock.Eq(arg)).Times(1).Return(tc.stats, nil)
+
+			hit, triggered, err := rule.Check(context.Background(), store, transfer)
+			require.NoError(t, err)
+			require.Equal(t, tc.triggered, triggered)
+			if triggered {
+				require.Equal(t, Hold, hit.Decision)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	rule := RoundTrip{Window: time.Hour, Decision: Hold}
+
+	// transfers in a single currency are never looked up
+	_, triggered, err := rule.Check(context.Background(), store, randomTransfer(util.USD, util.USD))
+	require.NoError(t, err)
+	require.False(t, triggered)
+
+	transfer := randomTransfer(util.EUR, util.USD)
+	store.EXPECT().
+		CountTransfersBetween(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CountTransfersBetweenParams) (int64, error) {
+			require.Equal(t, transfer.ToAccount.ID, arg.FromAccountID)
+			require.Equal(t, transfer.FromAccount.ID, arg.ToAccountID)
+			require.WithinDuration(t, time.Now().Add(-time.Hour), arg.Since, time.Second)
+			return 1, nil
+		})
+
+	hit, triggered, err := rule.Check(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.True(t, triggered)
+	require.Equal(t, "round_trip", hit.Rule)
+}
+
+type stubRule struct {
+	name     string
+	decision Decision
+	err      error
+}
+
+func (rule stubRule) Name() string {
+	return rule.name
+}
+
+func (rule stubRule) Check(ctx context.Context, store db.Querier, transfer Transfer) (Hit, bool, error) {
+	if rule.err != nil || rule.decision == "" {
+		return Hit{}, false, rule.err
+	}
+	return Hit{Rule: rule.name, Decision: rule.decision}, true, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	transfer := randomTransfer(util.USD, util.USD)
+
+	testCases := []struct {
+		name     string
+		rules    []Rule
+		decision Decision
+		hits     int
+	}{
+		{
+			name:     "NoRules",
+			decision: Allow,
+		},
+		{
+			name:     "NotTriggered",
+			rules:    []Rule{stubRule{name: "quiet"}},
+			decision: Allow,
+		},
+		{
+			name:     "StrictestWins",
+			rules:    []Rule{stubRule{name: "a", decision: Block}, stubRule{name: "b", decision: Hold}, stubRule{name: "c", decision: Allow}},
+			decision: Block,
+			hits:     3,
+		},
+		{
+			name:     "AllowOnlyRecorded",
+			rules:    []Rule{stubRule{name: "a", decision: Allow}, stubRule{name: "b"}},
+			decision: Allow,
+			hits:     1,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			engine := NewEngine(nil, tc.rules...)
+
+			decision, hits, err := engine.Evaluate(context.Background(), transfer)
+			require.NoError(t, err)
+			require.Equal(t, tc.decision, decision)
+			require.Len(t, hits, tc.hits)
+		})
+	}
+}
+
+func TestScreen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	transfer := randomTransfer(util.USD, util.USD)
+	engine := NewEngine(store, stubRule{name: "new_payee", decision: Allow}, stubRule{name: "amount_spike", decision: Hold})
+
+	store.EXPECT().
+		CreateFraudDecision(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateFraudDecisionParams) (db.FraudDecision, error) {
+			require.Equal(t, transfer.Username, arg.Username)
+			require.Equal(t, transfer.FromAccount.ID, arg.FromAccountID)
+			require.Equal(t, transfer.ToAccount.ID, arg.ToAccountID)
+			require.Equal(t, transfer.Amount, arg.Amount)
+			require.Equal(t, util.USD, arg.Currency)
+			require.Equal(t, string(Hold), arg.Decision)
+
+			var hits []Hit
+			require.NoError(t, json.Unmarshal(arg.Rules, &hits))
+			require.Equal(t, []Hit{{Rule: "new_payee", Decision: Allow}, {Rule: "amount_spike", Decision: Hold}}, hits)
+
+			return db.FraudDecision{ID: 7}, nil
+		})
+
+	screening, err := engine.Screen(context.Background(), transfer)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), screening.DecisionID)
+	require.Equal(t, Hold, screening.Decision)
+	require.Len(t, screening.Hits, 2)
+}
+
+func TestScreenRuleError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateFraudDecision(gomock.Any(), gomock.Any()).Times(0)
+
+	engine := NewEngine(store, stubRule{name: "broken", err: errors.New("db is down")})
+	_, err := engine.Screen(context.Background(), randomTransfer(util.USD, util.USD))
+	require.ErrorContains(t, err, "broken")
+}
+
+func TestDefaultRules(t *testing.T) {
+	rules := DefaultRules(util.Config{})
+	require.Len(t, rules, 1)
+	require.Equal(t, "new_payee", rules[0].Name())
+
+	rules = DefaultRules(util.Config{
+		FraudVelocityLimit:   5,
+		FraudVelocityWindow:  10 * time.Minute,
+		FraudAmountFactor:    10,
+		FraudAmountHistory:   20,
+		FraudRoundTripWindow: time.Hour,
+	})
+
+	names := make([]string, len(rules))
+	for i, rule := range rules {
+		names[i] = rule.Name()
+	}
+	require.Equal(t, []string{"new_payee", "velocity", "amount_spike", "round_trip"}, names)
+}
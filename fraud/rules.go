@@ -0,0 +1,161 @@
+package fraud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/util"
+)
+
+// Velocity is triggered when the account already sent Limit transfers within Window
+type Velocity struct {
+	Limit    int64
+	Window   time.Duration
+	Decision Decision
+}
+
+func (rule Velocity) Name() string {
+	return "velocity"
+}
+
+func (rule Velocity) Check(ctx context.Context, store db.Querier, transfer Transfer) (Hit, bool, error) {
+	count, err := store.CountTransfersFrom(ctx, db.CountTransfersFromParams{
+		FromAccountID: transfer.FromAccount.ID,
+		Since:         time.Now().Add(-rule.Window),
+	})
+	if err != nil || count < rule.Limit {
+		return Hit{}, false, err
+	}
+
+	return Hit{
+		Rule:     rule.Name(),
+		Decision: rule.Decision,
+		Detail:   fmt.Sprintf("%d transfers sent in the last %s", count, rule.Window),
+	}, true, nil
+}
+
+// NewPayee is triggered by the first transfer from the account to the destination account
+type NewPayee struct {
+	Decision Decision
+}
+
+func (rule NewPayee) Name() string {
+	return "new_payee"
+}
+
+func (rule NewPayee) Check(ctx context.Context, store db.Querier, transfer Transfer) (Hit, bool, error) {
+	// Since is left zero to count every transfer ever sent
+	count, err := store.CountTransfersBetween(ctx, db.CountTransfersBetweenParams{
+		FromAccountID: transfer.FromAccount.ID,
+		ToAccountID:   transfer.ToAccount.ID,
+	})
+	if err != nil || count > 0 {
+		return Hit{}, false, err
+	}
+
+	return Hit{
+		Rule:     rule.Name(),
+		Decision: rule.Decision,
+		Detail:   fmt.Sprintf("first transfer to account %d", transfer.ToAccount.ID),
+	}, true, nil
+}
+
+// AmountSpike is triggered by an amount over Factor times the average of the latest History transfers
+// of the account. Accounts with fewer than MinHistory transfers have no history to compare with.
+type AmountSpike struct {
+	Factor     int64
+	History    int32
+	MinHistory int64
+	Decision   Decision
+}
+
+func (rule AmountSpike) Name() string {
+	return "amount_spike"
+}
+
+func (rule AmountSpike) Check(ctx context.Context, store db.Querier, transfer Transfer) (Hit, bool, error) {
+	stats, err := store.GetTransferAmountStats(ctx, db.GetTransferAmountStatsParams{
+		FromAccountID: transfer.FromAccount.ID,
+		History:       rule.History,
+	})
+	if err != nil || stats.TransferCount < rule.MinHistory || transfer.Amount <= rule.Factor*stats.AverageAmount {
+		return Hit{}, false, err
+	}
+
+	return Hit{
+		Rule:     rule.Name(),
+		Decision: rule.Decision,
+		Detail: fmt.Sprintf("amount %d is over %d times the average %d of the last %d transfers",
+			transfer.Amount, rule.Factor, stats.AverageAmount, stats.TransferCount),
+	}, true, nil
+}
+
+// RoundTrip is triggered by a cross-currency transfer back to an account that sent money
+// to the source account within Window, which converts the money there and back again
+type RoundTrip struct {
+	Window   time.Duration
+	Decision Decision
+}
+
+func (rule RoundTrip) Name() string {
+	return "round_trip"
+}
+
+func (rule RoundTrip) Check(ctx context.Context, store db.Querier, transfer Transfer) (Hit, bool, error) {
+	if transfer.FromAccount.Currency == transfer.ToAccount.Currency {
+		return Hit{}, false, nil
+	}
+
+	count, err := store.CountTransfersBetween(ctx, db.CountTransfersBetweenParams{
+		FromAccountID: transfer.ToAccount.ID,
+		ToAccountID:   transfer.FromAccount.ID,
+		Since:         time.Now().Add(-rule.Window),
+	})
+	if err != nil || count == 0 {
+		return Hit{}, false, err
+	}
+
+	return Hit{
+		Rule:     rule.Name(),
+		Decision: rule.Decision,
+		Detail: fmt.Sprintf("account %d converted money into %s within the last %s",
+			transfer.ToAccount.ID, transfer.FromAccount.Currency, rule.Window),
+	}, true, nil
+}
+
+// DefaultRules configures the rules from the environment, a rule whose setting is zero is left out.
+// A new payee alone is only recorded, it makes the decisions of the other rules easier to review.
+func DefaultRules(config util.Config) []Rule {
+	rules := []Rule{NewPayee{Decision: Allow}}
+
+	if config.FraudVelocityLimit > 0 {
+		rules = append(rules, Velocity{
+			Limit:    config.FraudVelocityLimit,
+			Window:   config.FraudVelocityWindow,
+			Decision: Block,
+		})
+	}
+
+	if config.FraudAmountFactor > 0 {
+		rules = append(rules, AmountSpike{
+			Factor:     config.FraudAmountFactor,
+			History:    config.FraudAmountHistory,
+			MinHistory: minAmountHistory,
+			Decision:   Hold,
+		})
+	}
+
+	if config.FraudRoundTripWindow > 0 {
+		rules = append(rules, RoundTrip{
+			Window:   config.FraudRoundTripWindow,
+			Decision: Hold,
+		})
+	}
+
+	return rules
+}
+
+// minAmountHistory is how many transfers an account must have sent before its amounts are compared
+const minAmountHistory = 3
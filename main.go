@@ -12,6 +12,10 @@ import (
 	"github.com/RobertChienShiba/simplebank/api"
 	db "github.com/RobertChienShiba/simplebank/db/sqlc"
 	"github.com/RobertChienShiba/simplebank/mail"
+	"github.com/RobertChienShiba/simplebank/outbox"
+	"github.com/RobertChienShiba/simplebank/posting"
+	"github.com/RobertChienShiba/simplebank/rbac"
+	"github.com/RobertChienShiba/simplebank/reconcile"
 	rds "github.com/RobertChienShiba/simplebank/redis"
 	"github.com/RobertChienShiba/simplebank/util"
 	"github.com/RobertChienShiba/simplebank/worker"
@@ -46,10 +50,15 @@ func main() {
 		log.Fatal().Err(err).Msg("cannot connect to db")
 	}
 
-	runDBMigration(config.MigrationURL, config.DBSource)
-
 	store := db.NewStore(connPool)
 
+	// `main reconcile` checks the ledger once and exits, without migrating or starting any server
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(runReconcile(ctx, store))
+	}
+
+	runDBMigration(config.MigrationURL, config.DBSource)
+
 	connOpts, _ := redis.ParseURL(config.RedisURL)
 	connOpts.DB = 0
 
@@ -65,7 +74,19 @@ func main() {
 
 	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
 
-	runTaskProcessor(ctx, waitGroup, config, redisOpt, store, kvStore)
+	publisher, err := outbox.NewPublisher(config.OutboxPublisher, redisConn, config.OutboxStream)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create outbox publisher")
+	}
+
+	runTaskProcessor(ctx, waitGroup, config, redisOpt, store, kvStore, publisher)
+	runTaskScheduler(ctx, waitGroup, config, redisOpt)
+
+	// Load the rates right away instead of waiting for the first periodic sync
+	err = taskDistributor.DistributeTaskSyncExchangeRates(ctx, asynq.Queue(worker.QueueCritical), asynq.Unique(config.RatesSyncInterval))
+	if err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+		log.Error().Err(err).Msg("failed to enqueue initial rates sync")
+	}
 	runGinServer(ctx, waitGroup, config, store, kvStore, taskDistributor)
 
 	err = waitGroup.Wait()
@@ -87,6 +108,25 @@ func runDBMigration(migrationURL string, dbSource string) {
 	log.Info().Msg("db migrated successfully")
 }
 
+// runReconcile prints the reconciliation report and returns the exit code, 1 if the ledger has discrepancies
+func runReconcile(ctx context.Context, store db.Store) int {
+	report, err := reconcile.Run(ctx, store)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to reconcile ledger")
+		return 2
+	}
+
+	if err := report.WriteText(os.Stdout); err != nil {
+		log.Error().Err(err).Msg("failed to write reconciliation report")
+		return 2
+	}
+
+	if !report.Clean() {
+		return 1
+	}
+	return 0
+}
+
 func runTaskProcessor(
 	ctx context.Context,
 	waitGroup *errgroup.Group,
@@ -94,12 +134,18 @@ func runTaskProcessor(
 	redisOpt asynq.RedisClientOpt,
 	store db.Store,
 	kvStore rds.Store,
+	publisher outbox.Publisher,
 ) {
+	controls, err := posting.NewControls(config, store)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create transfer controls")
+	}
+
 	mailer := mail.NewGmailSender(config.EmailSenderName, config.EmailSenderAddress, config.EmailSenderPassword)
-	taskProcessor := worker.NewRedisTaskProcessor(config, redisOpt, store, kvStore, mailer)
+	taskProcessor := worker.NewRedisTaskProcessor(config, redisOpt, store, kvStore, mailer, publisher, controls)
 
 	log.Info().Msg("start task processor")
-	err := taskProcessor.Start()
+	err = taskProcessor.Start()
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to start task processor")
 	}
@@ -115,6 +161,31 @@ func runTaskProcessor(
 	})
 }
 
+func runTaskScheduler(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	config util.Config,
+	redisOpt asynq.RedisClientOpt,
+) {
+	taskScheduler := worker.NewRedisTaskScheduler(config, redisOpt)
+
+	log.Info().Msg("start task scheduler")
+	err := taskScheduler.Start()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to start task scheduler")
+	}
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown task scheduler")
+
+		taskScheduler.Shutdown()
+		log.Info().Msg("task scheduler is stopped")
+
+		return nil
+	})
+}
+
 func runGinServer(ctx context.Context,
 	waitGroup *errgroup.Group,
 	config util.Config,
@@ -122,7 +193,13 @@ func runGinServer(ctx context.Context,
 	kvStore rds.Store,
 	taskDistributor worker.TaskDistributor,
 ) {
-	server, err := api.NewServer(config, store, kvStore, taskDistributor)
+	// The policy is read once, restart the server to apply changes to role_permissions
+	policy, err := rbac.Load(ctx, store)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load rbac policy")
+	}
+
+	server, err := api.NewServer(config, store, kvStore, taskDistributor, policy)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot create server")
 	}
@@ -0,0 +1,152 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RateScale is the number of decimals used when an exchange rate or a remainder is rendered as a decimal
+const RateScale = 12
+
+// Conversion is the outcome of converting an amount into another currency
+type Conversion struct {
+	Source Money
+	Target Money
+	// Rate is the number of target major units per source major unit
+	Rate *big.Rat
+	// Remainder is the exact target amount minus the rounded one, in target minor units
+	Remainder *big.Rat
+}
+
+// Convert converts an amount into the target currency.
+// Both rates are quoted against the same base currency, as stored in the currencies table.
+func Convert(source Money, targetCurrency string, sourceRate, targetRate *big.Rat, mode RoundingMode) (Conversion, error) {
+	if targetRate.Sign() <= 0 || sourceRate.Sign() <= 0 {
+		return Conversion{}, errors.New("exchange rates must be positive")
+	}
+
+	return ConvertAt(source, targetCurrency, new(big.Rat).Quo(sourceRate, targetRate), mode)
+}
+
+// ConvertAt converts an amount into the target currency at a rate already worked out,
+// given in target major units per source major unit
+func ConvertAt(source Money, targetCurrency string, rate *big.Rat, mode RoundingMode) (Conversion, error) {
+	if rate.Sign() <= 0 {
+		return Conversion{}, errors.New("exchange rate must be positive")
+	}
+
+	targetExponent, err := Exponent(targetCurrency)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	major, err := source.Rat()
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	exact := new(big.Rat).Mul(major, rate)
+	exact.Mul(exact, new(big.Rat).SetInt(pow10(targetExponent)))
+
+	amount, err := Round(exact, mode)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	return Conversion{
+		Source:    source,
+		Target:    New(amount, targetCurrency),
+		Rate:      new(big.Rat).Set(rate),
+		Remainder: exact.Sub(exact, new(big.Rat).SetInt64(amount)),
+	}, nil
+}
+
+// ConvertToTarget works out the source amount needed for the target to receive exactly the given amount.
+// The source amount is always rounded up so the bank never pays out more than it collects,
+// and Remainder is the non-negative surplus of the exact target amount over the requested one.
+func ConvertToTarget(target Money, sourceCurrency string, sourceRate, targetRate *big.Rat) (Conversion, error) {
+	if targetRate.Sign() <= 0 || sourceRate.Sign() <= 0 {
+		return Conversion{}, errors.New("exchange rates must be positive")
+	}
+
+	return ConvertToTargetAt(target, sourceCurrency, new(big.Rat).Quo(sourceRate, targetRate))
+}
+
+// ConvertToTargetAt is ConvertToTarget at a rate already worked out,
+// given in target major units per source major unit
+func ConvertToTargetAt(target Money, sourceCurrency string, rate *big.Rat) (Conversion, error) {
+	if rate.Sign() <= 0 {
+		return Conversion{}, errors.New("exchange rate must be positive")
+	}
+
+	sourceExponent, err := Exponent(sourceCurrency)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	major, err := target.Rat()
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	exact := new(big.Rat).Quo(major, rate)
+	exact.Mul(exact, new(big.Rat).SetInt(pow10(sourceExponent)))
+
+	amount, err := Round(exact, RoundUp)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	source := New(amount, sourceCurrency)
+	sourceMajor, err := source.Rat()
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	targetExponent, err := Exponent(target.Currency)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	// the rounded up source converts to at least the target, the surplus stays with the bank
+	surplus := new(big.Rat).Mul(sourceMajor, rate)
+	surplus.Mul(surplus, new(big.Rat).SetInt(pow10(targetExponent)))
+	surplus.Sub(surplus, new(big.Rat).SetInt64(target.Amount))
+
+	return Conversion{
+		Source:    source,
+		Target:    target,
+		Rate:      new(big.Rat).Set(rate),
+		Remainder: surplus,
+	}, nil
+}
+
+// RatFromNumeric converts a numeric column into an exact rational
+func RatFromNumeric(value pgtype.Numeric) (*big.Rat, error) {
+	if !value.Valid || value.NaN || value.InfinityModifier != pgtype.Finite {
+		return nil, errors.New("numeric value is not a finite number")
+	}
+
+	rat := new(big.Rat).SetInt(value.Int)
+	if value.Exp > 0 {
+		rat.Mul(rat, new(big.Rat).SetInt(pow10(value.Exp)))
+	} else if value.Exp < 0 {
+		rat.Quo(rat, new(big.Rat).SetInt(pow10(-value.Exp)))
+	}
+	return rat, nil
+}
+
+// NumericFromRat converts a rational into a numeric column value with RateScale decimals
+func NumericFromRat(value *big.Rat) pgtype.Numeric {
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(pow10(RateScale)))
+	quo, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	// Round half away from zero on the last kept decimal
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(scaled.Sign())))
+	}
+
+	return pgtype.Numeric{Int: quo, Exp: -RateScale, Valid: true}
+}
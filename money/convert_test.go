@@ -0,0 +1,154 @@
+package money
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRound(t *testing.T) {
+	testCases := []struct {
+		value string
+		mode  RoundingMode
+		want  int64
+	}{
+		{"2.5", RoundHalfEven, 2},
+		{"3.5", RoundHalfEven, 4},
+		{"-2.5", RoundHalfEven, -2},
+		{"2.5", RoundHalfUp, 3},
+		{"-2.5", RoundHalfUp, -3},
+		{"2.4", RoundHalfUp, 2},
+		{"2.9", RoundDown, 2},
+		{"-2.9", RoundDown, -2},
+		{"2.1", RoundUp, 3},
+		{"-2.1", RoundUp, -3},
+		{"7", RoundUp, 7},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value+"/"+string(tc.mode), func(t *testing.T) {
+			value, ok := new(big.Rat).SetString(tc.value)
+			require.True(t, ok)
+
+			got, err := Round(value, tc.mode)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	huge, _ := new(big.Rat).SetString("1e30")
+	_, err := Round(huge, RoundDown)
+	require.ErrorIs(t, err, ErrAmountOverflow)
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	mode, err := ParseRoundingMode("")
+	require.NoError(t, err)
+	require.Equal(t, RoundHalfEven, mode)
+
+	mode, err = ParseRoundingMode("half_up")
+	require.NoError(t, err)
+	require.Equal(t, RoundHalfUp, mode)
+
+	_, err = ParseRoundingMode("ceiling")
+	require.Error(t, err)
+}
+
+func TestConvert(t *testing.T) {
+	// 1 USD = 32.5 base units, 1 JPY = 0.2 base units, 1 EUR = 35 base units
+	usdRate := big.NewRat(65, 2)
+	jpyRate := big.NewRat(1, 5)
+	eurRate := big.NewRat(35, 1)
+
+	conversion, err := Convert(New(1001, "USD"), "JPY", usdRate, jpyRate, RoundHalfEven)
+	require.NoError(t, err)
+	// 10.01 USD * 162.5 = 1626.625 JPY
+	require.Equal(t, New(1627, "JPY"), conversion.Target)
+	require.Equal(t, big.NewRat(325, 2), conversion.Rate)
+	require.Equal(t, big.NewRat(-3, 8), conversion.Remainder)
+
+	conversion, err = Convert(New(1001, "USD"), "JPY", usdRate, jpyRate, RoundDown)
+	require.NoError(t, err)
+	require.Equal(t, New(1626, "JPY"), conversion.Target)
+	require.Equal(t, big.NewRat(5, 8), conversion.Remainder)
+
+	conversion, err = Convert(New(1000, "EUR"), "USD", eurRate, usdRate, RoundHalfEven)
+	require.NoError(t, err)
+	// 10 EUR * 35 / 32.5 = 10.769230... USD
+	require.Equal(t, New(1077, "USD"), conversion.Target)
+
+	conversion, err = Convert(New(100, "USD"), "USD", usdRate, usdRate, RoundHalfEven)
+	require.NoError(t, err)
+	require.Equal(t, New(100, "USD"), conversion.Target)
+	require.Zero(t, conversion.Remainder.Sign())
+
+	_, err = Convert(New(100, "USD"), "XXX", usdRate, usdRate, RoundHalfEven)
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+
+	_, err = Convert(New(100, "USD"), "EUR", usdRate, new(big.Rat), RoundHalfEven)
+	require.Error(t, err)
+}
+
+func TestNumeric(t *testing.T) {
+	var numeric pgtype.Numeric
+	require.NoError(t, numeric.Scan("32.155"))
+
+	rat, err := RatFromNumeric(numeric)
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(6431, 200), rat)
+
+	back := NumericFromRat(rat)
+	rat2, err := RatFromNumeric(back)
+	require.NoError(t, err)
+	require.Equal(t, rat, rat2)
+
+	third := NumericFromRat(big.NewRat(-2, 3))
+	value, err := third.Value()
+	require.NoError(t, err)
+	require.Equal(t, "-0.666666666667", value)
+
+	_, err = RatFromNumeric(pgtype.Numeric{})
+	require.Error(t, err)
+}
+
+func TestConvertAt(t *testing.T) {
+	// A locked rate of 162.5 JPY per USD gives the same result as converting through the base currency
+	conversion, err := ConvertAt(New(1001, "USD"), "JPY", big.NewRat(325, 2), RoundHalfEven)
+	require.NoError(t, err)
+	require.Equal(t, New(1627, "JPY"), conversion.Target)
+	require.Equal(t, big.NewRat(-3, 8), conversion.Remainder)
+
+	_, err = ConvertAt(New(1001, "USD"), "JPY", new(big.Rat), RoundHalfEven)
+	require.Error(t, err)
+}
+
+func TestConvertToTarget(t *testing.T) {
+	// 9.29 EUR at 32.5 / 35 needs 10.004615... USD, the sender pays the cent rounded up
+	conversion, err := ConvertToTarget(New(929, "EUR"), "USD", big.NewRat(65, 2), big.NewRat(35, 1))
+	require.NoError(t, err)
+	require.Equal(t, New(1001, "USD"), conversion.Source)
+	require.Equal(t, New(929, "EUR"), conversion.Target)
+	require.Equal(t, big.NewRat(13, 14), conversion.Rate)
+	// 10.01 USD converts to 929.5 EUR cents
+	require.Equal(t, big.NewRat(1, 2), conversion.Remainder)
+
+	// an exact conversion leaves nothing to the bank
+	conversion, err = ConvertToTarget(New(1300, "EUR"), "USD", big.NewRat(65, 2), big.NewRat(35, 1))
+	require.NoError(t, err)
+	require.Equal(t, New(1400, "USD"), conversion.Source)
+	require.Zero(t, conversion.Remainder.Sign())
+
+	// JPY has no minor unit, so 1 cent still costs a whole yen
+	conversion, err = ConvertToTargetAt(New(1, "USD"), "JPY", big.NewRat(1, 150))
+	require.NoError(t, err)
+	require.Equal(t, New(2, "JPY"), conversion.Source)
+	require.Equal(t, big.NewRat(1, 3), conversion.Remainder)
+
+	_, err = ConvertToTarget(New(100, "EUR"), "USD", new(big.Rat), big.NewRat(35, 1))
+	require.Error(t, err)
+
+	_, err = ConvertToTarget(New(100, "EUR"), "XYZ", big.NewRat(65, 2), big.NewRat(35, 1))
+	require.Error(t, err)
+}
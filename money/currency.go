@@ -0,0 +1,39 @@
+package money
+
+import "fmt"
+
+// exponents holds the ISO 4217 minor-unit exponent of each known currency,
+// i.e. the number of decimal places between the major and the minor unit.
+var exponents = map[string]int32{
+	"AUD": 2,
+	"BHD": 3,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"IDR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"MYR": 2,
+	"NZD": 2,
+	"PHP": 2,
+	"SEK": 2,
+	"SGD": 2,
+	"THB": 2,
+	"TWD": 2,
+	"USD": 2,
+	"VND": 0,
+	"ZAR": 2,
+}
+
+// Exponent returns the ISO 4217 minor-unit exponent of the currency
+func Exponent(currency string) (int32, error) {
+	exponent, ok := exponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+	return exponent, nil
+}
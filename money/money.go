@@ -0,0 +1,115 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrAmountOverflow  = errors.New("amount overflows int64 minor units")
+	ErrInvalidAmount   = errors.New("invalid amount")
+)
+
+// Money is an amount expressed in the minor units of its currency, e.g. cents for USD
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// New returns an amount of minor units in the given currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Rat returns the amount in major units as an exact rational
+func (m Money) Rat() (*big.Rat, error) {
+	exponent, err := Exponent(m.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(exponent)), nil
+}
+
+// Decimal formats the amount in major units with exactly as many decimals as the currency exponent
+func (m Money) Decimal() string {
+	exponent, err := Exponent(m.Currency)
+	if err != nil {
+		return fmt.Sprintf("%d", m.Amount)
+	}
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(exponent)).FloatString(int(exponent))
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+type moneyJSON struct {
+	Amount     string `json:"amount"`
+	Currency   string `json:"currency"`
+	MinorUnits int64  `json:"minor_units"`
+	Exponent   int32  `json:"exponent"`
+}
+
+// MarshalJSON renders both the decimal amount and the minor units so clients never have to guess the scale
+func (m Money) MarshalJSON() ([]byte, error) {
+	exponent, err := Exponent(m.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(moneyJSON{
+		Amount:     m.Decimal(),
+		Currency:   m.Currency,
+		MinorUnits: m.Amount,
+		Exponent:   exponent,
+	})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var value moneyJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	parsed, err := Parse(value.Amount, value.Currency)
+	if err != nil {
+		return err
+	}
+	if parsed.Amount != value.MinorUnits {
+		return fmt.Errorf("%w: %s does not match %d minor units", ErrInvalidAmount, value.Amount, value.MinorUnits)
+	}
+
+	*m = parsed
+	return nil
+}
+
+// Parse parses a decimal amount in major units, rejecting more decimals than the currency allows
+func Parse(amount string, currency string) (Money, error) {
+	exponent, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	if _, fraction, found := strings.Cut(amount, "."); found && len(fraction) > int(exponent) {
+		return Money{}, fmt.Errorf("%w: %s has more than %d decimals", ErrInvalidAmount, amount, exponent)
+	}
+
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrInvalidAmount, amount)
+	}
+
+	minor := value.Mul(value, new(big.Rat).SetInt(pow10(exponent)))
+	units, err := Round(minor, RoundDown)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(units, currency), nil
+}
+
+func pow10(exponent int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
@@ -0,0 +1,57 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecimal(t *testing.T) {
+	require.Equal(t, "12.34", New(1234, "USD").Decimal())
+	require.Equal(t, "-0.05", New(-5, "EUR").Decimal())
+	require.Equal(t, "1234", New(1234, "JPY").Decimal())
+	require.Equal(t, "1.234", New(1234, "KWD").Decimal())
+	require.Equal(t, "12.34 USD", New(1234, "USD").String())
+}
+
+func TestParse(t *testing.T) {
+	m, err := Parse("12.3", "USD")
+	require.NoError(t, err)
+	require.Equal(t, New(1230, "USD"), m)
+
+	m, err = Parse("500", "JPY")
+	require.NoError(t, err)
+	require.Equal(t, New(500, "JPY"), m)
+
+	_, err = Parse("0.001", "USD")
+	require.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = Parse("1.5", "JPY")
+	require.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = Parse("abc", "USD")
+	require.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = Parse("1", "XXX")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	m := New(1999, "CAD")
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":"19.99","currency":"CAD","minor_units":1999,"exponent":2}`, string(data))
+
+	var got Money
+	err = json.Unmarshal(data, &got)
+	require.NoError(t, err)
+	require.Equal(t, m, got)
+
+	err = json.Unmarshal([]byte(`{"amount":"19.99","currency":"CAD","minor_units":1998,"exponent":2}`), &got)
+	require.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = json.Marshal(New(1, "XXX"))
+	require.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode decides how an exact amount is turned into a whole number of minor units
+type RoundingMode string
+
+// Constants for all supported rounding modes
+const (
+	RoundHalfEven RoundingMode = "half_even"
+	RoundHalfUp   RoundingMode = "half_up"
+	RoundDown     RoundingMode = "down"
+	RoundUp       RoundingMode = "up"
+)
+
+// ParseRoundingMode parses a rounding mode from the configuration, defaulting to banker's rounding
+func ParseRoundingMode(mode string) (RoundingMode, error) {
+	switch RoundingMode(mode) {
+	case "":
+		return RoundHalfEven, nil
+	case RoundHalfEven, RoundHalfUp, RoundDown, RoundUp:
+		return RoundingMode(mode), nil
+	}
+	return "", fmt.Errorf("unsupported rounding mode: %s", mode)
+}
+
+// Round rounds an exact number of minor units to an integer.
+// Down and Up round toward and away from zero, the half modes only differ on exact ties.
+func Round(value *big.Rat, mode RoundingMode) (int64, error) {
+	quo, rem := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+
+	if rem.Sign() != 0 {
+		sign := big.NewInt(int64(value.Sign()))
+
+		// cmp compares the discarded fraction with one half
+		cmp := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(value.Denom())
+
+		switch mode {
+		case RoundUp:
+			quo.Add(quo, sign)
+		case RoundHalfUp:
+			if cmp >= 0 {
+				quo.Add(quo, sign)
+			}
+		case RoundHalfEven:
+			if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+				quo.Add(quo, sign)
+			}
+		case RoundDown:
+		default:
+			return 0, fmt.Errorf("unsupported rounding mode: %s", mode)
+		}
+	}
+
+	if !quo.IsInt64() {
+		return 0, ErrAmountOverflow
+	}
+	return quo.Int64(), nil
+}
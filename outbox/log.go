@@ -0,0 +1,29 @@
+package outbox
+
+import (
+	"context"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// LogPublisher writes every event to the log, it is meant for development and as a sink of last resort
+type LogPublisher struct {
+	logger zerolog.Logger
+}
+
+func NewLogPublisher() Publisher {
+	return &LogPublisher{logger: log.Logger}
+}
+
+func (publisher *LogPublisher) Publish(ctx context.Context, event db.OutboxEvent) error {
+	publisher.logger.Info().
+		Int64("id", event.ID).
+		Str("type", event.EventType).
+		Str("aggregate_type", event.AggregateType).
+		Int64("aggregate_id", event.AggregateID).
+		RawJSON("payload", event.Payload).
+		Msg("published outbox event")
+	return nil
+}
@@ -0,0 +1,59 @@
+// Package outbox publishes the ledger events the store writes to the outbox_events table.
+// The worker relays pending events oldest first to one Publisher, so a change is announced
+// if and only if it committed. Events may be published out of order, see RelayOutbox.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/redis/go-redis/v9"
+)
+
+// Kinds of publisher that can be picked with OUTBOX_PUBLISHER
+const (
+	PublisherLog   = "log"
+	PublisherRedis = "redis"
+)
+
+// Publisher hands an outbox event to a downstream system, it must return only once the event is durable there
+type Publisher interface {
+	Publish(ctx context.Context, event db.OutboxEvent) error
+}
+
+// Envelope is the wire format of an event, consumers deduplicate on ID
+type Envelope struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// NewEnvelope wraps the stored event for the wire
+func NewEnvelope(event db.OutboxEvent) Envelope {
+	return Envelope{
+		ID:            event.ID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       event.Payload,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+// NewPublisher builds the publisher of the given kind
+func NewPublisher(kind string, client *redis.Client, stream string) (Publisher, error) {
+	switch kind {
+	case PublisherLog:
+		return NewLogPublisher(), nil
+	case PublisherRedis:
+		return NewRedisStreamPublisher(client, stream), nil
+	default:
+		return nil, fmt.Errorf("unsupported outbox publisher %q", kind)
+	}
+}
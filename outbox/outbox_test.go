@@ -0,0 +1,75 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStreamClient struct {
+	args []*redis.XAddArgs
+	err  error
+}
+
+func (client *fakeStreamClient) XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	client.args = append(client.args, a)
+	cmd := redis.NewStringCmd(ctx)
+	if client.err != nil {
+		cmd.SetErr(client.err)
+	} else {
+		cmd.SetVal("1-0")
+	}
+	return cmd
+}
+
+func randomEvent() db.OutboxEvent {
+	return db.OutboxEvent{
+		ID:            util.RandomInt(1, 1000),
+		AggregateType: "transfer",
+		AggregateID:   util.RandomInt(1, 1000),
+		EventType:     db.EventTransferCreated,
+		Payload:       []byte(`{"amount":10}`),
+		CreatedAt:     time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+func TestRedisStreamPublisher(t *testing.T) {
+	event := randomEvent()
+	client := &fakeStreamClient{}
+	publisher := NewRedisStreamPublisher(client, "ledger")
+
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	require.Len(t, client.args, 1)
+
+	args := client.args[0]
+	require.Equal(t, "ledger", args.Stream)
+	require.True(t, args.Approx)
+	values := args.Values.(map[string]any)
+	require.Equal(t, event.ID, values["id"])
+	require.Equal(t, event.EventType, values["type"])
+	require.Equal(t, string(event.Payload), values["payload"])
+
+	client.err = errors.New("connection refused")
+	err := publisher.Publish(context.Background(), event)
+	require.ErrorIs(t, err, client.err)
+}
+
+func TestNewPublisher(t *testing.T) {
+	publisher, err := NewPublisher(PublisherLog, nil, "")
+	require.NoError(t, err)
+	require.IsType(t, &LogPublisher{}, publisher)
+	require.NoError(t, publisher.Publish(context.Background(), randomEvent()))
+
+	publisher, err = NewPublisher(PublisherRedis, redis.NewClient(&redis.Options{}), "ledger")
+	require.NoError(t, err)
+	require.IsType(t, &RedisStreamPublisher{}, publisher)
+
+	_, err = NewPublisher("sqs", nil, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/redis/go-redis/v9"
+)
+
+// streamMaxLen caps the stream approximately, consumers are expected to keep up well within it
+const streamMaxLen = 100000
+
+// StreamClient is the part of the Redis client the stream publisher uses
+type StreamClient interface {
+	XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd
+}
+
+// RedisStreamPublisher appends every event to a Redis stream
+type RedisStreamPublisher struct {
+	client StreamClient
+	stream string
+}
+
+func NewRedisStreamPublisher(client StreamClient, stream string) Publisher {
+	return &RedisStreamPublisher{
+		client: client,
+		stream: stream,
+	}
+}
+
+func (publisher *RedisStreamPublisher) Publish(ctx context.Context, event db.OutboxEvent) error {
+	err := publisher.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: publisher.stream,
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]any{
+			"id":             event.ID,
+			"type":           event.EventType,
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   event.AggregateID,
+			"payload":        string(event.Payload),
+			"created_at":     event.CreatedAt.Format(time.RFC3339Nano),
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to add event %d to stream %s: %w", event.ID, publisher.stream, err)
+	}
+	return nil
+}
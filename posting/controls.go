@@ -0,0 +1,192 @@
+// Package posting runs the controls a transfer goes through before it is posted, whichever path posts it.
+// The recipient is screened against the sanctions list, the fraud rules screen and record the transfer,
+// and a transfer they hold or above the approval threshold waits for a banker instead of being posted.
+package posting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/fraud"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/rates"
+	"github.com/RobertChienShiba/simplebank/sanctions"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrTransferBlocked is returned for a transfer the fraud rules block, the rules aren't disclosed to the sender
+var ErrTransferBlocked = errors.New("transfer was blocked by fraud screening")
+
+// FraudScreener screens and records transfers, it is the fraud engine outside of tests
+type FraudScreener interface {
+	Screen(ctx context.Context, transfer fraud.Transfer) (fraud.Screening, error)
+}
+
+// Controls are shared by the API and the worker, so a transfer is controlled the same way
+// whether a user, a schedule or a hold capture posts it
+type Controls struct {
+	Store db.Querier
+	Fraud FraudScreener
+	// Sanctions is nil when no sanctions list is configured
+	Sanctions SanctionsScreener
+	// ApprovalThreshold is nil when transfers never need approval
+	ApprovalThreshold *money.Money
+	ApprovalTTL       time.Duration
+	RoundingMode      money.RoundingMode
+}
+
+// NewControls creates the controls configured by config
+func NewControls(config util.Config, store db.Querier) (*Controls, error) {
+	roundingMode, err := money.ParseRoundingMode(config.RoundingMode)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse rounding mode %w", err)
+	}
+
+	controls := &Controls{
+		Store:        store,
+		Fraud:        fraud.NewEngine(store, fraud.DefaultRules(config)...),
+		ApprovalTTL:  config.TransferApprovalTTL,
+		RoundingMode: roundingMode,
+	}
+	if config.TransferApprovalThreshold != "" {
+		// The threshold is converted at the synced rates, so it must be a currency they can convert
+		currency := config.TransferApprovalCurrency
+		if !util.IsSupportedCurrency(currency) && currency != rates.QuoteCurrency {
+			return nil, fmt.Errorf("unsupported transfer approval currency %s", currency)
+		}
+		threshold, err := money.Parse(config.TransferApprovalThreshold, config.TransferApprovalCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse transfer approval threshold %w", err)
+		}
+		controls.ApprovalThreshold = &threshold
+	}
+	if config.SanctionsListPath != "" {
+		screener, err := sanctions.NewScreener(config.SanctionsListPath, config.SanctionsMatchThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load sanctions list %w", err)
+		}
+		controls.Sanctions = screener
+	}
+
+	return controls, nil
+}
+
+// Transfer is a transfer about to be posted
+type Transfer struct {
+	// Username is the user sending the transfer, who requests its approval
+	Username    string
+	FromAccount db.Account
+	ToAccount   db.Account
+	// Params posts the transfer, an approval locks its amounts and rate
+	Params db.TransferTxParams
+	// HoldID is the hold the transfer captures, zero for any other transfer
+	HoldID int64
+}
+
+// Check screens a transfer and returns the approval it now waits for, or nil when it can be posted right away
+func (controls *Controls) Check(ctx context.Context, transfer Transfer) (*db.TransferApproval, error) {
+	screening, err := controls.Screen(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	needed := screening.Decision == fraud.Hold
+	if !needed {
+		needed, err = controls.needsApproval(ctx, money.New(transfer.Params.FromAmount, transfer.FromAccount.Currency))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !needed {
+		return nil, nil
+	}
+
+	approval, err := controls.Store.CreateTransferApproval(ctx, db.CreateTransferApprovalParams{
+		FromAccountID:     transfer.Params.FromAccountID,
+		ToAccountID:       transfer.Params.ToAccountID,
+		Amount:            transfer.Params.FromAmount,
+		ToAmount:          transfer.Params.ToAmount,
+		ExchangeRate:      transfer.Params.ExchangeRate,
+		RoundingRemainder: transfer.Params.RoundingRemainder,
+		RequestedBy:       transfer.Username,
+		ExpiresAt:         time.Now().Add(controls.ApprovalTTL),
+		FraudDecisionID:   pgtype.Int8{Int64: screening.DecisionID, Valid: screening.DecisionID != 0},
+		HoldID:            pgtype.Int8{Int64: transfer.HoldID, Valid: transfer.HoldID != 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request transfer approval: %w", err)
+	}
+
+	return &approval, nil
+}
+
+// Screen screens the recipient of a transfer against the sanctions list, then runs the fraud rules and records their decision.
+// A recipient on the list returns ErrSanctionsMatch and a blocked transfer returns ErrTransferBlocked.
+func (controls *Controls) Screen(ctx context.Context, transfer Transfer) (fraud.Screening, error) {
+	if err := controls.ScreenRecipient(ctx, transfer.Username, transfer.ToAccount); err != nil {
+		return fraud.Screening{}, err
+	}
+
+	screening, err := controls.Fraud.Screen(ctx, fraud.Transfer{
+		Username:    transfer.Username,
+		FromAccount: transfer.FromAccount,
+		ToAccount:   transfer.ToAccount,
+		Amount:      transfer.Params.FromAmount,
+	})
+	if err != nil {
+		return screening, err
+	}
+	if screening.Decision == fraud.Block {
+		return screening, ErrTransferBlocked
+	}
+
+	return screening, nil
+}
+
+// needsApproval reports whether a transfer debiting amount is above the approval threshold
+func (controls *Controls) needsApproval(ctx context.Context, amount money.Money) (bool, error) {
+	if controls.ApprovalThreshold == nil {
+		return false, nil
+	}
+
+	threshold := *controls.ApprovalThreshold
+	if amount.Currency == threshold.Currency {
+		return amount.Amount > threshold.Amount, nil
+	}
+
+	fromExchangeRate, err := controls.exchangeRate(ctx, amount.Currency)
+	if err != nil {
+		return false, err
+	}
+
+	toExchangeRate, err := controls.exchangeRate(ctx, threshold.Currency)
+	if err != nil {
+		return false, err
+	}
+
+	conversion, err := money.Convert(amount, threshold.Currency, fromExchangeRate, toExchangeRate, controls.RoundingMode)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert amount: %w", err)
+	}
+
+	return conversion.Target.Amount > threshold.Amount, nil
+}
+
+// exchangeRate returns the rate of currency in the quote currency, which is 1 for the quote currency itself
+func (controls *Controls) exchangeRate(ctx context.Context, currency string) (*big.Rat, error) {
+	if currency == rates.QuoteCurrency {
+		return big.NewRat(1, 1), nil
+	}
+
+	rate, err := controls.Store.GetExchangeRate(ctx, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	return money.RatFromNumeric(rate)
+}
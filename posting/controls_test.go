@@ -0,0 +1,219 @@
+package posting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/fraud"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/rates"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+type stubScreener struct {
+	screening fraud.Screening
+}
+
+func (screener stubScreener) Screen(ctx context.Context, transfer fraud.Transfer) (fraud.Screening, error) {
+	return screener.screening, nil
+}
+
+func newRate(t *testing.T, rate string) pgtype.Numeric {
+	var numeric pgtype.Numeric
+	require.NoError(t, numeric.Scan(rate))
+	return numeric
+}
+
+func TestCheck(t *testing.T) {
+	username := util.RandomOwner()
+	fromAccount := db.Account{ID: util.RandomInt(1, 1000), Owner: username, Currency: util.EUR}
+	toAccount := db.Account{ID: util.RandomInt(1001, 2000), Owner: util.RandomOwner(), Currency: util.EUR}
+	decisionID := util.RandomInt(1, 1000)
+	holdID := util.RandomInt(1, 1000)
+
+	newTransfer := func(amount int64) Transfer {
+		return Transfer{
+			Username:    username,
+			FromAccount: fromAccount,
+			ToAccount:   toAccount,
+			Params: db.TransferTxParams{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				FromAmount:    amount,
+				ToAmount:      amount,
+				Username:      username,
+			},
+			HoldID: holdID,
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		transfer   Transfer
+		decision   fraud.Decision
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, approval *db.TransferApproval, err error)
+	}{
+		{
+			name: "ConvertedBelowThreshold",
+			// 45000 EUR cents at 35 and 32 TWD per EUR and USD are 49218 USD cents
+			transfer: newTransfer(45000),
+			decision: fraud.Allow,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.EUR)).Times(1).Return(newRate(t, "35"), nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(newRate(t, "32"), nil)
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, approval *db.TransferApproval, err error) {
+				require.NoError(t, err)
+				require.Nil(t, approval)
+			},
+		},
+		{
+			name: "ConvertedAboveThreshold",
+			// 46000 EUR cents are 50312 USD cents
+			transfer: newTransfer(46000),
+			decision: fraud.Allow,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.EUR)).Times(1).Return(newRate(t, "35"), nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(newRate(t, "32"), nil)
+				store.EXPECT().
+					CreateTransferApproval(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTransferApprovalParams) (db.TransferApproval, error) {
+						require.Equal(t, int64(46000), arg.Amount)
+						require.Equal(t, username, arg.RequestedBy)
+						require.Equal(t, pgtype.Int8{Int64: holdID, Valid: true}, arg.HoldID)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt, time.Second)
+						return db.TransferApproval{ID: 1, HoldID: arg.HoldID}, nil
+					})
+			},
+			check: func(t *testing.T, approval *db.TransferApproval, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, approval)
+				require.Equal(t, holdID, approval.HoldID.Int64)
+			},
+		},
+		{
+			name:     "FraudHold",
+			transfer: newTransfer(100),
+			decision: fraud.Hold,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreateTransferApproval(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTransferApprovalParams) (db.TransferApproval, error) {
+						require.Equal(t, pgtype.Int8{Int64: decisionID, Valid: true}, arg.FraudDecisionID)
+						return db.TransferApproval{ID: 1, FraudDecisionID: arg.FraudDecisionID}, nil
+					})
+			},
+			check: func(t *testing.T, approval *db.TransferApproval, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, approval)
+			},
+		},
+		{
+			name:     "FraudBlock",
+			transfer: newTransfer(100),
+			decision: fraud.Block,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, approval *db.TransferApproval, err error) {
+				require.ErrorIs(t, err, ErrTransferBlocked)
+				require.Nil(t, approval)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			threshold := money.New(50000, util.USD)
+			controls := &Controls{
+				Store:             store,
+				Fraud:             stubScreener{screening: fraud.Screening{DecisionID: decisionID, Decision: tc.decision}},
+				ApprovalThreshold: &threshold,
+				ApprovalTTL:       time.Hour,
+				RoundingMode:      money.RoundHalfEven,
+			}
+
+			approval, err := controls.Check(context.Background(), tc.transfer)
+			tc.check(t, approval, err)
+		})
+	}
+}
+
+func TestCheckQuoteCurrencyThreshold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	username := util.RandomOwner()
+	fromAccount := db.Account{ID: util.RandomInt(1, 1000), Owner: username, Currency: util.USD}
+	toAccount := db.Account{ID: util.RandomInt(1001, 2000), Owner: util.RandomOwner(), Currency: util.USD}
+
+	// The quote currency has no rate of its own, 1000 USD cents at 32 TWD per USD are 32000 TWD cents
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(newRate(t, "32"), nil)
+	store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(rates.QuoteCurrency)).Times(0)
+	store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferApproval{ID: 1}, nil)
+
+	threshold := money.New(30000, rates.QuoteCurrency)
+	controls := &Controls{
+		Store:             store,
+		Fraud:             stubScreener{screening: fraud.Screening{Decision: fraud.Allow}},
+		ApprovalThreshold: &threshold,
+		ApprovalTTL:       time.Hour,
+		RoundingMode:      money.RoundHalfEven,
+	}
+
+	approval, err := controls.Check(context.Background(), Transfer{
+		Username:    username,
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		Params: db.TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			FromAmount:    1000,
+			ToAmount:      1000,
+			Username:      username,
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, approval)
+}
+
+func TestNewControlsApprovalCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	config := util.Config{
+		RoundingMode:              "half_even",
+		TransferApprovalThreshold: "10000",
+		TransferApprovalCurrency:  rates.QuoteCurrency,
+	}
+
+	controls, err := NewControls(config, store)
+	require.NoError(t, err)
+	require.Equal(t, money.New(1000000, rates.QuoteCurrency), *controls.ApprovalThreshold)
+
+	// No rate converts a currency that is neither an account currency nor the quote currency
+	config.TransferApprovalCurrency = "JPY"
+	_, err = NewControls(config, store)
+	require.Error(t, err)
+}
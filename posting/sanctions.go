@@ -0,0 +1,88 @@
+package posting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/sanctions"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrSanctionsMatch is returned for an action held back by the sanctions list, the matched entry isn't disclosed
+var ErrSanctionsMatch = errors.New("the name matches the sanctions list, a banker will review it")
+
+// SanctionsScreener matches names against the sanctions list, it is the list file outside of tests
+type SanctionsScreener interface {
+	Screen(name string) (sanctions.Match, bool)
+	Reload() (int, error)
+}
+
+// ScreenName matches the screened name of arg against the sanctions list and opens a case for a match,
+// which returns ErrSanctionsMatch. Names a banker cleared for the matched entry pass, and a user
+// retrying an action held back for the same entry reuses the open case instead of opening another.
+func (controls *Controls) ScreenName(ctx context.Context, arg db.CreateSanctionsCaseParams) error {
+	if controls.Sanctions == nil {
+		return nil
+	}
+
+	match, found := controls.Sanctions.Screen(arg.ScreenedName)
+	if !found {
+		return nil
+	}
+
+	cleared, err := controls.Store.CountClearedSanctionsCases(ctx, db.CountClearedSanctionsCasesParams{
+		ScreenedName: arg.ScreenedName,
+		ListEntryID:  match.Entry.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to count cleared sanctions cases: %w", err)
+	}
+	if cleared > 0 {
+		return nil
+	}
+
+	_, err = controls.Store.GetOpenSanctionsCase(ctx, db.GetOpenSanctionsCaseParams{
+		Username:    arg.Username,
+		ListEntryID: match.Entry.ID,
+		Action:      arg.Action,
+	})
+	if err == nil {
+		return ErrSanctionsMatch
+	}
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get open sanctions case: %w", err)
+	}
+
+	arg.ListEntryID = match.Entry.ID
+	arg.MatchedName = match.Name
+	arg.Program = match.Entry.Program
+	arg.Score = match.Score
+	if _, err := controls.Store.CreateSanctionsCase(ctx, arg); err != nil {
+		return fmt.Errorf("failed to create sanctions case: %w", err)
+	}
+
+	return ErrSanctionsMatch
+}
+
+// ScreenRecipient matches the full name of the owner of toAccount against the sanctions list,
+// the case is opened for username who sends or schedules the transfer
+func (controls *Controls) ScreenRecipient(ctx context.Context, username string, toAccount db.Account) error {
+	if controls.Sanctions == nil {
+		return nil
+	}
+
+	recipient, err := controls.Store.GetUser(ctx, toAccount.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to get recipient: %w", err)
+	}
+
+	return controls.ScreenName(ctx, db.CreateSanctionsCaseParams{
+		Action:       util.SanctionsActionTransfer,
+		Username:     username,
+		AccountID:    pgtype.Int8{Int64: toAccount.ID, Valid: true},
+		ScreenedName: recipient.FullName,
+	})
+}
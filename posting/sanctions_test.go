@@ -0,0 +1,158 @@
+package posting
+
+import (
+	"context"
+	"testing"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/fraud"
+	"github.com/RobertChienShiba/simplebank/sanctions"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSanctionsScreener struct {
+	match sanctions.Match
+	found bool
+}
+
+func (screener stubSanctionsScreener) Screen(name string) (sanctions.Match, bool) {
+	return screener.match, screener.found
+}
+
+func (screener stubSanctionsScreener) Reload() (int, error) {
+	return 0, nil
+}
+
+// countingScreener counts how often the fraud rules ran
+type countingScreener struct {
+	calls *int
+}
+
+func (screener countingScreener) Screen(ctx context.Context, transfer fraud.Transfer) (fraud.Screening, error) {
+	*screener.calls++
+	return fraud.Screening{Decision: fraud.Allow}, nil
+}
+
+func TestScreenRecipient(t *testing.T) {
+	username := util.RandomOwner()
+	recipient := db.User{Username: util.RandomOwner(), FullName: "Viktor Kovalenko"}
+	toAccount := db.Account{ID: util.RandomInt(1, 1000), Owner: recipient.Username, Currency: util.USD}
+	match := sanctions.Match{
+		Entry: sanctions.Entry{ID: "36", Name: "KOVALENKO, Viktor", Program: "SDGT"},
+		Name:  "KOVALENKO, Viktor",
+		Score: 0.97,
+	}
+	openCase := db.GetOpenSanctionsCaseParams{
+		Username:    username,
+		ListEntryID: match.Entry.ID,
+		Action:      util.SanctionsActionTransfer,
+	}
+	transfer := Transfer{
+		Username:  username,
+		ToAccount: toAccount,
+		Params:    db.TransferTxParams{ToAccountID: toAccount.ID, FromAmount: 100},
+	}
+
+	testCases := []struct {
+		name        string
+		screener    SanctionsScreener
+		buildStubs  func(store *mockdb.MockStore)
+		fraudCalls  int
+		expectedErr error
+	}{
+		{
+			name:     "NoList",
+			screener: nil,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			fraudCalls: 1,
+		},
+		{
+			name:     "NoMatch",
+			screener: stubSanctionsScreener{found: false},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(0)
+			},
+			fraudCalls: 1,
+		},
+		{
+			name:     "Match",
+			screener: stubSanctionsScreener{match: match, found: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Eq(openCase)).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					CreateSanctionsCase(gomock.Any(), gomock.Eq(db.CreateSanctionsCaseParams{
+						Action:       util.SanctionsActionTransfer,
+						Username:     username,
+						AccountID:    pgtype.Int8{Int64: toAccount.ID, Valid: true},
+						ScreenedName: recipient.FullName,
+						ListEntryID:  match.Entry.ID,
+						MatchedName:  match.Name,
+						Program:      match.Entry.Program,
+						Score:        match.Score,
+					})).
+					Times(1).
+					Return(db.SanctionsCase{ID: 1}, nil)
+			},
+			fraudCalls:  0,
+			expectedErr: ErrSanctionsMatch,
+		},
+		{
+			name:     "OpenCase",
+			screener: stubSanctionsScreener{match: match, found: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Eq(openCase)).Times(1).Return(db.SanctionsCase{ID: 1}, nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+			},
+			fraudCalls:  0,
+			expectedErr: ErrSanctionsMatch,
+		},
+		{
+			name:     "Cleared",
+			screener: stubSanctionsScreener{match: match, found: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+			},
+			fraudCalls: 1,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			var fraudCalls int
+			controls := &Controls{
+				Store:     store,
+				Fraud:     countingScreener{calls: &fraudCalls},
+				Sanctions: tc.screener,
+			}
+
+			_, err := controls.Screen(context.Background(), transfer)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.fraudCalls, fraudCalls)
+		})
+	}
+}
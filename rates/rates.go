@@ -0,0 +1,128 @@
+// Package rates fetches exchange rates published by the Bank of Taiwan.
+package rates
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// rateColumn is the index of the cash selling rate in the Bank of Taiwan CSV
+const rateColumn = 12
+
+// QuoteCurrency is the currency the rates are quoted in, it has no rate of its own in the currencies table
+const QuoteCurrency = "TWD"
+
+// Rate is the number of New Taiwan dollars per major unit of a currency
+type Rate struct {
+	Currency string
+	Rate     pgtype.Numeric
+}
+
+// Fetcher downloads the rate sheet of the Bank of Taiwan
+type Fetcher struct {
+	client *http.Client
+	url    string
+}
+
+// NewFetcher creates a fetcher reading the CSV sheet served at url
+func NewFetcher(client *http.Client, url string) *Fetcher {
+	return &Fetcher{
+		client: client,
+		url:    url,
+	}
+}
+
+// Fetch downloads the sheet and returns the rates of the given currencies
+func (fetcher *Fetcher) Fetch(ctx context.Context, currencies map[string]bool) ([]Rate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fetcher.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	rsp, err := fetcher.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download rates: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download rates: unexpected status %s", rsp.Status)
+	}
+
+	return Parse(rsp.Body, currencies)
+}
+
+// Parse reads a Bank of Taiwan CSV sheet.
+// Rows of other currencies and rates the bank does not quote (zero or blank) are skipped.
+func Parse(r io.Reader, currencies map[string]bool) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rates := []Rate{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse rates: %w", err)
+		}
+
+		if len(record) <= rateColumn {
+			continue
+		}
+
+		currency := strings.TrimSpace(strings.TrimPrefix(record[0], "\ufeff"))
+		if !currencies[currency] {
+			continue
+		}
+
+		var rate pgtype.Numeric
+		if err := rate.Scan(strings.TrimSpace(record[rateColumn])); err != nil {
+			continue
+		}
+		if value, err := money.RatFromNumeric(rate); err != nil || value.Sign() <= 0 {
+			continue
+		}
+
+		rates = append(rates, Rate{
+			Currency: currency,
+			Rate:     rate,
+		})
+	}
+
+	return rates, nil
+}
+
+// LoadCurrencies reads the currencies to keep, one ISO 4217 code per line
+func LoadCurrencies(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open currency file: %w", err)
+	}
+	defer file.Close()
+
+	currencies := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if currency := strings.TrimSpace(scanner.Text()); currency != "" {
+			currencies[currency] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read currency file: %w", err)
+	}
+
+	return currencies, nil
+}
@@ -0,0 +1,75 @@
+package rates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func newFixtureServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join("testdata", "bot_rates.csv"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func requireRate(t *testing.T, rate Rate, currency string, expected string) {
+	require.Equal(t, currency, rate.Currency)
+
+	var want pgtype.Numeric
+	require.NoError(t, want.Scan(expected))
+	require.Equal(t, want, rate.Rate)
+}
+
+func TestFetch(t *testing.T) {
+	server := newFixtureServer(t)
+	fetcher := NewFetcher(server.Client(), server.URL)
+
+	rates, err := fetcher.Fetch(context.Background(), map[string]bool{"USD": true, "CAD": true, "EUR": true})
+	require.NoError(t, err)
+	require.Len(t, rates, 3)
+	requireRate(t, rates[0], "USD", "32.75500")
+	requireRate(t, rates[1], "CAD", "23.16000")
+	requireRate(t, rates[2], "EUR", "35.81000")
+}
+
+func TestFetchSkipsUnquotedRates(t *testing.T) {
+	server := newFixtureServer(t)
+	fetcher := NewFetcher(server.Client(), server.URL)
+
+	// The bank does not sell SEK in cash, so the sheet quotes a zero rate
+	rates, err := fetcher.Fetch(context.Background(), map[string]bool{"SEK": true, "HKD": true})
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	requireRate(t, rates[0], "HKD", "4.22000")
+}
+
+func TestFetchBadStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(server.Client(), server.URL)
+	_, err := fetcher.Fetch(context.Background(), map[string]bool{"USD": true})
+	require.Error(t, err)
+}
+
+func TestLoadCurrencies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "currency.txt")
+	require.NoError(t, os.WriteFile(path, []byte("USD\n CAD \n\nEUR\n"), 0o600))
+
+	currencies, err := LoadCurrencies(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"USD": true, "CAD": true, "EUR": true}, currencies)
+
+	_, err = LoadCurrencies(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+}
@@ -0,0 +1,134 @@
+// Package rbac decides what each role may do, from the role_permissions table.
+package rbac
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/util"
+)
+
+// Permission names an action, as stored in the permissions table
+type Permission string
+
+const (
+	UpdateUser      Permission = "user:update"
+	ManageUsers     Permission = "user:manage"
+	FreezeAccount   Permission = "account:freeze"
+	PostCash        Permission = "cash:post"
+	ManageLimits    Permission = "limit:manage"
+	ReadAudit       Permission = "audit:read"
+	CreateTransfer  Permission = "transfer:create"
+	ReverseTransfer Permission = "transfer:reverse"
+	SettleHold      Permission = "hold:settle"
+	ApproveTransfer Permission = "transfer:approve"
+	ReviewFraud     Permission = "fraud:review"
+	ReviewSanctions Permission = "sanctions:review"
+)
+
+// Scope restricts the resources a permission applies to
+type Scope string
+
+const (
+	// ScopeOwn only applies to resources of the user, e.g. accounts they are a member of
+	ScopeOwn Scope = "own"
+	// ScopeAny applies to every resource
+	ScopeAny Scope = "any"
+)
+
+// Rule grants a permission to a role
+type Rule struct {
+	Role       string
+	Permission Permission
+	Scope      Scope
+}
+
+// Policy answers which permissions a role has, it's read-only once built
+type Policy struct {
+	grants map[string]map[Permission]Scope
+}
+
+// NewPolicy builds a policy from its rules, the widest scope wins when a permission is granted twice
+func NewPolicy(rules []Rule) (*Policy, error) {
+	policy := &Policy{grants: map[string]map[Permission]Scope{}}
+
+	for _, rule := range rules {
+		if rule.Scope != ScopeOwn && rule.Scope != ScopeAny {
+			return nil, fmt.Errorf("unsupported scope %q for %s of %s", rule.Scope, rule.Permission, rule.Role)
+		}
+
+		grants, ok := policy.grants[rule.Role]
+		if !ok {
+			grants = map[Permission]Scope{}
+			policy.grants[rule.Role] = grants
+		}
+
+		if grants[rule.Permission] != ScopeAny {
+			grants[rule.Permission] = rule.Scope
+		}
+	}
+
+	return policy, nil
+}
+
+// Load builds the policy from the role_permissions table
+func Load(ctx context.Context, store db.Querier) (*Policy, error) {
+	rows, err := store.ListRolePermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role permissions: %w", err)
+	}
+
+	rules := make([]Rule, len(rows))
+	for i, row := range rows {
+		rules[i] = Rule{
+			Role:       row.Role,
+			Permission: Permission(row.Permission),
+			Scope:      Scope(row.Scope),
+		}
+	}
+
+	return NewPolicy(rules)
+}
+
+// Scope returns the scope a role has a permission with, and false if it doesn't have it at all
+func (policy *Policy) Scope(role string, permission Permission) (Scope, bool) {
+	scope, ok := policy.grants[role][permission]
+	return scope, ok
+}
+
+// Allows reports whether a role may act on a resource, owned tells if the resource belongs to the user
+func (policy *Policy) Allows(role string, permission Permission, owned bool) bool {
+	scope, ok := policy.Scope(role, permission)
+	return ok && (scope == ScopeAny || owned)
+}
+
+// DefaultRules are the rules seeded by the migrations
+func DefaultRules() []Rule {
+	return []Rule{
+		{Role: util.DepositorRole, Permission: UpdateUser, Scope: ScopeOwn},
+		{Role: util.DepositorRole, Permission: CreateTransfer, Scope: ScopeOwn},
+		{Role: util.DepositorRole, Permission: SettleHold, Scope: ScopeOwn},
+		{Role: util.BankerRole, Permission: UpdateUser, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: ManageUsers, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: FreezeAccount, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: PostCash, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: ManageLimits, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: ReadAudit, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: CreateTransfer, Scope: ScopeOwn},
+		{Role: util.BankerRole, Permission: ReverseTransfer, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: SettleHold, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: ApproveTransfer, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: ReviewFraud, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: ReviewSanctions, Scope: ScopeAny},
+	}
+}
+
+// DefaultPolicy is the policy of DefaultRules
+func DefaultPolicy() *Policy {
+	policy, err := NewPolicy(DefaultRules())
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}
@@ -0,0 +1,86 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPolicy(t *testing.T) {
+	policy, err := NewPolicy([]Rule{
+		{Role: util.DepositorRole, Permission: CreateTransfer, Scope: ScopeOwn},
+		{Role: util.BankerRole, Permission: ReverseTransfer, Scope: ScopeAny},
+		{Role: util.BankerRole, Permission: ReverseTransfer, Scope: ScopeOwn},
+	})
+	require.NoError(t, err)
+
+	scope, ok := policy.Scope(util.DepositorRole, CreateTransfer)
+	require.True(t, ok)
+	require.Equal(t, ScopeOwn, scope)
+
+	scope, ok = policy.Scope(util.BankerRole, ReverseTransfer)
+	require.True(t, ok)
+	require.Equal(t, ScopeAny, scope)
+
+	_, ok = policy.Scope(util.DepositorRole, ReverseTransfer)
+	require.False(t, ok)
+
+	_, ok = policy.Scope("auditor", ReadAudit)
+	require.False(t, ok)
+}
+
+func TestNewPolicyInvalidScope(t *testing.T) {
+	policy, err := NewPolicy([]Rule{
+		{Role: util.BankerRole, Permission: ReadAudit, Scope: "team"},
+	})
+	require.Error(t, err)
+	require.Nil(t, policy)
+}
+
+func TestAllows(t *testing.T) {
+	policy := DefaultPolicy()
+
+	require.True(t, policy.Allows(util.DepositorRole, UpdateUser, true))
+	require.False(t, policy.Allows(util.DepositorRole, UpdateUser, false))
+	require.True(t, policy.Allows(util.BankerRole, UpdateUser, false))
+	require.False(t, policy.Allows(util.DepositorRole, PostCash, true))
+	require.False(t, policy.Allows(util.BankerRole, CreateTransfer, false))
+}
+
+func TestLoad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListRolePermissions(gomock.Any()).Times(1).Return([]db.RolePermission{
+		{Role: util.BankerRole, Permission: string(ReadAudit), Scope: string(ScopeAny)},
+	}, nil)
+
+	policy, err := Load(context.Background(), store)
+	require.NoError(t, err)
+
+	scope, ok := policy.Scope(util.BankerRole, ReadAudit)
+	require.True(t, ok)
+	require.Equal(t, ScopeAny, scope)
+
+	_, ok = policy.Scope(util.DepositorRole, ReadAudit)
+	require.False(t, ok)
+}
+
+func TestLoadError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListRolePermissions(gomock.Any()).Times(1).Return(nil, errors.New("db is down"))
+
+	policy, err := Load(context.Background(), store)
+	require.Error(t, err)
+	require.Nil(t, policy)
+}
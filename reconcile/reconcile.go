@@ -0,0 +1,107 @@
+// Package reconcile checks the ledger against the balances denormalized on the accounts.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+)
+
+// Report lists the discrepancies found by a run, amounts are in minor units
+type Report struct {
+	CheckedAt          time.Time
+	BalanceDrifts      []db.ListBalanceDriftsRow
+	TransferMismatches []db.ListTransferEntryMismatchesRow
+}
+
+// Run recomputes every balance from the entries and checks both legs of every settled transfer.
+// Each check reads a single snapshot, so transfers committing meanwhile can't show up as drift.
+func Run(ctx context.Context, store db.Querier) (Report, error) {
+	report := Report{CheckedAt: time.Now().UTC()}
+
+	drifts, err := store.ListBalanceDrifts(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to list balance drifts: %w", err)
+	}
+
+	mismatches, err := store.ListTransferEntryMismatches(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to list transfer mismatches: %w", err)
+	}
+
+	report.BalanceDrifts = drifts
+	report.TransferMismatches = mismatches
+	return report, nil
+}
+
+// Clean returns true if the ledger and the balances agree
+func (report Report) Clean() bool {
+	return len(report.BalanceDrifts) == 0 && len(report.TransferMismatches) == 0
+}
+
+func (report Report) Summary() string {
+	return fmt.Sprintf("%d balance drift(s), %d transfer mismatch(es)", len(report.BalanceDrifts), len(report.TransferMismatches))
+}
+
+// WriteText renders the report as aligned plain text for the CLI
+func (report Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Ledger reconciliation at %s: %s\n", report.CheckedAt.Format(time.RFC3339), report.Summary())
+
+	if len(report.BalanceDrifts) > 0 {
+		fmt.Fprintln(tw, "\nACCOUNT\tOWNER\tCURRENCY\tBALANCE\tENTRIES\tDRIFT")
+		for _, drift := range report.BalanceDrifts {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%+d\n",
+				drift.ID, drift.Owner, drift.Currency, drift.Balance, drift.EntriesTotal, drift.Balance-drift.EntriesTotal)
+		}
+	}
+
+	if len(report.TransferMismatches) > 0 {
+		fmt.Fprintln(tw, "\nTRANSFER\tFROM\tTO\tAMOUNT\tTO AMOUNT\tENTRIES\tDEBITED\tCREDITED")
+		for _, mismatch := range report.TransferMismatches {
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+				mismatch.ID, mismatch.FromAccountID, mismatch.ToAccountID, mismatch.Amount, mismatch.ToAmount,
+				mismatch.EntryCount, -mismatch.FromTotal, mismatch.ToTotal)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// HTML renders the report as the body of the alert mailed to bankers
+func (report Report) HTML() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<p>The ledger reconciliation at <b>%s</b> found %s.</p>",
+		report.CheckedAt.Format(time.RFC3339), report.Summary())
+
+	if len(report.BalanceDrifts) > 0 {
+		b.WriteString("<h3>Balance drifts</h3><table border=\"1\" cellpadding=\"4\">")
+		b.WriteString("<tr><th>Account</th><th>Owner</th><th>Currency</th><th>Balance</th><th>Entries</th><th>Drift</th></tr>")
+		for _, drift := range report.BalanceDrifts {
+			fmt.Fprintf(&b, "<tr><td>%d</td><td>%s</td><td>%s</td><td>%d</td><td>%d</td><td>%+d</td></tr>",
+				drift.ID, html.EscapeString(drift.Owner), drift.Currency, drift.Balance, drift.EntriesTotal, drift.Balance-drift.EntriesTotal)
+		}
+		b.WriteString("</table>")
+	}
+
+	if len(report.TransferMismatches) > 0 {
+		b.WriteString("<h3>Transfers with missing or mismatched entries</h3><table border=\"1\" cellpadding=\"4\">")
+		b.WriteString("<tr><th>Transfer</th><th>From</th><th>To</th><th>Amount</th><th>To amount</th><th>Entries</th><th>Debited</th><th>Credited</th></tr>")
+		for _, mismatch := range report.TransferMismatches {
+			fmt.Fprintf(&b, "<tr><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td></tr>",
+				mismatch.ID, mismatch.FromAccountID, mismatch.ToAccountID, mismatch.Amount, mismatch.ToAmount,
+				mismatch.EntryCount, -mismatch.FromTotal, mismatch.ToTotal)
+		}
+		b.WriteString("</table>")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	drift := db.ListBalanceDriftsRow{ID: 7, Owner: "alice", Currency: "USD", Balance: 1500, EntriesTotal: 1000}
+	mismatch := db.ListTransferEntryMismatchesRow{
+		ID: 3, FromAccountID: 7, ToAccountID: 9, Amount: 500, ToAmount: 500, EntryCount: 1, FromTotal: -500,
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListBalanceDrifts(gomock.Any()).Times(1).Return([]db.ListBalanceDriftsRow{drift}, nil)
+	store.EXPECT().ListTransferEntryMismatches(gomock.Any()).Times(1).Return([]db.ListTransferEntryMismatchesRow{mismatch}, nil)
+
+	report, err := Run(context.Background(), store)
+	require.NoError(t, err)
+	require.False(t, report.Clean())
+	require.Equal(t, "1 balance drift(s), 1 transfer mismatch(es)", report.Summary())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	text := buf.String()
+	require.Contains(t, text, "1 balance drift(s)")
+	require.Regexp(t, `7\s+alice\s+USD\s+1500\s+1000\s+\+500`, text)
+	require.Regexp(t, `3\s+7\s+9\s+500\s+500\s+1\s+500\s+0`, text)
+
+	require.Contains(t, report.HTML(), "<td>alice</td>")
+}
+
+func TestRunClean(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListBalanceDrifts(gomock.Any()).Times(1).Return([]db.ListBalanceDriftsRow{}, nil)
+	store.EXPECT().ListTransferEntryMismatches(gomock.Any()).Times(1).Return([]db.ListTransferEntryMismatchesRow{}, nil)
+
+	report, err := Run(context.Background(), store)
+	require.NoError(t, err)
+	require.True(t, report.Clean())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	require.NotContains(t, buf.String(), "ACCOUNT")
+}
+
+func TestRunError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListBalanceDrifts(gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+	store.EXPECT().ListTransferEntryMismatches(gomock.Any()).Times(0)
+
+	_, err := Run(context.Background(), store)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
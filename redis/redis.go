@@ -12,6 +12,7 @@ var ctx = context.Background()
 type Store interface {
 	Set(key string, value interface{}, expiration time.Duration) error
 	Get(key string) (string, error)
+	GetDel(key string) (string, error)
 	Del(key string) error
 	Exists(key string) (int64, error)
 	ZRemRangeByScore(key string, start, end string) error
@@ -39,6 +40,10 @@ func (store *RedisStore) Get(key string) (string, error) {
 	return store.client.Get(ctx, key).Result()
 }
 
+func (store *RedisStore) GetDel(key string) (string, error) {
+	return store.client.GetDel(ctx, key).Result()
+}
+
 func (store *RedisStore) Del(key string) error {
 	return store.client.Del(ctx, key).Err()
 }
@@ -0,0 +1,159 @@
+// Package sanctions screens names against a sanctions list loaded from a local file.
+package sanctions
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Entry is a sanctioned person or organisation with the other names it is known by
+type Entry struct {
+	ID      string
+	Name    string
+	Aliases []string
+	Program string
+}
+
+// Names returns the name of the entry followed by its aliases
+func (entry Entry) Names() []string {
+	return append([]string{entry.Name}, entry.Aliases...)
+}
+
+// sdnRemarksColumn is the index of the remarks in the OFAC SDN CSV
+const sdnRemarksColumn = 11
+
+// sdnNull is how the OFAC SDN files write an empty field
+const sdnNull = "-0-"
+
+// akaPattern finds the aliases OFAC lists in the remarks of an entry, such as a.k.a. 'NAME'
+var akaPattern = regexp.MustCompile(`a\.k\.a\. '([^']+)'`)
+
+// Load reads a list file by its extension, .csv for the OFAC SDN list and .xml for the UN consolidated list
+func Load(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sanctions list: %w", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ParseCSV(file)
+	case ".xml":
+		return ParseXML(file)
+	default:
+		return nil, fmt.Errorf("unsupported sanctions list format %q", filepath.Ext(path))
+	}
+}
+
+// ParseCSV reads the OFAC SDN list (sdn.csv), which has no header and starts each row with
+// the entry number, name, type and program. Aliases are taken from the remarks.
+func ParseCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	entries := []Entry{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sanctions list: %w", err)
+		}
+
+		// the file ends with a control character on a row of its own
+		if len(record) < 4 {
+			continue
+		}
+
+		name := sdnField(record[1])
+		if name == "" {
+			continue
+		}
+
+		entry := Entry{
+			ID:      sdnField(record[0]),
+			Name:    name,
+			Program: sdnField(record[3]),
+		}
+		if len(record) > sdnRemarksColumn {
+			for _, match := range akaPattern.FindAllStringSubmatch(record[sdnRemarksColumn], -1) {
+				entry.Aliases = append(entry.Aliases, match[1])
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func sdnField(field string) string {
+	field = strings.TrimSpace(field)
+	if field == sdnNull {
+		return ""
+	}
+	return field
+}
+
+type unAlias struct {
+	Name string `xml:"ALIAS_NAME"`
+}
+
+type unSubject struct {
+	ReferenceNumber string    `xml:"REFERENCE_NUMBER"`
+	ListType        string    `xml:"UN_LIST_TYPE"`
+	FirstName       string    `xml:"FIRST_NAME"`
+	SecondName      string    `xml:"SECOND_NAME"`
+	ThirdName       string    `xml:"THIRD_NAME"`
+	FourthName      string    `xml:"FOURTH_NAME"`
+	IndividualAlias []unAlias `xml:"INDIVIDUAL_ALIAS"`
+	EntityAlias     []unAlias `xml:"ENTITY_ALIAS"`
+}
+
+type unList struct {
+	Individuals []unSubject `xml:"INDIVIDUALS>INDIVIDUAL"`
+	Entities    []unSubject `xml:"ENTITIES>ENTITY"`
+}
+
+// ParseXML reads the consolidated list of the UN Security Council, both its individuals and entities
+func ParseXML(r io.Reader) ([]Entry, error) {
+	var list unList
+	if err := xml.NewDecoder(r).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to parse sanctions list: %w", err)
+	}
+
+	entries := []Entry{}
+	for _, subject := range append(list.Individuals, list.Entities...) {
+		name := strings.Join(strings.Fields(strings.Join([]string{
+			subject.FirstName, subject.SecondName, subject.ThirdName, subject.FourthName,
+		}, " ")), " ")
+		if name == "" {
+			continue
+		}
+
+		entry := Entry{
+			ID:      strings.TrimSpace(subject.ReferenceNumber),
+			Name:    name,
+			Program: strings.TrimSpace(subject.ListType),
+		}
+		for _, alias := range append(subject.IndividualAlias, subject.EntityAlias...) {
+			if alias := strings.TrimSpace(alias.Name); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
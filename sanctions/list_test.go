@@ -0,0 +1,58 @@
+package sanctions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCSV(t *testing.T) {
+	entries, err := Load(filepath.Join("testdata", "sdn.csv"))
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	require.Equal(t, Entry{
+		ID:      "36",
+		Name:    "KOVALENKO, Viktor Ivanovich",
+		Aliases: []string{"KOVALENKO, Vitya", "KOVALENKA, Viktar"},
+		Program: "UKRAINE-EO13660",
+	}, entries[0])
+	require.Equal(t, "NORTHWIND MARITIME LIMITED", entries[1].Name)
+	require.Empty(t, entries[1].Aliases)
+	require.Equal(t, "ARAL STAR", entries[2].Name)
+}
+
+func TestLoadXML(t *testing.T) {
+	entries, err := Load(filepath.Join("testdata", "consolidated.xml"))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	require.Equal(t, Entry{
+		ID:      "QDi.902",
+		Name:    "HAMID RASHID AL-NOURI",
+		Aliases: []string{"Abu Rashid"},
+		Program: "Al-Qaida",
+	}, entries[0])
+	require.Equal(t, Entry{
+		ID:      "KPe.077",
+		Name:    "EASTERN CRESCENT TRADING COMPANY",
+		Aliases: []string{"ECTC"},
+		Program: "DPRK",
+	}, entries[1])
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := Load(filepath.Join("testdata", "missing.csv"))
+	require.ErrorContains(t, err, "failed to open sanctions list")
+
+	path := filepath.Join(t.TempDir(), "list.json")
+	require.NoError(t, os.WriteFile(path, []byte("[]"), 0o600))
+	_, err = Load(path)
+	require.ErrorContains(t, err, "unsupported sanctions list format")
+
+	_, err = ParseXML(strings.NewReader("<CONSOLIDATED_LIST>"))
+	require.ErrorContains(t, err, "failed to parse sanctions list")
+}
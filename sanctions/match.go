@@ -0,0 +1,88 @@
+package sanctions
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// winklerPrefix is the longest common prefix Jaro-Winkler rewards, and winklerScale how much each rune of it counts
+const (
+	winklerPrefix = 4
+	winklerScale  = 0.1
+)
+
+// Similarity scores how alike two names are from 0 to 1 with Jaro-Winkler.
+// Case, punctuation and the order of the words don't count, so "BIN LADEN, Usama" matches "Usama bin Laden".
+func Similarity(a, b string) float64 {
+	return jaroWinkler([]rune(normalize(a)), []rune(normalize(b)))
+}
+
+// normalize lower-cases a name and sorts its words
+func normalize(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+func jaroWinkler(a, b []rune) float64 {
+	similarity := jaro(a, b)
+
+	prefix := 0
+	for prefix < min(len(a), len(b), winklerPrefix) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	return similarity + float64(prefix)*winklerScale*(1-similarity)
+}
+
+func jaro(a, b []rune) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	// runes only match when they are at most window apart
+	window := max(max(len(a), len(b))/2-1, 0)
+
+	matchedA := make([]bool, len(a))
+	matchedB := make([]bool, len(b))
+	matches := 0
+	for i := range a {
+		for j := max(i-window, 0); j < min(i+window+1, len(b)); j++ {
+			if matchedB[j] || a[i] != b[j] {
+				continue
+			}
+			matchedA[i] = true
+			matchedB[j] = true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// matched runes out of order are half transpositions
+	halfTranspositions := 0
+	j := 0
+	for i := range a {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			halfTranspositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(a)) + m/float64(len(b)) + (m-float64(halfTranspositions)/2)/m) / 3
+}
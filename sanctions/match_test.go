@@ -0,0 +1,33 @@
+package sanctions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	// the textbook examples of Winkler
+	testCases := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"martha", "marhta", 0.961},
+		{"dwayne", "duane", 0.840},
+		{"dixon", "dicksonx", 0.813},
+		{"abc", "xyz", 0},
+		{"", "", 1},
+		{"abc", "", 0},
+	}
+
+	for _, tc := range testCases {
+		require.InDelta(t, tc.expected, jaroWinkler([]rune(tc.a), []rune(tc.b)), 0.001, "%s %s", tc.a, tc.b)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	require.Equal(t, 1.0, Similarity("KOVALENKO, Viktor", "viktor kovalenko"))
+	require.Equal(t, 1.0, Similarity("Al-Nouri", "al nouri"))
+	require.Greater(t, Similarity("Viktor Kovalenko", "Victor Kovalenko"), 0.95)
+	require.Less(t, Similarity("Viktor Kovalenko", "Alice Chen"), 0.7)
+}
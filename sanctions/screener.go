@@ -0,0 +1,145 @@
+package sanctions
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNoList is returned when a screener is reloaded without a list file
+var ErrNoList = errors.New("no sanctions list is configured")
+
+// Match is the list entry a screened name is most similar to
+type Match struct {
+	Entry Entry
+	// Name is the name or alias of the entry that matched
+	Name  string
+	Score float64
+}
+
+type indexedName struct {
+	entry      int
+	name       string
+	normalized []rune
+}
+
+// fileVersion tells a replaced list file from the one that was read
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// Screener matches names against the list file at path. It is safe for concurrent use,
+// and Reload swaps in a new version of the file while names are being screened.
+// Screen reloads a file replaced since it was read, so every process sharing the file follows it.
+type Screener struct {
+	path      string
+	threshold float64
+
+	mu      sync.RWMutex
+	entries []Entry
+	names   []indexedName
+	// version is the file last read, a file that couldn't be parsed isn't read again until it changes
+	version fileVersion
+}
+
+// NewScreener loads the list at path, names scoring at least threshold are matches
+func NewScreener(path string, threshold float64) (*Screener, error) {
+	screener := &Screener{
+		path:      path,
+		threshold: threshold,
+	}
+	if _, err := screener.Reload(); err != nil {
+		return nil, err
+	}
+	return screener, nil
+}
+
+// Reload reads the list file again and returns the number of entries.
+// The loaded list is kept when the file can't be read.
+func (screener *Screener) Reload() (int, error) {
+	if screener.path == "" {
+		return 0, ErrNoList
+	}
+
+	info, err := os.Stat(screener.path)
+	if err != nil {
+		return 0, err
+	}
+	version := fileVersion{modTime: info.ModTime(), size: info.Size()}
+
+	entries, err := Load(screener.path)
+	if err != nil {
+		screener.mu.Lock()
+		screener.version = version
+		screener.mu.Unlock()
+		return 0, err
+	}
+
+	names := []indexedName{}
+	for i, entry := range entries {
+		for _, name := range entry.Names() {
+			names = append(names, indexedName{
+				entry:      i,
+				name:       name,
+				normalized: []rune(normalize(name)),
+			})
+		}
+	}
+
+	screener.mu.Lock()
+	defer screener.mu.Unlock()
+
+	screener.entries = entries
+	screener.names = names
+	screener.version = version
+	return len(entries), nil
+}
+
+// refresh reloads the list when its file changed since it was last read, the loaded list stays in use otherwise
+func (screener *Screener) refresh() {
+	info, err := os.Stat(screener.path)
+	if err != nil {
+		return
+	}
+
+	screener.mu.RLock()
+	changed := !info.ModTime().Equal(screener.version.modTime) || info.Size() != screener.version.size
+	screener.mu.RUnlock()
+
+	if changed {
+		_, _ = screener.Reload()
+	}
+}
+
+// Screen returns the entry most similar to name when it scores at least the threshold
+func (screener *Screener) Screen(name string) (Match, bool) {
+	normalized := []rune(normalize(name))
+	if len(normalized) == 0 {
+		return Match{}, false
+	}
+
+	screener.refresh()
+
+	screener.mu.RLock()
+	defer screener.mu.RUnlock()
+
+	var best Match
+	found := false
+	for _, candidate := range screener.names {
+		score := jaroWinkler(normalized, candidate.normalized)
+		if score < screener.threshold || (found && score <= best.Score) {
+			continue
+		}
+
+		best = Match{
+			Entry: screener.entries[candidate.entry],
+			Name:  candidate.name,
+			Score: score,
+		}
+		found = true
+	}
+
+	return best, found
+}
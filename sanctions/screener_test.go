@@ -0,0 +1,98 @@
+package sanctions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testThreshold = 0.93
+
+func TestScreen(t *testing.T) {
+	screener, err := NewScreener(filepath.Join("testdata", "sdn.csv"), testThreshold)
+	require.NoError(t, err)
+
+	match, ok := screener.Screen("Viktor Kovalenko")
+	require.True(t, ok)
+	require.Equal(t, "36", match.Entry.ID)
+	require.Equal(t, "KOVALENKO, Viktor Ivanovich", match.Entry.Name)
+
+	// an alias is a closer match than the full name
+	match, ok = screener.Screen("Vitya Kovalenko")
+	require.True(t, ok)
+	require.Equal(t, "KOVALENKO, Vitya", match.Name)
+	require.Equal(t, 1.0, match.Score)
+
+	match, ok = screener.Screen("Nortwind Maritime Limited")
+	require.True(t, ok)
+	require.Equal(t, "173", match.Entry.ID)
+	require.GreaterOrEqual(t, match.Score, testThreshold)
+
+	_, ok = screener.Screen("Alice Chen")
+	require.False(t, ok)
+
+	_, ok = screener.Screen(" ,. ")
+	require.False(t, ok)
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdn.csv")
+	writeList := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	writeList(`1,"CHEN, Alice","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ` + "\n")
+	screener, err := NewScreener(path, testThreshold)
+	require.NoError(t, err)
+
+	_, ok := screener.Screen("Alice Chen")
+	require.True(t, ok)
+	_, ok = screener.Screen("Viktor Kovalenko")
+	require.False(t, ok)
+
+	writeList(`36,"KOVALENKO, Viktor","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ` + "\n" +
+		`37,"KOVALENKO, Olena","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ` + "\n")
+	count, err := screener.Reload()
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	_, ok = screener.Screen("Alice Chen")
+	require.False(t, ok)
+	_, ok = screener.Screen("Viktor Kovalenko")
+	require.True(t, ok)
+
+	// a broken file leaves the loaded list in place
+	require.NoError(t, os.Remove(path))
+	_, err = screener.Reload()
+	require.Error(t, err)
+	_, ok = screener.Screen("Viktor Kovalenko")
+	require.True(t, ok)
+}
+
+func TestScreenFollowsReplacedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdn.csv")
+	require.NoError(t, os.WriteFile(path, []byte(`1,"CHEN, Alice","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- `+"\n"), 0o600))
+
+	screener, err := NewScreener(path, testThreshold)
+	require.NoError(t, err)
+	_, ok := screener.Screen("Viktor Kovalenko")
+	require.False(t, ok)
+
+	// another process replaced the file, no one reloaded this screener
+	require.NoError(t, os.WriteFile(path, []byte(`36,"KOVALENKO, Viktor","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- `+"\n"), 0o600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	_, ok = screener.Screen("Viktor Kovalenko")
+	require.True(t, ok)
+	_, ok = screener.Screen("Alice Chen")
+	require.False(t, ok)
+}
+
+func TestNewScreenerWithoutList(t *testing.T) {
+	_, err := NewScreener("", testThreshold)
+	require.ErrorIs(t, err, ErrNoList)
+}
@@ -0,0 +1,42 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+var csvHeader = []string{"date", "entry_id", "transfer_id", "description", "amount", "balance", "currency"}
+
+// WriteCSV renders the statement as CSV, framed by opening and closing balance rows
+func WriteCSV(w io.Writer, statement Statement) error {
+	writer := csv.NewWriter(w)
+
+	rows := [][]string{
+		csvHeader,
+		{statement.From.Format(dateLayout), "", "", "Opening balance", "", statement.format(statement.OpeningBalance), statement.Currency},
+	}
+	for _, line := range statement.Lines {
+		transferID := ""
+		if line.TransferID != 0 {
+			transferID = strconv.FormatInt(line.TransferID, 10)
+		}
+		rows = append(rows, []string{
+			line.Date.Format(dateLayout),
+			strconv.FormatInt(line.EntryID, 10),
+			transferID,
+			line.Description,
+			statement.format(line.Amount),
+			statement.format(line.Balance),
+			statement.Currency,
+		})
+	}
+	rows = append(rows, []string{
+		statement.To.AddDate(0, 0, -1).Format(dateLayout), "", "", "Closing balance", "", statement.format(statement.ClosingBalance), statement.Currency,
+	})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
@@ -0,0 +1,132 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The PDF is laid out in Courier on A4 pages, a monospaced font lets columns be aligned by padding
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	pageMargin   = 40
+	fontSize     = 9
+	lineHeight   = 12
+	linesPerPage = (pageHeight - 2*pageMargin) / lineHeight
+)
+
+const rowFormat = "%-10s  %-40s  %18s  %18s"
+
+// WritePDF renders the statement as a PDF document
+func WritePDF(w io.Writer, statement Statement) error {
+	credits, debits := statement.Totals()
+
+	header := []string{
+		"Go2Bank Account Statement",
+		"",
+		fmt.Sprintf("Account:  #%d (%s)", statement.AccountID, statement.Currency),
+		fmt.Sprintf("Owner:    %s", statement.Owner),
+		fmt.Sprintf("Period:   %s", statement.Period()),
+		"",
+		fmt.Sprintf(rowFormat, "Date", "Description", "Amount", "Balance"),
+		strings.Repeat("-", 92),
+		fmt.Sprintf(rowFormat, statement.From.Format(dateLayout), "Opening balance", "", statement.format(statement.OpeningBalance)),
+	}
+
+	lines := header
+	for _, line := range statement.Lines {
+		lines = append(lines, fmt.Sprintf(rowFormat,
+			line.Date.Format(dateLayout),
+			truncate(line.Description, 40),
+			statement.format(line.Amount),
+			statement.format(line.Balance),
+		))
+	}
+	lines = append(lines,
+		strings.Repeat("-", 92),
+		fmt.Sprintf(rowFormat, statement.To.AddDate(0, 0, -1).Format(dateLayout), "Closing balance", "", statement.format(statement.ClosingBalance)),
+		"",
+		fmt.Sprintf("Total credits: %s %s", statement.format(credits), statement.Currency),
+		fmt.Sprintf("Total debits:  %s %s", statement.format(debits), statement.Currency),
+	)
+
+	var pages [][]string
+	for len(lines) > linesPerPage {
+		pages = append(pages, lines[:linesPerPage])
+		lines = lines[linesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	_, err := w.Write(renderPDF(pages))
+	return err
+}
+
+// renderPDF writes a minimal PDF 1.4 document with one text stream per page
+func renderPDF(pages [][]string) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1 to 3 are the catalog, the page tree and the font, then each page takes a page and a content object
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, pageMargin, pageHeight-pageMargin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", escapePDF(line))
+		}
+		content.WriteString("ET")
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// escapePDF makes text safe for a PDF string literal, characters outside ASCII are replaced since only the standard font is embedded
+func escapePDF(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func truncate(text string, width int) string {
+	if len(text) <= width {
+		return text
+	}
+	return text[:width-3] + "..."
+}
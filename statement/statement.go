@@ -0,0 +1,127 @@
+// Package statement renders account statements from the ledger.
+package statement
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
+)
+
+const dateLayout = "2006-01-02"
+
+// Statement lists the entries of an account over [From, To)
+type Statement struct {
+	AccountID      int64
+	Owner          string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	Lines          []Line
+}
+
+// Line is a single entry of the statement, Balance is the account balance right after it
+type Line struct {
+	EntryID     int64
+	TransferID  int64
+	Date        time.Time
+	Description string
+	Amount      int64
+	Balance     int64
+}
+
+// Build collects the entries of account posted in [from, to)
+func Build(ctx context.Context, store db.Querier, account db.Account, from, to time.Time) (Statement, error) {
+	statement := Statement{
+		AccountID: account.ID,
+		Owner:     account.Owner,
+		Currency:  account.Currency,
+		From:      from,
+		To:        to,
+	}
+
+	opening, err := store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+		AtTime:    from,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		return statement, fmt.Errorf("failed to get opening balance: %w", err)
+	}
+
+	entries, err := store.ListStatementEntries(ctx, db.ListStatementEntriesParams{
+		AccountID: account.ID,
+		FromTime:  from,
+		ToTime:    to,
+	})
+	if err != nil {
+		return statement, fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	statement.OpeningBalance = opening
+	statement.ClosingBalance = opening
+	for _, entry := range entries {
+		statement.Lines = append(statement.Lines, Line{
+			EntryID:     entry.ID,
+			TransferID:  entry.TransferID.Int64,
+			Date:        entry.CreatedAt,
+			Description: describe(entry),
+			Amount:      entry.Amount,
+			Balance:     entry.RunningBalance,
+		})
+		statement.ClosingBalance = entry.RunningBalance
+	}
+
+	return statement, nil
+}
+
+func describe(entry db.ListStatementEntriesRow) string {
+	switch {
+	case entry.CashTransactionID.Valid && entry.Amount > 0:
+		return fmt.Sprintf("Cash deposit, ref %s", entry.CashReference.String)
+	case entry.CashTransactionID.Valid:
+		return fmt.Sprintf("Cash withdrawal, ref %s", entry.CashReference.String)
+	case !entry.TransferID.Valid:
+		return "Adjustment"
+	case entry.ReversalOf.Valid:
+		return fmt.Sprintf("Reversal of transfer #%d", entry.ReversalOf.Int64)
+	case entry.Amount < 0:
+		return fmt.Sprintf("Transfer to account #%d", entry.ToAccountID.Int64)
+	default:
+		return fmt.Sprintf("Transfer from account #%d", entry.FromAccountID.Int64)
+	}
+}
+
+// Totals returns the sum of credits and the sum of debits, debits as a positive amount
+func (statement Statement) Totals() (credits int64, debits int64) {
+	for _, line := range statement.Lines {
+		if line.Amount > 0 {
+			credits += line.Amount
+		} else {
+			debits -= line.Amount
+		}
+	}
+	return
+}
+
+// Period describes the statement range with an inclusive end date
+func (statement Statement) Period() string {
+	return fmt.Sprintf("%s to %s", statement.From.Format(dateLayout), statement.To.AddDate(0, 0, -1).Format(dateLayout))
+}
+
+// Filename names the rendered statement, ext is the format without the dot
+func (statement Statement) Filename(ext string) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s",
+		statement.AccountID,
+		statement.From.Format(dateLayout),
+		statement.To.AddDate(0, 0, -1).Format(dateLayout),
+		ext,
+	)
+}
+
+func (statement Statement) format(amount int64) string {
+	return money.New(amount, statement.Currency).Decimal()
+}
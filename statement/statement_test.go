@@ -0,0 +1,154 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	from = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to   = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func testStatement() Statement {
+	return Statement{
+		AccountID:      7,
+		Owner:          "alice",
+		Currency:       "USD",
+		From:           from,
+		To:             to,
+		OpeningBalance: 10000,
+		ClosingBalance: 12550,
+		Lines: []Line{
+			{EntryID: 1, TransferID: 3, Date: from.Add(time.Hour), Description: "Transfer from account #9", Amount: 5000, Balance: 15000},
+			{EntryID: 2, TransferID: 4, Date: from.Add(48 * time.Hour), Description: "Transfer to account #9", Amount: -2450, Balance: 12550},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := db.Account{ID: 7, Owner: "alice", Currency: "USD", Balance: 20000}
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetAccountBalanceAt(gomock.Any(), gomock.Eq(db.GetAccountBalanceAtParams{
+			AtTime:    from,
+			AccountID: account.ID,
+		})).
+		Times(1).
+		Return(int64(10000), nil)
+	store.EXPECT().
+		ListStatementEntries(gomock.Any(), gomock.Eq(db.ListStatementEntriesParams{
+			AccountID: account.ID,
+			FromTime:  from,
+			ToTime:    to,
+		})).
+		Times(1).
+		Return([]db.ListStatementEntriesRow{
+			{ID: 1, Amount: 5000, RunningBalance: 15000, TransferID: pgtype.Int8{Int64: 3, Valid: true},
+				FromAccountID: pgtype.Int8{Int64: 9, Valid: true}, ToAccountID: pgtype.Int8{Int64: 7, Valid: true}},
+			{ID: 2, Amount: -2450, RunningBalance: 12550, TransferID: pgtype.Int8{Int64: 4, Valid: true},
+				FromAccountID: pgtype.Int8{Int64: 7, Valid: true}, ToAccountID: pgtype.Int8{Int64: 9, Valid: true}},
+			{ID: 3, Amount: 2450, RunningBalance: 15000, TransferID: pgtype.Int8{Int64: 5, Valid: true},
+				FromAccountID: pgtype.Int8{Int64: 9, Valid: true}, ToAccountID: pgtype.Int8{Int64: 7, Valid: true},
+				ReversalOf: pgtype.Int8{Int64: 4, Valid: true}},
+			{ID: 4, Amount: 100, RunningBalance: 15100},
+			{ID: 5, Amount: -100, RunningBalance: 15000, CashTransactionID: pgtype.Int8{Int64: 2, Valid: true},
+				CashReference: pgtype.Text{String: "TELLER-42", Valid: true}},
+		}, nil)
+
+	statement, err := Build(context.Background(), store, account, from, to)
+	require.NoError(t, err)
+	require.Equal(t, int64(10000), statement.OpeningBalance)
+	require.Equal(t, int64(15000), statement.ClosingBalance)
+	require.Len(t, statement.Lines, 5)
+	require.Equal(t, "Transfer from account #9", statement.Lines[0].Description)
+	require.Equal(t, "Transfer to account #9", statement.Lines[1].Description)
+	require.Equal(t, "Reversal of transfer #4", statement.Lines[2].Description)
+	require.Equal(t, "Adjustment", statement.Lines[3].Description)
+	require.Equal(t, "Cash withdrawal, ref TELLER-42", statement.Lines[4].Description)
+
+	credits, debits := statement.Totals()
+	require.Equal(t, int64(7550), credits)
+	require.Equal(t, int64(2550), debits)
+}
+
+func TestBuildEmptyPeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := db.Account{ID: 7, Owner: "alice", Currency: "USD", Balance: 20000}
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Any()).Times(1).Return(int64(20000), nil)
+	store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(1).Return([]db.ListStatementEntriesRow{}, nil)
+
+	statement, err := Build(context.Background(), store, account, from, to)
+	require.NoError(t, err)
+	require.Empty(t, statement.Lines)
+	require.Equal(t, int64(20000), statement.OpeningBalance)
+	require.Equal(t, int64(20000), statement.ClosingBalance)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, testStatement())
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"date", "entry_id", "transfer_id", "description", "amount", "balance", "currency"},
+		{"2024-01-01", "", "", "Opening balance", "", "100.00", "USD"},
+		{"2024-01-01", "1", "3", "Transfer from account #9", "50.00", "150.00", "USD"},
+		{"2024-01-03", "2", "4", "Transfer to account #9", "-24.50", "125.50", "USD"},
+		{"2024-01-31", "", "", "Closing balance", "", "125.50", "USD"},
+	}, rows)
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	err := WritePDF(&buf, testStatement())
+	require.NoError(t, err)
+
+	pdf := buf.String()
+	require.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	require.Contains(t, pdf, "/Count 1")
+	require.Contains(t, pdf, "Period:   2024-01-01 to 2024-01-31")
+	require.Contains(t, pdf, "Transfer to account #9")
+	require.Contains(t, pdf, "Total debits:  24.50 USD")
+
+	// startxref must point at the cross-reference table
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	require.NotNil(t, m)
+	offset, err := strconv.Atoi(m[1])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(pdf[offset:], "xref\n"))
+}
+
+func TestWritePDFPages(t *testing.T) {
+	statement := testStatement()
+	for i := 0; i < 2*linesPerPage; i++ {
+		statement.Lines = append(statement.Lines, Line{EntryID: int64(i), Date: from, Description: "Adjustment (manual)"})
+	}
+
+	var buf bytes.Buffer
+	err := WritePDF(&buf, statement)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "/Count 3")
+	require.Contains(t, buf.String(), `Adjustment \(manual\)`)
+}
@@ -8,22 +8,51 @@ import (
 
 // Config is the application configuration
 type Config struct {
-	DBSource               string        `mapstructure:"DB_SOURCE"`
-	AllowedOrigins         []string      `mapstructure:"ALLOWED_ORIGINS"`
-	HTTPServerAddress      string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	TokenSymmetricKey      string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration    time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration   time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	RedisURL               string        `mapstructure:"REDIS_URL"`
-	MigrationURL           string        `mapstructure:"MIGRATION_URL"`
-	EmailSenderName        string        `mapstructure:"EMAIL_SENDER_NAME"`
-	EmailSenderAddress     string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
-	EmailSenderPassword    string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
-	APILimitBound          int64         `mapstructure:"API_LIMIT_BOUND"`
-	APILimitDuration       time.Duration `mapstructure:"API_LIMIT_DURATION"`
-	GoogleClientID         string        `mapstructure:"GOOGLE_OAUTH_CLIENT_ID"`
-	GoogleClientSecret     string        `mapstructure:"GOOGLE_OAUTH_CLIENT_SECRET"`
-	GoogleOAuthRedirectUrl string        `mapstructure:"GOOGLE_OAUTH_REDIRECT_URL"`
+	DBSource                  string        `mapstructure:"DB_SOURCE"`
+	AllowedOrigins            []string      `mapstructure:"ALLOWED_ORIGINS"`
+	HTTPServerAddress         string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	TokenSymmetricKey         string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration       time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration      time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	RedisURL                  string        `mapstructure:"REDIS_URL"`
+	MigrationURL              string        `mapstructure:"MIGRATION_URL"`
+	EmailSenderName           string        `mapstructure:"EMAIL_SENDER_NAME"`
+	EmailSenderAddress        string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
+	EmailSenderPassword       string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
+	APILimitBound             int64         `mapstructure:"API_LIMIT_BOUND"`
+	APILimitDuration          time.Duration `mapstructure:"API_LIMIT_DURATION"`
+	GoogleClientID            string        `mapstructure:"GOOGLE_OAUTH_CLIENT_ID"`
+	GoogleClientSecret        string        `mapstructure:"GOOGLE_OAUTH_CLIENT_SECRET"`
+	GoogleOAuthRedirectUrl    string        `mapstructure:"GOOGLE_OAUTH_REDIRECT_URL"`
+	ScheduledTransferInterval time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`
+	RoundingMode              string        `mapstructure:"ROUNDING_MODE"`
+	RatesSourceURL            string        `mapstructure:"RATES_SOURCE_URL"`
+	RatesCurrencyFile         string        `mapstructure:"RATES_CURRENCY_FILE"`
+	RatesSyncInterval         time.Duration `mapstructure:"RATES_SYNC_INTERVAL"`
+	StatementSchedule         string        `mapstructure:"STATEMENT_SCHEDULE"`
+	HoldExpiryInterval        time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	OutboxPublisher           string        `mapstructure:"OUTBOX_PUBLISHER"`
+	OutboxStream              string        `mapstructure:"OUTBOX_STREAM"`
+	OutboxRelayInterval       time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	OutboxClaimLease          time.Duration `mapstructure:"OUTBOX_CLAIM_LEASE"`
+	OutboxMaxAttempts         int32         `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	ReconcileSchedule         string        `mapstructure:"RECONCILE_SCHEDULE"`
+	FXQuoteTTL                time.Duration `mapstructure:"FX_QUOTE_TTL"`
+	IdempotencyKeyTTL         time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	IdempotencySweepInterval  time.Duration `mapstructure:"IDEMPOTENCY_SWEEP_INTERVAL"`
+	// TransferApprovalThreshold is in major units of TransferApprovalCurrency, empty disables approvals
+	TransferApprovalThreshold      string        `mapstructure:"TRANSFER_APPROVAL_THRESHOLD"`
+	TransferApprovalCurrency       string        `mapstructure:"TRANSFER_APPROVAL_CURRENCY"`
+	TransferApprovalTTL            time.Duration `mapstructure:"TRANSFER_APPROVAL_TTL"`
+	TransferApprovalExpiryInterval time.Duration `mapstructure:"TRANSFER_APPROVAL_EXPIRY_INTERVAL"`
+	FraudVelocityLimit             int64         `mapstructure:"FRAUD_VELOCITY_LIMIT"`
+	FraudVelocityWindow            time.Duration `mapstructure:"FRAUD_VELOCITY_WINDOW"`
+	FraudAmountFactor              int64         `mapstructure:"FRAUD_AMOUNT_FACTOR"`
+	FraudAmountHistory             int32         `mapstructure:"FRAUD_AMOUNT_HISTORY"`
+	FraudRoundTripWindow           time.Duration `mapstructure:"FRAUD_ROUND_TRIP_WINDOW"`
+	// SanctionsListPath is an OFAC SDN .csv or UN consolidated .xml file, empty disables sanctions screening
+	SanctionsListPath       string  `mapstructure:"SANCTIONS_LIST_PATH"`
+	SanctionsMatchThreshold float64 `mapstructure:"SANCTIONS_MATCH_THRESHOLD"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
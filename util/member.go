@@ -0,0 +1,27 @@
+package util
+
+// Constants for the roles of a member of an account, from the most to the least privileged
+const (
+	MemberRoleOwner       = "owner"
+	MemberRoleCanTransfer = "can_transfer"
+	MemberRoleViewOnly    = "view_only"
+)
+
+// memberRoleRanks orders the member roles, a role may do anything a lower ranked one may
+var memberRoleRanks = map[string]int{
+	MemberRoleViewOnly:    1,
+	MemberRoleCanTransfer: 2,
+	MemberRoleOwner:       3,
+}
+
+// IsSupportedMemberRole returns true if the member role is supported
+func IsSupportedMemberRole(role string) bool {
+	_, ok := memberRoleRanks[role]
+	return ok
+}
+
+// MemberCan reports whether a member with role is allowed to do what requires the required role
+func MemberCan(role string, required string) bool {
+	rank, ok := memberRoleRanks[role]
+	return ok && rank >= memberRoleRanks[required]
+}
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemberCan(t *testing.T) {
+	require.True(t, MemberCan(MemberRoleOwner, MemberRoleOwner))
+	require.True(t, MemberCan(MemberRoleOwner, MemberRoleViewOnly))
+	require.True(t, MemberCan(MemberRoleCanTransfer, MemberRoleCanTransfer))
+	require.True(t, MemberCan(MemberRoleCanTransfer, MemberRoleViewOnly))
+	require.False(t, MemberCan(MemberRoleCanTransfer, MemberRoleOwner))
+	require.False(t, MemberCan(MemberRoleViewOnly, MemberRoleCanTransfer))
+	require.False(t, MemberCan("unknown", MemberRoleViewOnly))
+
+	require.True(t, IsSupportedMemberRole(MemberRoleViewOnly))
+	require.False(t, IsSupportedMemberRole("admin"))
+}
@@ -0,0 +1,60 @@
+package util
+
+import "time"
+
+// Constants for all supported scheduled transfer frequencies
+const (
+	FrequencyOnce    = "once"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Constants for the lifecycle of a scheduled transfer
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+// Constants for the outcome of one run of a scheduled transfer
+const (
+	// ScheduleRunStatusClaimed is a run whose occurrence was claimed and not posted yet
+	ScheduleRunStatusClaimed   = "claimed"
+	ScheduleRunStatusCompleted = "completed"
+	ScheduleRunStatusFailed    = "failed"
+	// ScheduleRunStatusPending is a run waiting for a banker's approval
+	ScheduleRunStatusPending = "pending"
+)
+
+// IsSupportedFrequency returns true if the frequency is supported
+func IsSupportedFrequency(frequency string) bool {
+	switch frequency {
+	case FrequencyOnce, FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+		return true
+	}
+	return false
+}
+
+// NextRunAt returns the occurrence following runAt for the given frequency.
+// Monthly runs keep the day of month of anchor, the schedule's first run, and are clamped to the
+// last day of shorter months without losing that day for the months after.
+func NextRunAt(frequency string, anchor, runAt time.Time) time.Time {
+	switch frequency {
+	case FrequencyDaily:
+		return runAt.AddDate(0, 0, 1)
+	case FrequencyWeekly:
+		return runAt.AddDate(0, 0, 7)
+	case FrequencyMonthly:
+		year, month, _ := runAt.Date()
+		day := anchor.In(runAt.Location()).Day()
+		lastDay := time.Date(year, month+2, 0, 0, 0, 0, 0, runAt.Location()).Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		hour, min, sec := runAt.Clock()
+		return time.Date(year, month+1, day, hour, min, sec, runAt.Nanosecond(), runAt.Location())
+	}
+	return runAt
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRunAt(t *testing.T) {
+	runAt := time.Date(2024, time.January, 31, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		frequency string
+		anchor    time.Time
+		runAt     time.Time
+		expected  time.Time
+	}{
+		{
+			name:      "Once",
+			anchor:    runAt,
+			frequency: FrequencyOnce,
+			runAt:     runAt,
+			expected:  runAt,
+		},
+		{
+			name:      "Daily",
+			anchor:    runAt,
+			frequency: FrequencyDaily,
+			runAt:     runAt,
+			expected:  time.Date(2024, time.February, 1, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "Weekly",
+			anchor:    runAt,
+			frequency: FrequencyWeekly,
+			runAt:     runAt,
+			expected:  time.Date(2024, time.February, 7, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "MonthlyClampedToLeapDay",
+			anchor:    runAt,
+			frequency: FrequencyMonthly,
+			runAt:     runAt,
+			expected:  time.Date(2024, time.February, 29, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "MonthlyAcrossYear",
+			anchor:    time.Date(2024, time.December, 15, 9, 30, 0, 0, time.UTC),
+			frequency: FrequencyMonthly,
+			runAt:     time.Date(2024, time.December, 15, 9, 30, 0, 0, time.UTC),
+			expected:  time.Date(2025, time.January, 15, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "MonthlyBackToAnchorDay",
+			anchor:    runAt,
+			frequency: FrequencyMonthly,
+			runAt:     time.Date(2024, time.February, 29, 9, 30, 0, 0, time.UTC),
+			expected:  time.Date(2024, time.March, 31, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "MonthlyClampedAfterAnchorDay",
+			anchor:    runAt,
+			frequency: FrequencyMonthly,
+			runAt:     time.Date(2024, time.March, 31, 9, 30, 0, 0, time.UTC),
+			expected:  time.Date(2024, time.April, 30, 9, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, NextRunAt(tc.frequency, tc.anchor, tc.runAt))
+		})
+	}
+}
+
+func TestIsSupportedFrequency(t *testing.T) {
+	require.True(t, IsSupportedFrequency(FrequencyMonthly))
+	require.False(t, IsSupportedFrequency("yearly"))
+}
@@ -0,0 +1,69 @@
+package util
+
+// Constants for the lifecycle of a transfer. A transfer is only written once it is posted, a failed one rolls back
+// and leaves no row, and one waiting for a banker is a transfer approval until it is approved.
+const (
+	// TransferStatusCompleted is a posted transfer, TransferTx and reversals write their transfers completed
+	TransferStatusCompleted = "completed"
+	// TransferStatusReversed is a completed transfer a banker reversed, its reversal links back to it
+	TransferStatusReversed = "reversed"
+)
+
+// Constants for the kinds of cash transaction posted by bankers
+const (
+	CashDeposit    = "deposit"
+	CashWithdrawal = "withdrawal"
+)
+
+// CashAccountOwner is the system user holding the bank's cash account of each currency
+const CashAccountOwner = "bank_cash"
+
+// Constants for the lifecycle of an account
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// Constants for the lifecycle of a hold on an account
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+// Constants for the lifecycle of a webhook delivery
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Constants for the lifecycle of a membership of an account
+const (
+	MemberStatusInvited = "invited"
+	MemberStatusActive  = "active"
+)
+
+// Constants for the lifecycle of a transfer waiting for a banker's approval
+const (
+	ApprovalStatusPending  = "pending"
+	ApprovalStatusApproved = "approved"
+	ApprovalStatusRejected = "rejected"
+	ApprovalStatusExpired  = "expired"
+)
+
+// Constants for the lifecycle of a case opened by a sanctions list match
+const (
+	SanctionsCaseStatusOpen      = "open"
+	SanctionsCaseStatusCleared   = "cleared"
+	SanctionsCaseStatusConfirmed = "confirmed"
+)
+
+// Constants for the actions screened against the sanctions list
+const (
+	SanctionsActionRegistration  = "registration"
+	SanctionsActionTransfer      = "transfer"
+	SanctionsActionProfileUpdate = "profile_update"
+)
@@ -0,0 +1,202 @@
+// Package webhook signs and sends the ledger events a user subscribed to.
+//
+// Every request is a POST of the event envelope with
+//
+//	X-Go2Bank-Signature: sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+//
+// where timestamp is the X-Go2Bank-Timestamp header in Unix seconds, so a receiver
+// can reject both tampered and replayed payloads.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Go2Bank-Event"
+	DeliveryHeader  = "X-Go2Bank-Delivery"
+	TimestampHeader = "X-Go2Bank-Timestamp"
+	SignatureHeader = "X-Go2Bank-Signature"
+
+	signaturePrefix = "sha256="
+	secretPrefix    = "whsec_"
+)
+
+const (
+	// MaxRetry is how many times a failed delivery is retried before it is given up
+	MaxRetry = 8
+	// MaxConsecutiveFailures is how many deliveries may be given up in a row before the subscription is disabled
+	MaxConsecutiveFailures = 5
+
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = time.Hour
+
+	// maxResponseSize bounds how much of a receiver's response is read before the connection is reused
+	maxResponseSize = 512
+)
+
+var ErrUnexpectedStatus = errors.New("unexpected response status")
+var ErrForbiddenAddress = errors.New("webhook address is not publicly routable")
+
+// sharedAddressSpace is the carrier-grade NAT range, which netip doesn't count as private
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddress reports whether addr is routable on the internet, so a webhook can't reach
+// loopback, private, link-local or unique-local services next to the worker
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// ValidateURL rejects webhook URLs that name a non-public host outright.
+// Host names are only resolved when dialing, where the sender checks the address again.
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddress(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// dialControl runs once the host name is resolved, right before connecting, so a name that
+// resolves or rebinds to an internal address is refused as well
+func dialControl(network, address string, c syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// NewSecret generates the signing secret of a new subscription
+func NewSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return secretPrefix + hex.EncodeToString(key), nil
+}
+
+// Sign returns the signature header value of a body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature in constant time, receivers written in Go can use it as is
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// RetryDelay backs off exponentially from 30s, capped at an hour
+func RetryDelay(retried int) time.Duration {
+	if retried >= 7 {
+		return maxRetryDelay
+	}
+	return min(baseRetryDelay<<retried, maxRetryDelay)
+}
+
+// Request is one delivery of an event to a subscription
+type Request struct {
+	URL        string
+	Secret     string
+	DeliveryID int64
+	EventType  string
+	Body       []byte
+}
+
+// Sender delivers a request and returns the response status, any status but 2xx is an error
+type Sender interface {
+	Send(ctx context.Context, req Request) (int, error)
+}
+
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender only connects to public addresses and never through a proxy
+func NewHTTPSender(timeout time.Duration) Sender {
+	return newHTTPSender(timeout, dialControl)
+}
+
+func newHTTPSender(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) Sender {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: control,
+	}
+
+	return &HTTPSender{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				ForceAttemptHTTP2:   true,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+			// a redirect could point the signed payload anywhere
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (sender *HTTPSender) Send(ctx context.Context, req Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "Go2Bank-Webhook/1.0")
+	httpReq.Header.Set(EventHeader, req.EventType)
+	httpReq.Header.Set(DeliveryHeader, strconv.FormatInt(req.DeliveryID, 10))
+	httpReq.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(SignatureHeader, Sign(req.Secret, timestamp, req.Body))
+
+	resp, err := sender.client.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// drain the body so the connection can be reused, it is never kept since the subscriber can read the delivery log
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("%w %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	body := []byte(`{"id":1}`)
+	timestamp := time.Now().Unix()
+
+	signature := Sign(secret, timestamp, body)
+	require.True(t, Verify(secret, timestamp, body, signature))
+
+	require.False(t, Verify(secret, timestamp+1, body, signature))
+	require.False(t, Verify(secret, timestamp, []byte(`{"id":2}`), signature))
+	require.False(t, Verify(util.RandomString(32), timestamp, body, signature))
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, 30*time.Second, RetryDelay(0))
+	require.Equal(t, time.Minute, RetryDelay(1))
+	require.Equal(t, 32*time.Minute, RetryDelay(6))
+	require.Equal(t, time.Hour, RetryDelay(7))
+	require.Equal(t, time.Hour, RetryDelay(100))
+}
+
+func TestHTTPSender(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	body := []byte(`{"id":1,"type":"transfer.created"}`)
+
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		got, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, body, got)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		require.NoError(t, err)
+		if !Verify(secret, timestamp, got, r.Header.Get(SignatureHeader)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// httptest listens on loopback, which NewHTTPSender refuses
+	sender := newHTTPSender(time.Second, nil)
+	req := Request{
+		URL:        server.URL,
+		Secret:     secret,
+		DeliveryID: 7,
+		EventType:  "transfer.created",
+		Body:       body,
+	}
+
+	status, err := sender.Send(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, status)
+	require.Equal(t, "transfer.created", received.Header.Get(EventHeader))
+	require.Equal(t, "7", received.Header.Get(DeliveryHeader))
+
+	req.Secret = "whsec_wrong"
+	status, err = sender.Send(context.Background(), req)
+	require.ErrorIs(t, err, ErrUnexpectedStatus)
+	require.NotContains(t, err.Error(), "bad signature")
+	require.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestHTTPSenderRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://example.com", http.StatusFound)
+	}))
+	defer server.Close()
+
+	status, err := newHTTPSender(time.Second, nil).Send(context.Background(), Request{URL: server.URL, Body: []byte(`{}`)})
+	require.ErrorIs(t, err, ErrUnexpectedStatus)
+	require.Equal(t, http.StatusFound, status)
+}
+
+func TestHTTPSenderUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	status, err := newHTTPSender(time.Second, nil).Send(context.Background(), Request{URL: server.URL, Body: []byte(`{}`)})
+	require.Error(t, err)
+	require.Zero(t, status)
+}
+
+func TestHTTPSenderForbiddenAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request must not reach a loopback address")
+	}))
+	defer server.Close()
+
+	status, err := NewHTTPSender(time.Second).Send(context.Background(), Request{URL: server.URL, Body: []byte(`{}`)})
+	require.ErrorIs(t, err, ErrForbiddenAddress)
+	require.Zero(t, status)
+}
+
+func TestValidateURL(t *testing.T) {
+	testCases := []struct {
+		url       string
+		forbidden bool
+	}{
+		{url: "https://hooks.example.com/go2bank", forbidden: false},
+		{url: "http://93.184.216.34:8080/hooks", forbidden: false},
+		{url: "http://localhost:8080/hooks", forbidden: true},
+		{url: "http://api.localhost/hooks", forbidden: true},
+		{url: "http://127.0.0.1/hooks", forbidden: true},
+		{url: "http://169.254.169.254/latest/meta-data", forbidden: true},
+		{url: "http://10.0.0.5/hooks", forbidden: true},
+		{url: "http://172.16.3.4/hooks", forbidden: true},
+		{url: "http://192.168.1.1/hooks", forbidden: true},
+		{url: "http://100.64.0.1/hooks", forbidden: true},
+		{url: "http://0.0.0.0/hooks", forbidden: true},
+		{url: "http://[::1]/hooks", forbidden: true},
+		{url: "http://[fd00::1]/hooks", forbidden: true},
+		{url: "http://[fe80::1]/hooks", forbidden: true},
+		{url: "http://[::ffff:127.0.0.1]/hooks", forbidden: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			err := ValidateURL(tc.url)
+			if tc.forbidden {
+				require.ErrorIs(t, err, ErrForbiddenAddress)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
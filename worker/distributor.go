@@ -12,6 +12,25 @@ type TaskDistributor interface {
 		payload *PayloadSendVerifyEmail,
 		opts ...asynq.Option,
 	) error
+	DistributeTaskSyncExchangeRates(
+		ctx context.Context,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendStatement(
+		ctx context.Context,
+		payload *PayloadSendStatement,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskDeliverWebhook(
+		ctx context.Context,
+		payload *PayloadDeliverWebhook,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendTransferApprovalNotice(
+		ctx context.Context,
+		payload *PayloadSendTransferApprovalNotice,
+		opts ...asynq.Option,
+	) error
 }
 
 type RedisTaskDistributor struct {
@@ -2,11 +2,15 @@ package worker
 
 import (
 	"context"
+	"time"
 
 	db "github.com/RobertChienShiba/simplebank/db/sqlc"
 	"github.com/RobertChienShiba/simplebank/mail"
+	"github.com/RobertChienShiba/simplebank/outbox"
+	"github.com/RobertChienShiba/simplebank/posting"
 	rds "github.com/RobertChienShiba/simplebank/redis"
 	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/RobertChienShiba/simplebank/webhook"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
 )
@@ -16,21 +20,48 @@ const (
 	QueueDefault  = "default"
 )
 
+// webhookTimeout bounds a single delivery attempt
+const webhookTimeout = 10 * time.Second
+
 type TaskProcessor interface {
 	Start() error
 	Shutdown()
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskRunScheduledTransfers(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSyncExchangeRates(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendStatement(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendMonthlyStatements(ctx context.Context, task *asynq.Task) error
+	ProcessTaskExpireHolds(ctx context.Context, task *asynq.Task) error
+	ProcessTaskRelayOutbox(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error
+	ProcessTaskReconcileLedger(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendTransferApprovalNotice(ctx context.Context, task *asynq.Task) error
+	ProcessTaskExpireTransferApprovals(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSweepIdempotencyKeys(ctx context.Context, task *asynq.Task) error
 }
 
 type RedisTaskProcessor struct {
-	config   util.Config
-	server   *asynq.Server
-	store    db.Store
-	otpStore rds.Store
-	mailer   mail.EmailSender
+	config      util.Config
+	server      *asynq.Server
+	store       db.Store
+	otpStore    rds.Store
+	mailer      mail.EmailSender
+	publisher   outbox.Publisher
+	webhooks    webhook.Sender
+	distributor TaskDistributor
+	// controls screen scheduled runs and decide whether they need approval, like transfers sent through the API
+	controls *posting.Controls
 }
 
-func NewRedisTaskProcessor(config util.Config, redisOpt asynq.RedisClientOpt, store db.Store, otpStore rds.Store, mailer mail.EmailSender) TaskProcessor {
+func NewRedisTaskProcessor(
+	config util.Config,
+	redisOpt asynq.RedisClientOpt,
+	store db.Store,
+	otpStore rds.Store,
+	mailer mail.EmailSender,
+	publisher outbox.Publisher,
+	controls *posting.Controls,
+) TaskProcessor {
 	server := asynq.NewServer(
 		redisOpt,
 		asynq.Config{
@@ -38,6 +69,13 @@ func NewRedisTaskProcessor(config util.Config, redisOpt asynq.RedisClientOpt, st
 				QueueCritical: 10,
 				QueueDefault:  5,
 			},
+			// Webhook receivers get more room to recover than the default backoff gives them
+			RetryDelayFunc: func(n int, err error, task *asynq.Task) time.Duration {
+				if task.Type() == TaskDeliverWebhook {
+					return webhook.RetryDelay(n)
+				}
+				return asynq.DefaultRetryDelayFunc(n, err, task)
+			},
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
 				log.Error().Err(err).Str("type", task.Type()).
 					Bytes("payload", task.Payload()).Msg("process task failed")
@@ -46,11 +84,16 @@ func NewRedisTaskProcessor(config util.Config, redisOpt asynq.RedisClientOpt, st
 	)
 
 	return &RedisTaskProcessor{
-		config:   config,
-		server:   server,
-		store:    store,
-		otpStore: otpStore,
-		mailer:   mailer,
+		config:    config,
+		server:    server,
+		store:     store,
+		otpStore:  otpStore,
+		mailer:    mailer,
+		publisher: publisher,
+		webhooks:  webhook.NewHTTPSender(webhookTimeout),
+		// Periodic sweeps fan out follow-up tasks through the same Redis
+		distributor: NewRedisTaskDistributor(redisOpt),
+		controls:    controls,
 	}
 }
 
@@ -58,6 +101,17 @@ func (processor *RedisTaskProcessor) Start() error {
 	mux := asynq.NewServeMux()
 
 	mux.HandleFunc(TaskSendVerifyEmail, processor.ProcessTaskSendVerifyEmail)
+	mux.HandleFunc(TaskRunScheduledTransfers, processor.ProcessTaskRunScheduledTransfers)
+	mux.HandleFunc(TaskSyncExchangeRates, processor.ProcessTaskSyncExchangeRates)
+	mux.HandleFunc(TaskSendStatement, processor.ProcessTaskSendStatement)
+	mux.HandleFunc(TaskSendMonthlyStatements, processor.ProcessTaskSendMonthlyStatements)
+	mux.HandleFunc(TaskExpireHolds, processor.ProcessTaskExpireHolds)
+	mux.HandleFunc(TaskRelayOutbox, processor.ProcessTaskRelayOutbox)
+	mux.HandleFunc(TaskDeliverWebhook, processor.ProcessTaskDeliverWebhook)
+	mux.HandleFunc(TaskReconcileLedger, processor.ProcessTaskReconcileLedger)
+	mux.HandleFunc(TaskSendTransferApprovalNotice, processor.ProcessTaskSendTransferApprovalNotice)
+	mux.HandleFunc(TaskExpireTransferApprovals, processor.ProcessTaskExpireTransferApprovals)
+	mux.HandleFunc(TaskSweepIdempotencyKeys, processor.ProcessTaskSweepIdempotencyKeys)
 
 	return processor.server.Start(mux)
 }
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+type TaskScheduler interface {
+	Start() error
+	Shutdown()
+}
+
+type RedisTaskScheduler struct {
+	config    util.Config
+	scheduler *asynq.Scheduler
+}
+
+func NewRedisTaskScheduler(config util.Config, redisOpt asynq.RedisClientOpt) TaskScheduler {
+	scheduler := asynq.NewScheduler(
+		redisOpt,
+		&asynq.SchedulerOpts{
+			PostEnqueueFunc: func(info *asynq.TaskInfo, err error) {
+				if err != nil {
+					log.Error().Err(err).Msg("failed to enqueue periodic task")
+				}
+			},
+		},
+	)
+
+	return &RedisTaskScheduler{
+		config:    config,
+		scheduler: scheduler,
+	}
+}
+
+func (scheduler *RedisTaskScheduler) Start() error {
+	// Each occurrence records its own outcome, so a failed sweep is simply picked up by the next tick
+	err := scheduler.register(TaskRunScheduledTransfers, scheduler.config.ScheduledTransferInterval, asynq.MaxRetry(0))
+	if err != nil {
+		return err
+	}
+
+	err = scheduler.register(TaskSyncExchangeRates, scheduler.config.RatesSyncInterval, asynq.MaxRetry(3))
+	if err != nil {
+		return err
+	}
+
+	// Expired holds already stop reserving funds, this only records their final status
+	err = scheduler.register(TaskExpireHolds, scheduler.config.HoldExpiryInterval, asynq.MaxRetry(0))
+	if err != nil {
+		return err
+	}
+
+	// Expired approvals can't be approved anymore, this records their final status and notifies the requesters
+	err = scheduler.register(TaskExpireTransferApprovals, scheduler.config.TransferApprovalExpiryInterval, asynq.MaxRetry(0))
+	if err != nil {
+		return err
+	}
+
+	// Expired keys are already ignored by the middleware, this only keeps the table small
+	err = scheduler.register(TaskSweepIdempotencyKeys, scheduler.config.IdempotencySweepInterval, asynq.MaxRetry(0))
+	if err != nil {
+		return err
+	}
+
+	// Unpublished events stay pending, so the next tick relays them again
+	err = scheduler.register(TaskRelayOutbox, scheduler.config.OutboxRelayInterval, asynq.MaxRetry(0))
+	if err != nil {
+		return err
+	}
+
+	// Statements are cut by calendar month, so this one runs on a cron spec instead of an interval
+	err = scheduler.registerCron(TaskSendMonthlyStatements, scheduler.config.StatementSchedule, time.Hour, asynq.MaxRetry(3))
+	if err != nil {
+		return err
+	}
+
+	// A discrepancy stays in the ledger until someone fixes it, so the next run reports it again
+	err = scheduler.registerCron(TaskReconcileLedger, scheduler.config.ReconcileSchedule, time.Hour, asynq.MaxRetry(1))
+	if err != nil {
+		return err
+	}
+
+	return scheduler.scheduler.Start()
+}
+
+func (scheduler *RedisTaskScheduler) register(taskType string, interval time.Duration, opts ...asynq.Option) error {
+	return scheduler.registerCron(taskType, fmt.Sprintf("@every %s", interval), interval, opts...)
+}
+
+// registerCron enqueues taskType on the cron spec, unique bounds how long a duplicate enqueue is rejected
+func (scheduler *RedisTaskScheduler) registerCron(taskType string, spec string, unique time.Duration, opts ...asynq.Option) error {
+	opts = append(opts, asynq.Queue(QueueDefault), asynq.Unique(unique))
+
+	_, err := scheduler.scheduler.Register(spec, asynq.NewTask(taskType, nil), opts...)
+	if err != nil {
+		return fmt.Errorf("failed to register periodic task %s: %w", taskType, err)
+	}
+	return nil
+}
+
+func (scheduler *RedisTaskScheduler) Shutdown() {
+	scheduler.scheduler.Shutdown()
+}
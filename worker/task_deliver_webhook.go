@@ -0,0 +1,233 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/outbox"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/RobertChienShiba/simplebank/webhook"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskDeliverWebhook = "task:deliver_webhook"
+
+type PayloadDeliverWebhook struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskDeliverWebhook(
+	ctx context.Context,
+	payload *PayloadDeliverWebhook,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskDeliverWebhook, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+// eventAccounts is the part of every event payload naming the accounts it touched
+type eventAccounts struct {
+	AccountID     int64 `json:"account_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+}
+
+// eventAccountIDs returns the accounts whose owners are notified of the event
+func eventAccountIDs(event db.OutboxEvent) ([]int64, error) {
+	if event.AggregateType == "account" {
+		return []int64{event.AggregateID}, nil
+	}
+
+	var accounts eventAccounts
+	if err := json.Unmarshal(event.Payload, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event %d: %w", event.ID, err)
+	}
+
+	ids := make([]int64, 0, 3)
+	for _, id := range []int64{accounts.AccountID, accounts.FromAccountID, accounts.ToAccountID} {
+		if id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// publishEvent hands a relayed event to the publisher and records its webhook deliveries.
+// It returns the deliveries still pending, they are queued once the relay marked the event published.
+func (processor *RedisTaskProcessor) publishEvent(ctx context.Context, event db.OutboxEvent) ([]db.WebhookDelivery, error) {
+	if err := processor.publisher.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	accountIDs, err := eventAccountIDs(event)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := processor.store.ListWebhookSubscriptionsForEvent(ctx, db.ListWebhookSubscriptionsForEventParams{
+		EventType:  event.EventType,
+		AccountIds: accountIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	pending := make([]db.WebhookDelivery, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		// a republished event gets back the delivery it already has
+		delivery, err := processor.store.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.EventType,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+		}
+		if delivery.Status == util.DeliveryStatusPending {
+			pending = append(pending, delivery)
+		}
+	}
+
+	return pending, nil
+}
+
+// enqueueWebhookDeliveries queues one task per delivery
+func (processor *RedisTaskProcessor) enqueueWebhookDeliveries(ctx context.Context, deliveries []db.WebhookDelivery) error {
+	for _, delivery := range deliveries {
+		err := EnqueueWebhookDelivery(ctx, processor.distributor, delivery, asynq.Queue(QueueDefault))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnqueueWebhookDelivery queues the task of a delivery. Its task id keeps a delivery whose task is still
+// queued or retrying from being queued twice, and changes with every redelivery so a settled task doesn't block it.
+func EnqueueWebhookDelivery(ctx context.Context, distributor TaskDistributor, delivery db.WebhookDelivery, opts ...asynq.Option) error {
+	opts = append(opts,
+		asynq.MaxRetry(webhook.MaxRetry),
+		asynq.TaskID(fmt.Sprintf("webhook-delivery:%d:%d", delivery.ID, delivery.Redeliveries)),
+	)
+
+	err := distributor.DistributeTaskDeliverWebhook(ctx, &PayloadDeliverWebhook{DeliveryID: delivery.ID}, opts...)
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return err
+	}
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadDeliverWebhook
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	delivery, err := processor.store.GetWebhookDelivery(ctx, payload.DeliveryID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return fmt.Errorf("webhook delivery doesn't exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	// a duplicate task of a delivery that is already settled
+	if delivery.Status != util.DeliveryStatusPending {
+		return nil
+	}
+
+	subscription, err := processor.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if !subscription.IsActive {
+		_, err = processor.store.RecordWebhookDeliveryAttempt(ctx, db.RecordWebhookDeliveryAttemptParams{
+			ID:        delivery.ID,
+			Status:    util.DeliveryStatusFailed,
+			LastError: pgtype.Text{String: "subscription is disabled", Valid: true},
+		})
+		return err
+	}
+
+	event, err := processor.store.GetOutboxEvent(ctx, delivery.EventID)
+	if err != nil {
+		return fmt.Errorf("failed to get outbox event: %w", err)
+	}
+
+	body, err := json.Marshal(outbox.NewEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", asynq.SkipRetry)
+	}
+
+	status, sendErr := processor.webhooks.Send(ctx, webhook.Request{
+		URL:        subscription.Url,
+		Secret:     subscription.Secret,
+		DeliveryID: delivery.ID,
+		EventType:  event.EventType,
+		Body:       body,
+	})
+
+	arg := db.RecordWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         util.DeliveryStatusSucceeded,
+		ResponseStatus: pgtype.Int4{Int32: int32(status), Valid: status != 0},
+	}
+	if sendErr == nil {
+		if _, err = processor.store.RecordWebhookDeliveryAttempt(ctx, arg); err != nil {
+			return fmt.Errorf("failed to record webhook delivery: %w", err)
+		}
+		if err = processor.store.ResetWebhookSubscriptionFailures(ctx, subscription.ID); err != nil {
+			return fmt.Errorf("failed to reset webhook failures: %w", err)
+		}
+
+		log.Info().Str("type", task.Type()).Int64("delivery_id", delivery.ID).
+			Int("status", status).Msg("processed task")
+		return nil
+	}
+
+	// the delivery stays pending until asynq gives up retrying it
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	final := retried >= maxRetry
+
+	arg.Status = util.DeliveryStatusPending
+	if final {
+		arg.Status = util.DeliveryStatusFailed
+	}
+	arg.LastError = pgtype.Text{String: sendErr.Error(), Valid: true}
+	if _, err = processor.store.RecordWebhookDeliveryAttempt(ctx, arg); err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	if final {
+		subscription, err = processor.store.RecordWebhookSubscriptionFailure(ctx, db.RecordWebhookSubscriptionFailureParams{
+			ID:          subscription.ID,
+			MaxFailures: webhook.MaxConsecutiveFailures,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record webhook failure: %w", err)
+		}
+		if !subscription.IsActive {
+			log.Warn().Int64("subscription_id", subscription.ID).Str("owner", subscription.Owner).
+				Msg("disabled webhook subscription after repeated failures")
+		}
+	}
+
+	return fmt.Errorf("failed to deliver webhook: %w", sendErr)
+}
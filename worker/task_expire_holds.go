@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskExpireHolds = "task:expire_holds"
+
+func (processor *RedisTaskProcessor) ProcessTaskExpireHolds(ctx context.Context, task *asynq.Task) error {
+	expired, err := processor.store.ExpireHolds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to expire holds: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Int64("expired", expired).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskExpireTransferApprovals = "task:expire_transfer_approvals"
+
+// ProcessTaskExpireTransferApprovals expires the approvals nobody reviewed in time, fails the scheduled runs
+// waiting for them and notifies their requesters
+func (processor *RedisTaskProcessor) ProcessTaskExpireTransferApprovals(ctx context.Context, task *asynq.Task) error {
+	approvals, err := processor.store.ExpireTransferApprovalsTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to expire transfer approvals: %w", err)
+	}
+
+	for _, approval := range approvals {
+		opts := []asynq.Option{
+			asynq.MaxRetry(3),
+			asynq.Queue(QueueDefault),
+			asynq.TaskID(fmt.Sprintf("approval-notice:%d", approval.ID)),
+		}
+
+		payload := &PayloadSendTransferApprovalNotice{ApprovalID: approval.ID}
+		err = processor.distributor.DistributeTaskSendTransferApprovalNotice(ctx, payload, opts...)
+		if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+			// The approval is already expired, so a lost notice is logged rather than retried by the next sweep
+			log.Error().Err(err).Int64("approval_id", approval.ID).Msg("failed to enqueue transfer approval notice")
+		}
+	}
+
+	log.Info().Str("type", task.Type()).Int("expired", len(approvals)).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RobertChienShiba/simplebank/reconcile"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskReconcileLedger = "task:reconcile_ledger"
+
+func (processor *RedisTaskProcessor) ProcessTaskReconcileLedger(ctx context.Context, task *asynq.Task) error {
+	report, err := reconcile.Run(ctx, processor.store)
+	if err != nil {
+		return err
+	}
+
+	if report.Clean() {
+		log.Info().Str("type", task.Type()).Msg("processed task, ledger is balanced")
+		return nil
+	}
+
+	log.Error().Int("balance_drifts", len(report.BalanceDrifts)).
+		Int("transfer_mismatches", len(report.TransferMismatches)).Msg("ledger reconciliation found discrepancies")
+
+	bankers, err := processor.store.ListUsersByRole(ctx, util.BankerRole)
+	if err != nil {
+		return fmt.Errorf("failed to list bankers: %w", err)
+	}
+	if len(bankers) == 0 {
+		log.Warn().Str("type", task.Type()).Msg("no banker to alert about the ledger discrepancies")
+		return nil
+	}
+
+	to := make([]string, len(bankers))
+	for i, banker := range bankers {
+		to[i] = banker.Email
+	}
+
+	subject := fmt.Sprintf("Go2Bank Ledger Reconciliation: %s", report.Summary())
+	content := fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html lang="en">
+		<head>
+			<meta charset="UTF-8">
+			<meta name="viewport" content="width=device-width, initial-scale=1.0">
+			<title>Ledger Reconciliation</title>
+		</head>
+		<body>
+			%s
+			<p>Amounts are in minor units. Run <code>main reconcile</code> to check the ledger again.</p>
+		</body>
+		</html>`,
+		report.HTML(),
+	)
+
+	err = processor.mailer.SendEmail(subject, content, to, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send reconciliation alert: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Int("bankers", len(to)).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskRelayOutbox = "task:relay_outbox"
+
+	relayOutboxBatchSize = 100
+
+	// webhookRequeueAfter is how long a delivery stays pending untouched before the relay queues it again,
+	// well beyond the gap between recording a delivery and queueing it
+	webhookRequeueAfter = 10 * time.Minute
+)
+
+func (processor *RedisTaskProcessor) ProcessTaskRelayOutbox(ctx context.Context, task *asynq.Task) error {
+	total := 0
+	for {
+		var deliveries []db.WebhookDelivery
+		result, err := processor.store.RelayOutbox(ctx, db.RelayOutboxParams{
+			Limit:       relayOutboxBatchSize,
+			Lease:       processor.config.OutboxClaimLease,
+			MaxAttempts: processor.config.OutboxMaxAttempts,
+			Publish: func(ctx context.Context, event db.OutboxEvent) error {
+				pending, err := processor.publishEvent(ctx, event)
+				if err != nil {
+					return err
+				}
+				deliveries = append(deliveries, pending...)
+				return nil
+			},
+		})
+		if err != nil {
+			// the batch is relayed again, the deliveries are queued once its events are marked published
+			return fmt.Errorf("failed to relay outbox events: %w", err)
+		}
+		total += result.Published
+
+		if err := processor.enqueueWebhookDeliveries(ctx, deliveries); err != nil {
+			return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+		}
+
+		if result.Failed != nil {
+			log.Error().Str("type", task.Type()).Int64("event_id", result.Failed.ID).
+				Int32("attempts", result.Failed.Attempts).Bool("dead_lettered", result.Failed.DeadLetteredAt.Valid).
+				Msg("failed to publish outbox event")
+			break
+		}
+		// a short batch means the outbox is drained
+		if result.Claimed < relayOutboxBatchSize {
+			break
+		}
+	}
+
+	// Deliveries whose task was lost after their event was published are queued again,
+	// the task id leaves the ones still queued or retrying alone
+	stale, err := processor.store.ListStaleWebhookDeliveries(ctx, db.ListStaleWebhookDeliveriesParams{
+		UpdatedAt: time.Now().Add(-webhookRequeueAfter),
+		Limit:     relayOutboxBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list stale webhook deliveries: %w", err)
+	}
+	if err := processor.enqueueWebhookDeliveries(ctx, stale); err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Int("published", total).Int("requeued", len(stale)).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,288 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"math/big"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/posting"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskRunScheduledTransfers = "task:run_scheduled_transfers"
+
+// scheduledTransferBatchSize bounds how many due instructions a single sweep picks up
+const scheduledTransferBatchSize = 100
+
+// scheduledRunResumeAfter is how long a run stays claimed before a sweep takes it over, well beyond the time a run takes
+const scheduledRunResumeAfter = 10 * time.Minute
+
+func (processor *RedisTaskProcessor) ProcessTaskRunScheduledTransfers(ctx context.Context, task *asynq.Task) error {
+	now := time.Now()
+
+	// Runs left claimed by a worker that stopped are resumed, their transfer was never posted
+	staleRuns, err := processor.store.ListStaleScheduledTransferRuns(ctx, db.ListStaleScheduledTransferRunsParams{
+		CreatedAt: now.Add(-scheduledRunResumeAfter),
+		Limit:     scheduledTransferBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list stale scheduled transfer runs: %w", err)
+	}
+
+	for _, run := range staleRuns {
+		processor.resumeScheduledTransferRun(ctx, run)
+	}
+
+	scheduledTransfers, err := processor.store.ListDueScheduledTransfers(ctx, db.ListDueScheduledTransfersParams{
+		NextRunAt: now,
+		Limit:     scheduledTransferBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list due scheduled transfers: %w", err)
+	}
+
+	for _, scheduledTransfer := range scheduledTransfers {
+		processor.runScheduledTransfer(ctx, scheduledTransfer)
+	}
+
+	log.Info().Str("type", task.Type()).Int("due", len(scheduledTransfers)).Int("resumed", len(staleRuns)).Msg("processed task")
+	return nil
+}
+
+// runScheduledTransfer claims one occurrence of the instruction, executes it and records the outcome.
+// The claim advances next_run_at only if nobody else did it first, so concurrent sweeps never run an occurrence twice,
+// and it records the occurrence as a claimed run in the same transaction.
+func (processor *RedisTaskProcessor) runScheduledTransfer(ctx context.Context, scheduledTransfer db.ScheduledTransfer) {
+	scheduledFor := scheduledTransfer.NextRunAt
+	nextRunAt := util.NextRunAt(scheduledTransfer.Frequency, scheduledTransfer.AnchorAt, scheduledFor)
+
+	status := util.ScheduleStatusActive
+	if scheduledTransfer.Frequency == util.FrequencyOnce ||
+		(scheduledTransfer.RemainingRuns.Valid && scheduledTransfer.RemainingRuns.Int32 <= 1) ||
+		(scheduledTransfer.EndAt.Valid && nextRunAt.After(scheduledTransfer.EndAt.Time)) {
+		status = util.ScheduleStatusCompleted
+	}
+
+	run, err := processor.store.ClaimScheduledTransferTx(ctx, db.ClaimScheduledTransferParams{
+		NewNextRunAt: nextRunAt,
+		Status:       status,
+		ID:           scheduledTransfer.ID,
+		NextRunAt:    scheduledFor,
+	})
+	if err != nil {
+		if !errors.Is(err, db.ErrRecordNotFound) {
+			log.Error().Err(err).Int64("scheduled_transfer_id", scheduledTransfer.ID).Msg("failed to claim scheduled transfer")
+		}
+		return
+	}
+
+	processor.finishScheduledTransferRun(ctx, scheduledTransfer, run)
+}
+
+// resumeScheduledTransferRun executes a run its worker claimed and never finished
+func (processor *RedisTaskProcessor) resumeScheduledTransferRun(ctx context.Context, run db.ScheduledTransferRun) {
+	scheduledTransfer, err := processor.store.GetScheduledTransfer(ctx, run.ScheduledTransferID)
+	if err != nil {
+		log.Error().Err(err).Int64("scheduled_transfer_id", run.ScheduledTransferID).Msg("failed to get scheduled transfer")
+		return
+	}
+
+	processor.finishScheduledTransferRun(ctx, scheduledTransfer, run)
+}
+
+// finishScheduledTransferRun executes a claimed run and records its outcome, a posted run is completed with its transfer
+func (processor *RedisTaskProcessor) finishScheduledTransferRun(
+	ctx context.Context,
+	scheduledTransfer db.ScheduledTransfer,
+	run db.ScheduledTransferRun,
+) {
+	approval, runErr := processor.executeScheduledTransfer(ctx, scheduledTransfer, run.ID)
+	if runErr == nil && approval == nil {
+		return
+	}
+	if errors.Is(runErr, db.ErrScheduledRunFinished) {
+		// Another sweep resumed the run and finished it first
+		return
+	}
+
+	arg := db.FinishScheduledTransferRunParams{ID: run.ID}
+	if runErr != nil {
+		arg.Status = util.ScheduleRunStatusFailed
+		arg.Error = pgtype.Text{String: runErr.Error(), Valid: true}
+	} else {
+		// The run is settled by the banker reviewing the approval
+		arg.Status = util.ScheduleRunStatusPending
+		arg.ApprovalID = pgtype.Int8{Int64: approval.ID, Valid: true}
+	}
+
+	_, err := processor.store.FinishScheduledTransferRun(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return
+		}
+		log.Error().Err(err).Int64("scheduled_transfer_id", scheduledTransfer.ID).Msg("failed to record scheduled transfer run")
+	}
+
+	if runErr != nil {
+		log.Error().Err(runErr).Int64("scheduled_transfer_id", scheduledTransfer.ID).Msg("scheduled transfer failed")
+
+		err = processor.sendScheduledTransferFailedEmail(ctx, scheduledTransfer, run.ScheduledFor, runErr)
+		if err != nil {
+			log.Error().Err(err).Int64("scheduled_transfer_id", scheduledTransfer.ID).Msg("failed to notify owner")
+		}
+	}
+}
+
+// executeScheduledTransfer posts one occurrence of the instruction with its run, or returns the approval it waits for instead
+func (processor *RedisTaskProcessor) executeScheduledTransfer(
+	ctx context.Context,
+	scheduledTransfer db.ScheduledTransfer,
+	runID int64,
+) (*db.TransferApproval, error) {
+	// A resumed run of a schedule cancelled in the meantime isn't posted
+	if scheduledTransfer.Status == util.ScheduleStatusCancelled || scheduledTransfer.Status == util.ScheduleStatusPaused {
+		return nil, fmt.Errorf("scheduled transfer is %s", scheduledTransfer.Status)
+	}
+
+	fromAccount, err := processor.store.GetAccount(ctx, scheduledTransfer.FromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get from account: %w", err)
+	}
+
+	// The schedule stops running once its owner may no longer move money out of the account
+	member, err := processor.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: fromAccount.ID,
+		Username:  scheduledTransfer.Owner,
+	})
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get account member: %w", err)
+	}
+	if err != nil || member.Status != util.MemberStatusActive || !util.MemberCan(member.Role, util.MemberRoleCanTransfer) {
+		return nil, errors.New("from account doesn't belong to the schedule owner")
+	}
+
+	if fromAccount.Currency != scheduledTransfer.Currency {
+		return nil, fmt.Errorf("account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, scheduledTransfer.Currency)
+	}
+
+	toAccount, err := processor.store.GetAccount(ctx, scheduledTransfer.ToAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get to account: %w", err)
+	}
+
+	fromExchangeRate, err := processor.exchangeRate(ctx, fromAccount.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	toExchangeRate, err := processor.exchangeRate(ctx, toAccount.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	roundingMode, err := money.ParseRoundingMode(processor.config.RoundingMode)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion, err := money.Convert(
+		money.New(scheduledTransfer.Amount, fromAccount.Currency),
+		toAccount.Currency,
+		fromExchangeRate,
+		toExchangeRate,
+		roundingMode,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert amount: %w", err)
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		FromAmount:    conversion.Source.Amount,
+		ToAmount:      conversion.Target.Amount,
+		ExchangeRate:  money.NumericFromRat(conversion.Rate),
+		Username:      scheduledTransfer.Owner,
+	}
+	if conversion.Remainder.Sign() != 0 {
+		arg.RoundingRemainder = money.NumericFromRat(conversion.Remainder)
+	}
+
+	approval, err := processor.controls.Check(ctx, posting.Transfer{
+		Username:    scheduledTransfer.Owner,
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		Params:      arg,
+	})
+	if err != nil || approval != nil {
+		return approval, err
+	}
+
+	_, err = processor.store.PostScheduledTransferRunTx(ctx, db.PostScheduledTransferRunTxParams{
+		RunID:    runID,
+		Transfer: arg,
+	})
+	return nil, err
+}
+
+func (processor *RedisTaskProcessor) exchangeRate(ctx context.Context, currency string) (*big.Rat, error) {
+	rate, err := processor.store.GetExchangeRate(ctx, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	return money.RatFromNumeric(rate)
+}
+
+func (processor *RedisTaskProcessor) sendScheduledTransferFailedEmail(
+	ctx context.Context,
+	scheduledTransfer db.ScheduledTransfer,
+	scheduledFor time.Time,
+	runErr error,
+) error {
+	user, err := processor.store.GetUser(ctx, scheduledTransfer.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	subject := "Scheduled Transfer Failed"
+	content := fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html lang="en">
+		<head>
+			<meta charset="UTF-8">
+			<meta name="viewport" content="width=device-width, initial-scale=1.0">
+			<title>Scheduled Transfer Failed</title>
+		</head>
+		<body>
+			<p>Hello %s,</p>
+			<p>Your scheduled transfer <b>#%d</b> of <b>%d %s</b> from account <b>%d</b> to account <b>%d</b> due at <b>%s</b> could not be completed.</p>
+			<p>Reason: %s</p>
+		</body>
+		</html>`,
+		html.EscapeString(user.FullName),
+		scheduledTransfer.ID,
+		scheduledTransfer.Amount,
+		scheduledTransfer.Currency,
+		scheduledTransfer.FromAccountID,
+		scheduledTransfer.ToAccountID,
+		scheduledFor.Format(time.RFC1123),
+		html.EscapeString(runErr.Error()),
+	)
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send scheduled transfer failed email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,343 @@
+package worker
+
+import (
+	"context"
+	"html"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/fraud"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/posting"
+	"github.com/RobertChienShiba/simplebank/sanctions"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+type stubScreener struct {
+	decision fraud.Decision
+}
+
+func (screener stubScreener) Screen(ctx context.Context, transfer fraud.Transfer) (fraud.Screening, error) {
+	return fraud.Screening{Decision: screener.decision}, nil
+}
+
+type stubMailer struct {
+	sent []string
+}
+
+func (mailer *stubMailer) SendEmail(subject string, content string, to []string, attachFiles []string) error {
+	mailer.sent = append(mailer.sent, content)
+	return nil
+}
+
+func newRate(t *testing.T, rate string) pgtype.Numeric {
+	var numeric pgtype.Numeric
+	require.NoError(t, numeric.Scan(rate))
+	return numeric
+}
+
+func TestRunScheduledTransferControls(t *testing.T) {
+	owner := util.RandomOwner()
+	fromAccount := db.Account{ID: util.RandomInt(1, 1000), Owner: owner, Currency: util.USD, Status: util.AccountStatusActive}
+	toAccount := db.Account{ID: util.RandomInt(1001, 2000), Owner: util.RandomOwner(), Currency: util.USD, Status: util.AccountStatusActive}
+	scheduledTransfer := db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         owner,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        50001,
+		Currency:      util.USD,
+		Frequency:     util.FrequencyOnce,
+		NextRunAt:     time.Now().Add(-time.Minute).UTC(),
+		Status:        util.ScheduleStatusActive,
+	}
+	scheduledTransfer.AnchorAt = scheduledTransfer.NextRunAt
+	run := db.ScheduledTransferRun{
+		ID:                  util.RandomInt(1, 1000),
+		ScheduledTransferID: scheduledTransfer.ID,
+		Status:              util.ScheduleRunStatusClaimed,
+		ScheduledFor:        scheduledTransfer.NextRunAt,
+	}
+	approvalID := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name       string
+		decision   fraud.Decision
+		buildStubs func(store *mockdb.MockStore)
+		checkMail  func(t *testing.T, mailer *stubMailer)
+	}{
+		{
+			name:     "AboveApprovalThreshold",
+			decision: fraud.Allow,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTransferApproval(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTransferApprovalParams) (db.TransferApproval, error) {
+						require.Equal(t, fromAccount.ID, arg.FromAccountID)
+						require.Equal(t, toAccount.ID, arg.ToAccountID)
+						require.Equal(t, scheduledTransfer.Amount, arg.Amount)
+						require.Equal(t, owner, arg.RequestedBy)
+						require.False(t, arg.HoldID.Valid)
+
+						return db.TransferApproval{ID: approvalID, Status: util.ApprovalStatusPending}, nil
+					})
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{
+						ID:         run.ID,
+						Status:     util.ScheduleRunStatusPending,
+						ApprovalID: pgtype.Int8{Int64: approvalID, Valid: true},
+					})).
+					Times(1).
+					Return(db.ScheduledTransferRun{}, nil)
+			},
+			checkMail: func(t *testing.T, mailer *stubMailer) {
+				require.Empty(t, mailer.sent)
+			},
+		},
+		{
+			name:     "FraudBlock",
+			decision: fraud.Block,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					FinishScheduledTransferRun(gomock.Any(), gomock.Eq(db.FinishScheduledTransferRunParams{
+						ID:     run.ID,
+						Status: util.ScheduleRunStatusFailed,
+						Error:  pgtype.Text{String: posting.ErrTransferBlocked.Error(), Valid: true},
+					})).
+					Times(1).
+					Return(db.ScheduledTransferRun{}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(owner)).
+					Times(1).
+					Return(db.User{Username: owner, FullName: "<b>Alice</b>", Email: util.RandomEmail()}, nil)
+			},
+			checkMail: func(t *testing.T, mailer *stubMailer) {
+				require.Len(t, mailer.sent, 1)
+				require.Contains(t, mailer.sent[0], html.EscapeString(posting.ErrTransferBlocked.Error()))
+				require.Contains(t, mailer.sent[0], "&lt;b&gt;Alice&lt;/b&gt;")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ClaimScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(run, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().
+				GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: fromAccount.ID, Username: owner})).
+				Times(1).
+				Return(db.AccountMember{Role: util.MemberRoleOwner, Status: util.MemberStatusActive}, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).AnyTimes().Return(newRate(t, "1"), nil)
+			store.EXPECT().PostScheduledTransferRunTx(gomock.Any(), gomock.Any()).Times(0)
+			tc.buildStubs(store)
+
+			threshold := money.New(50000, util.USD)
+			mailer := &stubMailer{}
+			processor := &RedisTaskProcessor{
+				store:  store,
+				mailer: mailer,
+				controls: &posting.Controls{
+					Store:             store,
+					Fraud:             stubScreener{decision: tc.decision},
+					ApprovalThreshold: &threshold,
+					ApprovalTTL:       time.Hour,
+				},
+			}
+
+			processor.runScheduledTransfer(context.Background(), scheduledTransfer)
+			tc.checkMail(t, mailer)
+		})
+	}
+}
+
+func TestRunScheduledTransfersResumesStaleRuns(t *testing.T) {
+	owner := util.RandomOwner()
+	fromAccount := db.Account{ID: util.RandomInt(1, 1000), Owner: owner, Currency: util.USD, Status: util.AccountStatusActive}
+	toAccount := db.Account{ID: util.RandomInt(1001, 2000), Owner: util.RandomOwner(), Currency: util.USD, Status: util.AccountStatusActive}
+	scheduledTransfer := db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         owner,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+		Currency:      util.USD,
+		Frequency:     util.FrequencyDaily,
+		Status:        util.ScheduleStatusActive,
+	}
+	// the worker claimed the run and stopped before posting it
+	run := db.ScheduledTransferRun{
+		ID:                  util.RandomInt(1, 1000),
+		ScheduledTransferID: scheduledTransfer.ID,
+		Status:              util.ScheduleRunStatusClaimed,
+		ScheduledFor:        time.Now().Add(-time.Hour),
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Posted",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PostScheduledTransferRunTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.PostScheduledTransferRunTxParams) (db.PostScheduledTransferRunTxResult, error) {
+						require.Equal(t, run.ID, arg.RunID)
+						require.Equal(t, scheduledTransfer.Amount, arg.Transfer.FromAmount)
+						require.Equal(t, owner, arg.Transfer.Username)
+						return db.PostScheduledTransferRunTxResult{}, nil
+					})
+				store.EXPECT().FinishScheduledTransferRun(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "FinishedByAnotherSweep",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PostScheduledTransferRunTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PostScheduledTransferRunTxResult{}, db.ErrScheduledRunFinished)
+				store.EXPECT().FinishScheduledTransferRun(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ListStaleScheduledTransferRuns(gomock.Any(), gomock.Any()).Times(1).Return([]db.ScheduledTransferRun{run}, nil)
+			store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduledTransfer.ID)).Times(1).Return(scheduledTransfer, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().
+				GetAccountMember(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AccountMember{Role: util.MemberRoleOwner, Status: util.MemberStatusActive}, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).AnyTimes().Return(newRate(t, "1"), nil)
+			store.EXPECT().ClaimScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().ListDueScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return([]db.ScheduledTransfer{}, nil)
+			tc.buildStubs(store)
+
+			mailer := &stubMailer{}
+			processor := &RedisTaskProcessor{
+				store:  store,
+				mailer: mailer,
+				controls: &posting.Controls{
+					Store: store,
+					Fraud: stubScreener{decision: fraud.Allow},
+				},
+			}
+
+			err := processor.ProcessTaskRunScheduledTransfers(context.Background(), asynq.NewTask(TaskRunScheduledTransfers, nil))
+			require.NoError(t, err)
+			require.Empty(t, mailer.sent)
+		})
+	}
+}
+
+func TestRunScheduledTransferFollowsSanctionsList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owner := util.RandomOwner()
+	recipient := db.User{Username: util.RandomOwner(), FullName: "Viktor Kovalenko"}
+	fromAccount := db.Account{ID: util.RandomInt(1, 1000), Owner: owner, Currency: util.USD, Status: util.AccountStatusActive}
+	toAccount := db.Account{ID: util.RandomInt(1001, 2000), Owner: recipient.Username, Currency: util.USD, Status: util.AccountStatusActive}
+	scheduledTransfer := db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         owner,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+		Currency:      util.USD,
+		Frequency:     util.FrequencyDaily,
+		NextRunAt:     time.Now().Add(-time.Minute).UTC(),
+		Status:        util.ScheduleStatusActive,
+	}
+	scheduledTransfer.AnchorAt = scheduledTransfer.NextRunAt
+
+	path := filepath.Join(t.TempDir(), "sdn.csv")
+	require.NoError(t, os.WriteFile(path, []byte(`1,"CHEN, Alice","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- `+"\n"), 0o600))
+	screener, err := sanctions.NewScreener(path, 0.93)
+	require.NoError(t, err)
+
+	store := mockdb.NewMockStore(ctrl)
+	run := db.ScheduledTransferRun{ID: 1, ScheduledTransferID: scheduledTransfer.ID, Status: util.ScheduleRunStatusClaimed}
+	store.EXPECT().ClaimScheduledTransferTx(gomock.Any(), gomock.Any()).Times(2).Return(run, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(2).Return(fromAccount, nil)
+	store.EXPECT().
+		GetAccountMember(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(db.AccountMember{Role: util.MemberRoleOwner, Status: util.MemberStatusActive}, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(2).Return(toAccount, nil)
+	store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(util.USD)).AnyTimes().Return(newRate(t, "1"), nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(2).Return(recipient, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(owner)).Times(1).Return(db.User{Username: owner, Email: util.RandomEmail()}, nil)
+
+	// The first run posts, the list doesn't name the recipient yet
+	store.EXPECT().PostScheduledTransferRunTx(gomock.Any(), gomock.Any()).Times(1).Return(db.PostScheduledTransferRunTxResult{}, nil)
+	store.EXPECT().
+		FinishScheduledTransferRun(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.FinishScheduledTransferRunParams) (db.ScheduledTransferRun, error) {
+			require.Equal(t, util.ScheduleRunStatusFailed, arg.Status)
+			return db.ScheduledTransferRun{Status: arg.Status, Error: arg.Error}, nil
+		})
+	store.EXPECT().CountClearedSanctionsCases(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().GetOpenSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, db.ErrRecordNotFound)
+	store.EXPECT().
+		CreateSanctionsCase(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ any, arg db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+			require.Equal(t, recipient.FullName, arg.ScreenedName)
+			require.Equal(t, "36", arg.ListEntryID)
+			return db.SanctionsCase{ID: 1}, nil
+		})
+
+	mailer := &stubMailer{}
+	processor := &RedisTaskProcessor{
+		store:  store,
+		mailer: mailer,
+		controls: &posting.Controls{
+			Store:     store,
+			Fraud:     stubScreener{decision: fraud.Allow},
+			Sanctions: screener,
+		},
+	}
+
+	processor.runScheduledTransfer(context.Background(), scheduledTransfer)
+	require.Empty(t, mailer.sent)
+
+	// The list file is replaced while the worker runs, nothing reloads it
+	require.NoError(t, os.WriteFile(path, []byte(`36,"KOVALENKO, Viktor","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- `+"\n"), 0o600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	processor.runScheduledTransfer(context.Background(), scheduledTransfer)
+	require.Len(t, mailer.sent, 1)
+	require.Contains(t, mailer.sent[0], posting.ErrSanctionsMatch.Error())
+}
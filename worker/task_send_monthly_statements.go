@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendMonthlyStatements = "task:send_monthly_statements"
+
+// statementBatchSize bounds how many accounts are read per query while fanning out statements
+const statementBatchSize = 100
+
+// ProcessTaskSendMonthlyStatements enqueues one statement task per account for the previous calendar month.
+// Each task has an ID derived from the account and the month, so a repeated sweep doesn't mail a statement twice.
+func (processor *RedisTaskProcessor) ProcessTaskSendMonthlyStatements(ctx context.Context, task *asynq.Task) error {
+	from, to := previousMonth(time.Now())
+
+	var lastID int64
+	enqueued := 0
+	for {
+		accounts, err := processor.store.ListAccountsAfter(ctx, db.ListAccountsAfterParams{
+			ID:    lastID,
+			Limit: statementBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list accounts: %w", err)
+		}
+
+		for _, account := range accounts {
+			payload := &PayloadSendStatement{
+				AccountID: account.ID,
+				From:      from,
+				To:        to,
+			}
+			opts := []asynq.Option{
+				asynq.MaxRetry(3),
+				asynq.Queue(QueueDefault),
+				asynq.TaskID(fmt.Sprintf("statement:%d:%s", account.ID, from.Format("2006-01"))),
+			}
+
+			err = processor.distributor.DistributeTaskSendStatement(ctx, payload, opts...)
+			if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+				return err
+			}
+			enqueued++
+		}
+
+		if len(accounts) < statementBatchSize {
+			break
+		}
+		lastID = accounts[len(accounts)-1].ID
+	}
+
+	log.Info().Str("type", task.Type()).Time("from", from).Time("to", to).
+		Int("accounts", enqueued).Msg("processed task")
+	return nil
+}
+
+// previousMonth returns the bounds of the calendar month before now, in UTC
+func previousMonth(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return to.AddDate(0, -1, 0), to
+}
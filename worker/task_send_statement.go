@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/RobertChienShiba/simplebank/statement"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendStatement = "task:send_statement"
+
+// PayloadSendStatement covers the entries of the account posted in [From, To)
+type PayloadSendStatement struct {
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendStatement(
+	ctx context.Context,
+	payload *PayloadSendStatement,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendStatement, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendStatement(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendStatement
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	account, err := processor.store.GetAccount(ctx, payload.AccountID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return fmt.Errorf("account doesn't exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	user, err := processor.store.GetUser(ctx, account.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	stmt, err := statement.Build(ctx, processor.store, account, payload.From, payload.To)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "statement")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	csvFile, err := writeStatementFile(dir, stmt.Filename("csv"), stmt, statement.WriteCSV)
+	if err != nil {
+		return err
+	}
+
+	pdfFile, err := writeStatementFile(dir, stmt.Filename("pdf"), stmt, statement.WritePDF)
+	if err != nil {
+		return err
+	}
+
+	credits, debits := stmt.Totals()
+	subject := fmt.Sprintf("Go2Bank Statement for Account #%d", account.ID)
+	content := fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html lang="en">
+		<head>
+			<meta charset="UTF-8">
+			<meta name="viewport" content="width=device-width, initial-scale=1.0">
+			<title>Account Statement</title>
+		</head>
+		<body>
+			<p>Hello %s,</p>
+			<p>Your statement for account <b>#%d</b> covering <b>%s</b> is attached in CSV and PDF.</p>
+			<p>%d entries, %s in credits and %s in debits. Closing balance: <b>%s</b>.</p>
+		</body>
+		</html>`,
+		html.EscapeString(user.FullName),
+		account.ID,
+		stmt.Period(),
+		len(stmt.Lines),
+		money.New(credits, account.Currency),
+		money.New(debits, account.Currency),
+		money.New(stmt.ClosingBalance, account.Currency),
+	)
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, []string{csvFile, pdfFile})
+	if err != nil {
+		return fmt.Errorf("failed to send statement email: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("email", user.Email).Msg("processed task")
+	return nil
+}
+
+func writeStatementFile(dir string, name string, stmt statement.Statement, write func(w io.Writer, stmt statement.Statement) error) (string, error) {
+	path := filepath.Join(dir, name)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	defer file.Close()
+
+	if err := write(file, stmt); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	return path, file.Close()
+}
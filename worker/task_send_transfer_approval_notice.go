@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/money"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendTransferApprovalNotice = "task:send_transfer_approval_notice"
+
+// PayloadSendTransferApprovalNotice tells the requester of a transfer approval how it was settled
+type PayloadSendTransferApprovalNotice struct {
+	ApprovalID int64 `json:"approval_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendTransferApprovalNotice(
+	ctx context.Context,
+	payload *PayloadSendTransferApprovalNotice,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendTransferApprovalNotice, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendTransferApprovalNotice(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendTransferApprovalNotice
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	approval, err := processor.store.GetTransferApproval(ctx, payload.ApprovalID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return fmt.Errorf("transfer approval doesn't exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get transfer approval: %w", err)
+	}
+
+	user, err := processor.store.GetUser(ctx, approval.RequestedBy)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	fromAccount, err := processor.store.GetAccount(ctx, approval.FromAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	reason := "It was not reviewed in time, please send the transfer again if you still need it."
+	if approval.Reason.Valid {
+		reason = fmt.Sprintf("Reason given by the reviewer: %s", html.EscapeString(approval.Reason.String))
+	}
+
+	subject := fmt.Sprintf("Go2Bank Transfer Approval #%d %s", approval.ID, approval.Status)
+	content := fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html lang="en">
+		<head>
+			<meta charset="UTF-8">
+			<meta name="viewport" content="width=device-width, initial-scale=1.0">
+			<title>Transfer Approval</title>
+		</head>
+		<body>
+			<p>Hello %s,</p>
+			<p>Your transfer of <b>%s</b> from account <b>#%d</b> to account <b>#%d</b> was <b>%s</b>.</p>
+			<p>%s</p>
+		</body>
+		</html>`,
+		html.EscapeString(user.FullName),
+		money.New(approval.Amount, fromAccount.Currency),
+		approval.FromAccountID,
+		approval.ToAccountID,
+		approval.Status,
+		reason,
+	)
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send transfer approval notice: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("email", user.Email).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"testing"
+
+	mockdb "github.com/RobertChienShiba/simplebank/db/mock"
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessTaskSendTransferApprovalNoticeEscapesHTML(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := db.User{
+		Username: util.RandomOwner(),
+		FullName: `<a href="https://evil.example">Alice</a>`,
+		Email:    util.RandomEmail(),
+	}
+	fromAccount := db.Account{ID: util.RandomInt(1, 1000), Owner: user.Username, Currency: util.USD}
+	approval := db.TransferApproval{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   util.RandomInt(1001, 2000),
+		Amount:        util.RandomMoney(),
+		Status:        util.ApprovalStatusRejected,
+		RequestedBy:   user.Username,
+		Reason:        pgtype.Text{String: `<img src=x onerror="alert(1)">`, Valid: true},
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).Return(approval, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+
+	mailer := &stubMailer{}
+	processor := &RedisTaskProcessor{
+		store:  store,
+		mailer: mailer,
+	}
+
+	payload, err := json.Marshal(PayloadSendTransferApprovalNotice{ApprovalID: approval.ID})
+	require.NoError(t, err)
+
+	err = processor.ProcessTaskSendTransferApprovalNotice(context.Background(), asynq.NewTask(TaskSendTransferApprovalNotice, payload))
+	require.NoError(t, err)
+
+	require.Len(t, mailer.sent, 1)
+	require.Contains(t, mailer.sent[0], html.EscapeString(user.FullName))
+	require.Contains(t, mailer.sent[0], html.EscapeString(approval.Reason.String))
+	require.NotContains(t, mailer.sent[0], user.FullName)
+	require.NotContains(t, mailer.sent[0], approval.Reason.String)
+}
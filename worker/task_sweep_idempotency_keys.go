@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSweepIdempotencyKeys = "task:sweep_idempotency_keys"
+
+func (processor *RedisTaskProcessor) ProcessTaskSweepIdempotencyKeys(ctx context.Context, task *asynq.Task) error {
+	deleted, err := processor.store.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Int64("deleted", deleted).Msg("processed task")
+	return nil
+}
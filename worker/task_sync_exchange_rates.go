@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/RobertChienShiba/simplebank/db/sqlc"
+	"github.com/RobertChienShiba/simplebank/rates"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSyncExchangeRates = "task:sync_exchange_rates"
+
+// rateSourceTimeout bounds how long a sync waits for the rate sheet
+const rateSourceTimeout = 30 * time.Second
+
+func (distributor *RedisTaskDistributor) DistributeTaskSyncExchangeRates(
+	ctx context.Context,
+	opts ...asynq.Option,
+) error {
+	task := asynq.NewTask(TaskSyncExchangeRates, nil, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSyncExchangeRates(ctx context.Context, task *asynq.Task) error {
+	currencies, err := rates.LoadCurrencies(processor.config.RatesCurrencyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load currencies: %v: %w", err, asynq.SkipRetry)
+	}
+
+	fetcher := rates.NewFetcher(&http.Client{Timeout: rateSourceTimeout}, processor.config.RatesSourceURL)
+	fetched, err := fetcher.Fetch(ctx, currencies)
+	if err != nil {
+		return fmt.Errorf("failed to fetch rates: %w", err)
+	}
+
+	if len(fetched) < len(currencies) {
+		log.Warn().Int("fetched", len(fetched)).Int("required", len(currencies)).Msg("rate sheet is missing currencies")
+	}
+
+	arg := db.SyncRatesTxParams{
+		EffectiveAt: time.Now(),
+	}
+	for _, rate := range fetched {
+		arg.Rates = append(arg.Rates, db.UpsertCurrencyParams{
+			Currency: rate.Currency,
+			Rate:     rate.Rate,
+		})
+	}
+
+	result, err := processor.store.SyncRatesTx(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("failed to sync rates: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Int("currencies", len(result.Currencies)).
+		Int("changed", len(result.History)).Msg("processed task")
+	return nil
+}